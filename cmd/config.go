@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/JakubPluta/tmago/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// dumpFormat is the output format of config dump
+var dumpFormat string
+
+// configCmd groups the commands inspecting the config file.
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the config file",
+}
+
+// configDumpCmd prints the config as it will run, after substituting the
+// environment variables and applying the defaults, with secrets redacted.
+var configDumpCmd = &cobra.Command{
+	Use:   "dump",
+	Short: "Print the fully-resolved config",
+	Long: `Print the config as it will run: with the environment variables substituted
+and the defaults applied, leaving out unset settings. Secrets are redacted like
+in the reports: the values of redacted headers, HMAC secrets, values at the
+redacted JSON paths and the matches of the redaction patterns.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if configFile == "" {
+			return fmt.Errorf("please provide config file")
+		}
+
+		cfg, err := config.LoadConfigWithOptions(configFile, config.LoadOptions{Strict: strictConfig})
+		if err != nil {
+			return fmt.Errorf("loading config: %w", err)
+		}
+		if err := cfg.Validate(); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+
+		data, err := cfg.Dump(dumpFormat)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(data)
+		return err
+	},
+}
+
+func init() {
+	configDumpCmd.Flags().StringVarP(&dumpFormat, "output", "o", config.DumpYAML, "output format: yaml or json")
+	configCmd.AddCommand(configDumpCmd)
+	rootCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/JakubPluta/tmago/internal/doctor"
+	"github.com/spf13/cobra"
+)
+
+// doctorCmd diagnoses the environment and the config, printing a checklist
+// with the outcome of every check. It fails when any check fails.
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose the environment and config",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if configFile == "" {
+			return fmt.Errorf("please provide config file")
+		}
+
+		checks := doctor.Run(cmd.Context(), configFile, doctor.Options{
+			Strict: strictConfig,
+			Dirs:   []string{"reports", "logs"},
+		})
+
+		failed := 0
+		for _, c := range checks {
+			switch {
+			case c.Skipped != "":
+				fmt.Printf("⏭️  %s (skipped: %s)\n", c.Name, c.Skipped)
+			case c.Err != nil:
+				failed++
+				fmt.Printf("❌ %s: %v\n", c.Name, c.Err)
+			default:
+				fmt.Printf("✅ %s\n", c.Name)
+			}
+		}
+
+		if failed > 0 {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
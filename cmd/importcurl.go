@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/JakubPluta/tmago/internal/curl"
+	"github.com/spf13/cobra"
+)
+
+// importName overrides the name of the imported endpoint
+var importName string
+
+// importCurlCmd converts a cURL command into an endpoint. The endpoint is
+// appended to the config file given with --config, or printed when no config
+// file is given.
+var importCurlCmd = &cobra.Command{
+	Use:   "import-curl '<curl command>'",
+	Short: "Import an endpoint from a cURL command",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		endpoint, err := curl.Parse(args[0])
+		if err != nil {
+			return fmt.Errorf("parsing curl command: %w", err)
+		}
+		if importName != "" {
+			endpoint.Name = importName
+		}
+
+		if configFile == "" {
+			out, err := curl.Marshal(endpoint)
+			if err != nil {
+				return err
+			}
+			fmt.Print(string(out))
+			return nil
+		}
+
+		if err := curl.Append(configFile, endpoint); err != nil {
+			return fmt.Errorf("appending endpoint: %w", err)
+		}
+		fmt.Printf("Added endpoint %q to %s\n", endpoint.Name, configFile)
+		return nil
+	},
+}
+
+func init() {
+	importCurlCmd.Flags().StringVar(&importName, "name", "", "name of the endpoint (method and path by default)")
+	rootCmd.AddCommand(importCurlCmd)
+}
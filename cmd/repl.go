@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/JakubPluta/tmago/internal/config"
+	"github.com/JakubPluta/tmago/internal/repl"
+	"github.com/JakubPluta/tmago/internal/runner"
+	"github.com/spf13/cobra"
+)
+
+// replCmd starts an interactive prompt sending ad-hoc requests. With a config
+// file, its variables, redaction and allowed targets apply to the requests,
+// and the save command appends endpoints to it.
+var replCmd = &cobra.Command{
+	Use:   "repl",
+	Short: "Send ad-hoc requests from an interactive prompt",
+	Long: `Send ad-hoc requests from an interactive prompt: type a method and a URL, e.g.
+GET https://api.example.com/users, to see the response and its timing, and
+save it as an endpoint of the config file given with --config (or print it).
+Type help at the prompt for the commands.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		cfg := &config.Config{}
+		if configFile != "" {
+			var err error
+			cfg, err = config.LoadConfigWithOptions(configFile, config.LoadOptions{Strict: strictConfig})
+			if err != nil {
+				return fmt.Errorf("loading config: %w", err)
+			}
+			if err := cfg.Validate(); err != nil {
+				return fmt.Errorf("invalid config: %w", err)
+			}
+		}
+
+		r, err := runner.NewRunner(cfg, runner.Options{NoFileLog: true})
+		if err != nil {
+			return fmt.Errorf("creating runner: %w", err)
+		}
+		return repl.Run(cmd.Context(), r, repl.Options{
+			In:         os.Stdin,
+			Out:        os.Stdout,
+			ConfigFile: configFile,
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(replCmd)
+}
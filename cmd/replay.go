@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/JakubPluta/tmago/internal/config"
+	"github.com/JakubPluta/tmago/internal/har"
+	"github.com/JakubPluta/tmago/internal/reporter"
+	"github.com/JakubPluta/tmago/internal/runner"
+	"github.com/spf13/cobra"
+)
+
+// flags of the replay command
+var (
+	harFile         string
+	replaySpeed     string
+	replayJSONL     bool
+	replayNoFileLog bool
+	replayFormats   []string
+)
+
+// replayCmd replays the requests recorded in a HAR file at their recorded
+// pace, optionally time-scaled, and reports them like a run. With a config
+// file, its settings other than the endpoints and scenario apply, e.g. its
+// redaction and allowed targets.
+var replayCmd = &cobra.Command{
+	Use:   "replay --har FILE",
+	Short: "Replay recorded traffic from a HAR file",
+	Long: `Replay the requests recorded in a HAR file, e.g. exported from the browser
+devtools or a proxy, in the order and at the pace they were recorded, to load
+test with real traffic. --speed 2x replays them twice as fast. Every request
+is expected to get its recorded status, and the requests are reported per
+method and path.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		speed, err := parseSpeed(replaySpeed)
+		if err != nil {
+			return fmt.Errorf("--speed: %w", err)
+		}
+		traffic, skipped, err := har.Load(harFile)
+		if err != nil {
+			return fmt.Errorf("loading HAR file: %w", err)
+		}
+		if skipped > 0 {
+			fmt.Fprintf(os.Stderr, "Skipped %d entries that are not http or https requests\n", skipped)
+		}
+
+		cfg := &config.Config{}
+		if configFile != "" {
+			cfg, err = config.LoadConfigWithOptions(configFile, config.LoadOptions{Strict: strictConfig})
+			if err != nil {
+				return fmt.Errorf("loading config: %w", err)
+			}
+		}
+		cfg.Endpoints = nil
+		cfg.Scenario = nil
+		seen := make(map[string]bool)
+		for _, req := range traffic {
+			if !seen[req.Endpoint.Name] {
+				seen[req.Endpoint.Name] = true
+				cfg.Endpoints = append(cfg.Endpoints, req.Endpoint)
+			}
+		}
+		if err := cfg.Validate(); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+
+		opts := runner.Options{
+			NoFileLog: replayNoFileLog,
+			Formats:   replayFormats,
+			Traffic:   traffic,
+			Speed:     speed,
+		}
+		if replayJSONL {
+			opts.Events = os.Stdout
+		}
+		r, err := runner.NewRunner(cfg, opts)
+		if err != nil {
+			return fmt.Errorf("creating runner: %w", err)
+		}
+		return r.Run(context.Background())
+	},
+}
+
+// parseSpeed parses a replay speed such as 2x, 0.5x or 2.
+func parseSpeed(s string) (float64, error) {
+	speed, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(s), "x"), 64)
+	if err != nil || speed <= 0 {
+		return 0, fmt.Errorf("invalid speed %q, expected a positive factor such as 2x or 0.5x", s)
+	}
+	return speed, nil
+}
+
+func init() {
+	replayCmd.Flags().StringVar(&harFile, "har", "", "HAR file with the recorded requests")
+	replayCmd.MarkFlagRequired("har")
+	replayCmd.Flags().StringVar(&replaySpeed, "speed", "1x", "time scale of the replay, e.g. 2x for twice as fast as recorded")
+	replayCmd.Flags().BoolVar(&replayJSONL, "jsonl", false, "stream each completed request to stdout as a JSON line")
+	replayCmd.Flags().BoolVar(&replayNoFileLog, "no-file-log", false, "log to the console only, without creating a log file")
+	replayCmd.Flags().StringSliceVar(&replayFormats, "format", reporter.DefaultFormats, formatUsage)
+	rootCmd.AddCommand(replayCmd)
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/JakubPluta/tmago/internal/reporter"
+	"github.com/JakubPluta/tmago/internal/runner"
+	"github.com/spf13/cobra"
+)
+
+// flags of the report command, like those of run
+var (
+	reportMaxSize string
+	reportFormats []string
+	reportWorkers int
+)
+
+// formatUsage describes the --format flag of the run, replay and report
+// commands. describeFormats completes it with the registered formats.
+const formatUsage = "report formats to write, comma separated"
+
+// describeFormats lists the registered formats in the usage of the --format
+// flags. It runs when tmago is executed rather than when the flags are
+// defined, so formats registered by a program embedding tmago are listed.
+func describeFormats() {
+	for _, c := range []*cobra.Command{runCmd, replayCmd, reportCmd} {
+		if flag := c.Flags().Lookup("format"); flag != nil {
+			flag.Usage = fmt.Sprintf("%s: %s", formatUsage, strings.Join(reporter.Formats(), ", "))
+		}
+	}
+}
+
+// reportWorkersUsage describes the --report-workers flag of the run and report commands.
+const reportWorkersUsage = "endpoints whose statistics are computed concurrently for the reports (one per CPU when unset)"
+
+// reportCmd regenerates the HTML and JSON reports from the results streamed
+// during a run, e.g. after the run crashed before writing them.
+var reportCmd = &cobra.Command{
+	Use:   "report [results.jsonl]",
+	Short: "Regenerate the reports from the results of a run",
+	Long: fmt.Sprintf(`Regenerate the reports, report.html and report.json by default, from the
+results a run streams to %s as each endpoint finishes. The reports
+are written next to the results file. A run that did not finish is reported up to its last completed endpoint.`, runner.ResultsFile),
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		path := runner.ResultsFile
+		if len(args) == 1 {
+			path = args[0]
+		}
+
+		r, err := reporter.LoadResults(path)
+		if err != nil {
+			return fmt.Errorf("loading results: %w", err)
+		}
+		if reportMaxSize != "" {
+			size, err := parseReportSize(reportMaxSize)
+			if err != nil {
+				return fmt.Errorf("--max-report-size: %w", err)
+			}
+			r.SetMaxReportSize(size)
+		}
+		r.SetParallelism(reportWorkers)
+		dir := filepath.Dir(path)
+		writers := make([]reporter.ReportWriter, 0, len(reportFormats))
+		for _, format := range reportFormats {
+			writer, err := reporter.NewWriter(format, dir)
+			if err != nil {
+				return err
+			}
+			writers = append(writers, writer)
+		}
+		if err := r.Write(writers...); err != nil {
+			return err
+		}
+		fmt.Printf("Reports written to %s\n", dir)
+		return nil
+	},
+}
+
+func init() {
+	reportCmd.Flags().StringSliceVar(&reportFormats, "format", reporter.DefaultFormats, formatUsage)
+	reportCmd.Flags().IntVar(&reportWorkers, "report-workers", 0, reportWorkersUsage)
+	reportCmd.Flags().StringVar(&reportMaxSize, "max-report-size", "", "split the request details of the HTML report into pages beyond the given size, e.g. 20MB")
+	rootCmd.AddCommand(reportCmd)
+}
@@ -1,16 +1,26 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
+	"github.com/JakubPluta/tmago/internal/runner"
 	"github.com/spf13/cobra"
 )
 
+// Exit codes of tmago. A run skipped because its health check failed exits
+// with its own code, so CI can tell an environment that is down from failed tests.
+const (
+	exitFailed  = 1
+	exitSkipped = 3
+)
+
 // rootCmd represents the base command when called without any subcommands
 var (
-	configFile string
-	rootCmd    = &cobra.Command{
+	configFile   string
+	strictConfig bool
+	rootCmd      = &cobra.Command{
 		Use:   "tmago",
 		Long:  "TestMyAPI is a tool to test APIs, powered by Go and Golang.",
 		Short: "API testing tool",
@@ -20,15 +30,21 @@ var (
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
+	describeFormats()
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
-		os.Exit(1)
+		if errors.Is(err, runner.ErrSkipped) {
+			os.Exit(exitSkipped)
+		}
+		os.Exit(exitFailed)
 	}
 }
 
-// init initializes the root command with a required --config flag and adds the run command to it.
+// init initializes the root command with the --config and --strict flags,
+// and adds the run command to it. The commands reading a config check that
+// --config is given themselves, as import-curl uses it optionally.
 func init() {
-	rootCmd.PersistentFlags().StringVarP(&configFile, "config", "c", "", "config file (required)")
-	rootCmd.MarkPersistentFlagRequired("config")
+	rootCmd.PersistentFlags().StringVarP(&configFile, "config", "c", "", "config file (required by run and doctor)")
+	rootCmd.PersistentFlags().BoolVar(&strictConfig, "strict", false, "reject unknown keys in the config file")
 	rootCmd.AddCommand(runCmd)
 }
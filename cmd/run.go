@@ -3,12 +3,42 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/JakubPluta/tmago/internal/config"
+	"github.com/JakubPluta/tmago/internal/reporter"
 	"github.com/JakubPluta/tmago/internal/runner"
 	"github.com/spf13/cobra"
 )
 
+// flags of the run command
+var (
+	seed      int64
+	jsonl     bool
+	recordDir string
+	replayDir string
+	noFileLog bool
+	webhook   string
+	webhookOn string
+	varsIn    string
+	varsOut   string
+	splitDir  string
+	maxDur    time.Duration
+	exportEnv string
+	smoke     bool
+	bucket    time.Duration
+	labels    []string
+	maxReport string
+	maxJSON   string
+	formats   []string
+	workers   int
+	sample    float64
+	sortReqs  bool
+	shuffle   bool
+)
+
 // runCmd represents the run command
 // It runs all the tests in the given config concurrently.
 // It will call either runSingle or runConcurrent for each endpoint,
@@ -23,15 +53,93 @@ var runCmd = &cobra.Command{
 			return fmt.Errorf("please provide config file")
 		}
 
-		cfg, err := config.LoadConfig(configFile)
+		cfg, err := config.LoadConfigWithOptions(configFile, config.LoadOptions{Strict: strictConfig})
 		if err != nil {
 			return fmt.Errorf("loading config: %w", err)
 		}
+		if err := cfg.Validate(); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
 
-		r, err := runner.NewRunner(cfg)
+		opts := runner.Options{
+			Seed:            seed,
+			RecordDir:       recordDir,
+			ReplayDir:       replayDir,
+			NoFileLog:       noFileLog,
+			WebhookURL:      webhook,
+			WebhookOn:       webhookOn,
+			VarsIn:          varsIn,
+			VarsOut:         varsOut,
+			SplitReportsDir: splitDir,
+			MaxDuration:     maxDur,
+			ExportCaptures:  exportEnv,
+			Smoke:           smoke,
+			BucketWidth:     bucket,
+			Formats:         formats,
+			ReportWorkers:   workers,
+			Sample:          sample,
+			SortRequests:    sortReqs,
+			Shuffle:         shuffle,
+		}
+		if jsonl {
+			opts.Events = os.Stdout
+		}
+		if maxReport != "" {
+			size, err := parseReportSize(maxReport)
+			if err != nil {
+				return fmt.Errorf("--max-report-size: %w", err)
+			}
+			opts.MaxReportSize = size
+		}
+		if maxJSON != "" {
+			size, err := parseSize(maxJSON)
+			if err != nil {
+				return fmt.Errorf("--max-json-size: %w", err)
+			}
+			opts.MaxJSONSize = size
+		}
+		if len(labels) > 0 {
+			opts.Labels = make(map[string]string, len(labels))
+			for _, label := range labels {
+				key, value, ok := strings.Cut(label, "=")
+				if !ok || key == "" {
+					return fmt.Errorf("invalid label %q, expected key=value", label)
+				}
+				opts.Labels[key] = value
+			}
+		}
+
+		r, err := runner.NewRunner(cfg, opts)
 		if err != nil {
 			return fmt.Errorf("creating runner: %w", err)
 		}
 		return r.Run(context.Background())
 	},
 }
+
+// init registers the flags of the run command.
+func init() {
+	runCmd.Flags().Int64Var(&seed, "seed", 0, "seed for random test data (random when unset)")
+	runCmd.Flags().BoolVar(&shuffle, "shuffle", false, "run the endpoints in a random order drawn from the seed, after the endpoints they depend on")
+	runCmd.Flags().BoolVar(&jsonl, "jsonl", false, "stream each completed request to stdout as a JSON line")
+	runCmd.Flags().StringVar(&recordDir, "record", "", "save every response in the given directory")
+	runCmd.Flags().StringVar(&replayDir, "replay", "", "replay responses recorded with --record instead of hitting the network")
+	runCmd.MarkFlagsMutuallyExclusive("record", "replay")
+	runCmd.Flags().BoolVar(&noFileLog, "no-file-log", false, "log to the console only, without creating a log file")
+	runCmd.Flags().StringVar(&webhook, "webhook", "", "POST a JSON summary of the run to the given URL")
+	runCmd.Flags().StringVar(&webhookOn, "webhook-on", runner.WebhookOnFailure, "when to call the webhook: failure or always")
+	runCmd.Flags().StringVar(&varsIn, "vars-in", "", "load variables saved by a previous run with --vars-out")
+	runCmd.Flags().StringVar(&varsOut, "vars-out", "", "save the captured variables to the given JSON file at the end of the run")
+	runCmd.Flags().StringVar(&exportEnv, "export-captures", "", "write the captured variables to the given file as NAME=VALUE lines for shell scripts")
+	runCmd.Flags().StringVar(&splitDir, "split-reports", "", "also write an HTML and JSON report per endpoint, plus an index.html, to the given directory")
+	runCmd.Flags().Float64Var(&sample, "sample", 0, "run the given fraction of the requests and users of every endpoint, e.g. 0.1 for a quick version of a load test")
+	runCmd.Flags().BoolVar(&smoke, "smoke", false, "send a single request per endpoint, ignoring concurrency and retries, and print a pass/fail line for each")
+	runCmd.Flags().StringArrayVar(&labels, "label", nil, "label the run in the reports as key=value, e.g. --label env=staging (repeatable)")
+	runCmd.Flags().StringVar(&maxJSON, "max-json-size", "", "largest response body parsed for JSON checks and captures, e.g. 256MB (64MB when unset)")
+	runCmd.Flags().StringVar(&maxReport, "max-report-size", "", "split the request details of the HTML report into pages beyond the given size, e.g. 20MB")
+	runCmd.Flags().StringSliceVar(&formats, "format", reporter.DefaultFormats, formatUsage)
+	runCmd.Flags().IntVar(&workers, "report-workers", 0, reportWorkersUsage)
+	runCmd.Flags().BoolVar(&sortReqs, "sort-requests", false, "list the requests of every endpoint in the reports by ID instead of completion order, to diff runs")
+	runCmd.Flags().DurationVar(&bucket, "bucket", 0, "time window of the status code timeline in the report (automatic when unset)")
+	runCmd.Flags().DurationVar(&maxDur, "max-duration", 0, "fail the run when it takes longer than the given duration (the run still completes)")
+}
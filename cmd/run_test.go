@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// execute runs tmago with the given arguments, returning the error of the command.
+func execute(t *testing.T, args ...string) error {
+	t.Helper()
+	rootCmd.SetArgs(args)
+	rootCmd.SetOut(io.Discard)
+	rootCmd.SetErr(io.Discard)
+	t.Cleanup(func() { rootCmd.SetArgs(nil) })
+	return rootCmd.Execute()
+}
+
+func TestRunRejectsAnInvalidConfigBeforeSending(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	config := `
+endpoints:
+  - name: slo
+    url: ` + server.URL + `
+    method: GET
+    slo:
+      target: 150
+      threshold: 100ms
+`
+	if err := os.WriteFile(path, []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := execute(t, "run", "-c", path, "--no-file-log")
+	if err == nil || !strings.Contains(err.Error(), "invalid config") || !strings.Contains(err.Error(), "slo target") {
+		t.Fatalf("err = %v, want the invalid slo target", err)
+	}
+	if n := requests.Load(); n != 0 {
+		t.Errorf("sent %d requests with an invalid config", n)
+	}
+}
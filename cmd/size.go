@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/JakubPluta/tmago/internal/reporter"
+)
+
+// sizeUnits are the suffixes accepted by parseSize, longest first.
+var sizeUnits = []struct {
+	suffix string
+	bytes  int64
+}{
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// parseSize parses a size in bytes with an optional unit, e.g. 500KB or 10MB.
+// Units are binary, so 1KB is 1024 bytes.
+func parseSize(s string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(s))
+	multiplier := int64(1)
+	for _, unit := range sizeUnits {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			multiplier = unit.bytes
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q, expected e.g. 500KB or 10MB", s)
+	}
+	return int64(n * float64(multiplier)), nil
+}
+
+// parseReportSize parses the --max-report-size flag. Sizes below
+// reporter.MinReportSize are rejected, as the detail pages would not leave
+// room for their rows; zero disables the cap.
+func parseReportSize(s string) (int64, error) {
+	size, err := parseSize(s)
+	if err != nil {
+		return 0, err
+	}
+	if size > 0 && size < reporter.MinReportSize {
+		return 0, fmt.Errorf("size %s is below the minimum of %dKB", s, reporter.MinReportSize>>10)
+	}
+	return size, nil
+}
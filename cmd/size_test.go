@@ -0,0 +1,24 @@
+package cmd
+
+import "testing"
+
+func TestParseReportSize(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{in: "20MB", want: 20 << 20},
+		{in: "64KB", want: 64 << 10},
+		{in: "0", want: 0},
+		{in: "1KB", wantErr: true},
+		{in: "100", wantErr: true},
+		{in: "big", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseReportSize(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseReportSize(%q) = %d, %v, want %d (error %t)", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Representation of an acceptable response body of a bodyOneOf expectation,
+// given inline as Value, as YAML or a string of JSON like Match, or read from
+// the JSON File. Name, the file by default, identifies it in messages.
+type BodyCandidate struct {
+	Name  string      `yaml:"name"`
+	Value interface{} `yaml:"value"`
+	File  string      `yaml:"file"`
+	// hasValue records that Value was given, so that a `value: null`
+	// candidate, accepting a JSON null body, differs from a missing value
+	hasValue bool
+}
+
+// UnmarshalYAML records whether the value key is present.
+func (b *BodyCandidate) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plainBodyCandidate BodyCandidate
+	if err := unmarshal((*plainBodyCandidate)(b)); err != nil {
+		return err
+	}
+	var keys map[string]interface{}
+	if err := unmarshal(&keys); err != nil {
+		return err
+	}
+	_, b.hasValue = keys["value"]
+	return nil
+}
+
+// loadBodyCandidates reads the files of the bodyOneOf candidates of every
+// endpoint, including its byStatus blocks, into their values. The file is
+// cleared once read, so that a dumped config is self-contained.
+func (c *Config) loadBodyCandidates() error {
+	for i := range c.Endpoints {
+		e := &c.Endpoints[i]
+		if err := loadCandidates(e.Expect.BodyOneOf); err != nil {
+			return fmt.Errorf("endpoint %s: %w", e.Name, err)
+		}
+		for status, block := range e.Expect.ByStatus {
+			if err := loadCandidates(block.BodyOneOf); err != nil {
+				return fmt.Errorf("endpoint %s: byStatus %d: %w", e.Name, status, err)
+			}
+		}
+	}
+	return nil
+}
+
+// loadCandidates reads the files of the candidates in place.
+func loadCandidates(candidates []BodyCandidate) error {
+	for i := range candidates {
+		candidate := &candidates[i]
+		if candidate.File == "" {
+			continue
+		}
+		if candidate.hasValue {
+			return fmt.Errorf("bodyOneOf candidate %d: value and file are mutually exclusive", i+1)
+		}
+		data, err := os.ReadFile(candidate.File)
+		if err != nil {
+			return fmt.Errorf("bodyOneOf candidate %d: %w", i+1, err)
+		}
+		var value interface{}
+		if err := json.Unmarshal(data, &value); err != nil {
+			return fmt.Errorf("bodyOneOf candidate %d: invalid JSON in %s: %w", i+1, candidate.File, err)
+		}
+		if candidate.Name == "" {
+			candidate.Name = candidate.File
+		}
+		candidate.Value = value
+		candidate.hasValue = true
+		candidate.File = ""
+	}
+	return nil
+}
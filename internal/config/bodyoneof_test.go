@@ -0,0 +1,48 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBodyOneOfNullCandidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		candidate string
+		wantErr   string
+	}{
+		{name: "null value", candidate: "value: null"},
+		{name: "empty value", candidate: "value:"},
+		{name: "missing value", candidate: "name: nothing", wantErr: "value or file is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := LoadConfig(writeConfig(t, `
+endpoints:
+  - name: maybe
+    url: https://api.example.com/maybe
+    method: GET
+    expect:
+      bodyOneOf:
+        - value: {"id": 1}
+        - `+tt.candidate+`
+`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if value := cfg.Endpoints[0].Expect.BodyOneOf[1].Value; value != nil {
+					t.Errorf("value = %v, want nil", value)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
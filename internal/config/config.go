@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v2"
@@ -12,6 +15,94 @@ import (
 // Representation of the config file
 type Config struct {
 	Endpoints []Endpoint `yaml:"endpoints"`
+	// PreScripts run once before any endpoint
+	PreScripts []ScriptConfig `yaml:"preScripts"`
+	// Scenario, when set, replaces the per-endpoint runs with a mixed workload
+	Scenario *Scenario `yaml:"scenario"`
+	// RedactHeaders is a shorthand for Redact.Headers. Neither wins: on load
+	// both lists are combined into Redact.Headers and RedactHeaders is cleared
+	RedactHeaders []string `yaml:"redactHeaders"`
+	// Redact lists the sensitive headers, JSON paths and patterns hidden in
+	// the reports, events and logs, including the RedactHeaders once loaded
+	Redact *Redact `yaml:"redact"`
+	// Expect holds assertions about the run as a whole, checked after it
+	Expect *RunExpectation `yaml:"expect"`
+	// Metadata labels the run in the reports, e.g. with its environment
+	Metadata map[string]string `yaml:"metadata"`
+	// AllowedTargets, when set, fails requests to URLs outside the allowed
+	// schemes and hosts
+	AllowedTargets *AllowedTargets `yaml:"allowedTargets"`
+	// HealthCheck, when set, is checked before the run, which is aborted
+	// when the target is down
+	HealthCheck *HealthCheck `yaml:"healthCheck"`
+	// Profiles are named expectations shared by endpoints, which reference
+	// them as expect.profile
+	Profiles map[string]Expectation `yaml:"profiles"`
+}
+
+// Representation of the assertions about a whole run
+type RunExpectation struct {
+	// TotalRequests is the number of requests the run must make, guarding
+	// against silently dropped requests
+	TotalRequests *CountRange `yaml:"totalRequests"`
+}
+
+// CountRange is an expected count, either exact (`totalRequests: 100`) or a
+// range with an optional min and max (`totalRequests: {min: 90, max: 110}`).
+type CountRange struct {
+	Min *int `yaml:"min"`
+	Max *int `yaml:"max"`
+}
+
+// UnmarshalYAML accepts an exact count or a min/max mapping.
+func (c *CountRange) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var n int
+	if err := unmarshal(&n); err == nil {
+		*c = CountRange{Min: &n, Max: &n}
+		return nil
+	}
+
+	type plainCountRange CountRange
+	return unmarshal((*plainCountRange)(c))
+}
+
+// Contains reports whether n lies within the range.
+func (c CountRange) Contains(n int) bool {
+	return (c.Min == nil || n >= *c.Min) && (c.Max == nil || n <= *c.Max)
+}
+
+// String describes the range, e.g. "exactly 100" or "at least 90 and at most 110".
+func (c CountRange) String() string {
+	switch {
+	case c.Min != nil && c.Max != nil && *c.Min == *c.Max:
+		return fmt.Sprintf("exactly %d", *c.Min)
+	case c.Min != nil && c.Max != nil:
+		return fmt.Sprintf("at least %d and at most %d", *c.Min, *c.Max)
+	case c.Min != nil:
+		return fmt.Sprintf("at least %d", *c.Min)
+	case c.Max != nil:
+		return fmt.Sprintf("at most %d", *c.Max)
+	default:
+		return "any number"
+	}
+}
+
+// Representation of a mixed workload, where concurrent virtual users pick the
+// endpoint of every request by weight (e.g. 70% reads, 30% writes).
+type Scenario struct {
+	Users     Count              `yaml:"users"`
+	Total     Count              `yaml:"total"`
+	Delay     time.Duration      `yaml:"delay"`
+	Endpoints []WeightedEndpoint `yaml:"endpoints"`
+	// Jitter is the maximum random wait of a user before each request, like
+	// ConcurrentConfig.Jitter
+	Jitter time.Duration `yaml:"jitter"`
+}
+
+// Representation of an endpoint of a scenario, referenced by name
+type WeightedEndpoint struct {
+	Name   string `yaml:"name"`
+	Weight int    `yaml:"weight"`
 }
 
 // Representation of an endpoint in the config
@@ -25,69 +116,1147 @@ type Endpoint struct {
 	Expect     Expectation       `yaml:"expect"`
 	Retry      RetryConfig       `yaml:"retry"`
 	Concurrent ConcurrentConfig  `yaml:"concurrent"`
+	Capture    []Capture         `yaml:"capture"`
+	SLO        *SLOConfig        `yaml:"slo"`
+	PreScript  *ScriptConfig     `yaml:"preScript"`
+	HMAC       *HMACConfig       `yaml:"hmac"`
+	// MethodOverride, when set, sends Method in a header over a carrier method
+	MethodOverride *MethodOverride `yaml:"methodOverride"`
+	// Timeout limits the whole request, including reading the body
+	// (DefaultTimeout when unset). DialTimeout limits connecting and
+	// ResponseHeaderTimeout waiting for the response headers once the request
+	// is sent, e.g. for time-to-first-byte limits.
+	Timeout               time.Duration `yaml:"timeout"`
+	DialTimeout           time.Duration `yaml:"dialTimeout"`
+	ResponseHeaderTimeout time.Duration `yaml:"responseHeaderTimeout"`
+	// Host overrides the Host header, and the TLS server name of https URLs,
+	// e.g. to present a virtual host while connecting to an IP address. A Host
+	// entry in Headers is used the same way.
+	Host string `yaml:"host"`
+	// SSE, when set, reads the response as a stream of server-sent events.
+	SSE *SSEConfig `yaml:"sse"`
+	// Fallback, when set, is tried when a request to the endpoint fails.
+	Fallback *Fallback `yaml:"fallback"`
+	// TLS configures the TLS connections of https endpoints.
+	TLS *TLSConfig `yaml:"tls"`
+	// SaveResponseTo, when set, writes the body of every response to a file.
+	SaveResponseTo *SaveResponse `yaml:"saveResponseTo"`
+	// DependsOn names the endpoints that must finish before the endpoint
+	// starts. When any endpoint sets it, the endpoints run as a dependency
+	// graph, independent ones in parallel, instead of one after another.
+	DependsOn []string `yaml:"dependsOn"`
+	// Latency selects the requests covered by the latency statistics,
+	// LatencySuccessful (the default) or LatencyAll.
+	Latency string `yaml:"latency"`
+	// Protobuf, when set, sends and reads protobuf messages.
+	Protobuf *Protobuf `yaml:"protobuf"`
+}
+
+// Requests covered by the latency statistics of an endpoint. Failed requests,
+// including attempts retried by retry.count, are left out by default so that
+// slow failures do not skew the percentiles.
+const (
+	LatencySuccessful = "successful"
+	LatencyAll        = "all"
+)
+
+// Representation of the TLS settings of an endpoint
+type TLSConfig struct {
+	// DisableResumption makes every request open a new connection with a full
+	// TLS handshake, without resuming a previous session, to measure the
+	// worst-case connection setup cost.
+	DisableResumption bool `yaml:"disableResumption"`
+}
+
+// Representation of the fallback target of an endpoint, modelling client
+// failover: when a request fails after its transport and status retries, it
+// is sent to the fallback instead and validated against the same
+// expectations. Method, Headers and Body default to those of the endpoint.
+type Fallback struct {
+	URL     string            `yaml:"url"`
+	Method  string            `yaml:"method"`
+	Headers map[string]string `yaml:"headers"`
+	Body    string            `yaml:"body"`
+}
+
+// FallbackEndpoint returns the endpoint sending its requests to the fallback
+// target, without a fallback of its own. It must only be called on endpoints
+// with a Fallback.
+func (e Endpoint) FallbackEndpoint() Endpoint {
+	f := *e.Fallback
+	e.Fallback = nil
+	e.URL = f.URL
+	if f.Method != "" {
+		e.Method = f.Method
+	}
+	if f.Headers != nil {
+		e.Headers = f.Headers
+	}
+	if f.Body != "" {
+		e.Body = f.Body
+	}
+	return e
+}
+
+// DefaultSSEDuration is how long events are read when SSEConfig sets no duration.
+const DefaultSSEDuration = 10 * time.Second
+
+// Representation of a server-sent events stream: events are read until Events
+// events were received or Duration elapsed, whichever comes first, or until
+// the stream ends. Fewer than Events events fail the request. It can be
+// enabled with defaults as `sse: true`.
+type SSEConfig struct {
+	Duration time.Duration `yaml:"duration"`
+	Events   int           `yaml:"events"`
+}
+
+// UnmarshalYAML accepts either a boolean or a duration/events mapping.
+func (s *SSEConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var enabled bool
+	if err := unmarshal(&enabled); err == nil {
+		if !enabled {
+			return fmt.Errorf("sse: false is not supported, remove the key instead")
+		}
+		*s = SSEConfig{}
+		return nil
+	}
+
+	type plainSSEConfig SSEConfig
+	return unmarshal((*plainSSEConfig)(s))
+}
+
+// ReadDuration returns how long events are read at most.
+func (s SSEConfig) ReadDuration() time.Duration {
+	if s.Duration == 0 {
+		return DefaultSSEDuration
+	}
+	return s.Duration
+}
+
+// DefaultTimeout is the request timeout of endpoints that set none.
+const DefaultTimeout = 30 * time.Second
+
+// RequestTimeout returns the time a request of the endpoint may take: its
+// Timeout or DefaultTimeout, extended by the read duration of event streams.
+func (e Endpoint) RequestTimeout() time.Duration {
+	timeout := e.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	if e.SSE != nil {
+		timeout += e.SSE.ReadDuration()
+	}
+	return timeout
+}
+
+// DefaultMethodOverrideHeader is the header carrying the intended method.
+const DefaultMethodOverrideHeader = "X-HTTP-Method-Override"
+
+// Representation of method tunneling for gateways that only accept some
+// methods: the request is sent with the Carrier method (POST by default) and
+// the endpoint method in Header (X-HTTP-Method-Override by default).
+// It can be enabled with defaults as `methodOverride: true`.
+type MethodOverride struct {
+	Carrier string `yaml:"carrier"`
+	Header  string `yaml:"header"`
+}
+
+// UnmarshalYAML accepts either a boolean or a carrier/header mapping.
+func (m *MethodOverride) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var enabled bool
+	if err := unmarshal(&enabled); err == nil {
+		if !enabled {
+			return fmt.Errorf("methodOverride: false is not supported, remove the key instead")
+		}
+		*m = MethodOverride{}
+		return nil
+	}
+
+	type plainMethodOverride MethodOverride
+	return unmarshal((*plainMethodOverride)(m))
+}
+
+// CarrierMethod returns the method sent on the wire.
+func (m MethodOverride) CarrierMethod() string {
+	if m.Carrier == "" {
+		return "POST"
+	}
+	return strings.ToUpper(m.Carrier)
+}
+
+// HeaderName returns the header carrying the intended method.
+func (m MethodOverride) HeaderName() string {
+	if m.Header == "" {
+		return DefaultMethodOverrideHeader
+	}
+	return m.Header
+}
+
+// Representation of HMAC request signing. The signature of the request body is
+// sent in Header (X-Signature by default) as Prefix followed by the hex encoded
+// HMAC. Use ${ENV_VAR} substitution to keep the secret out of the config file.
+type HMACConfig struct {
+	Secret    string `yaml:"secret"`
+	Header    string `yaml:"header"`
+	Algorithm string `yaml:"algorithm"`
+	Prefix    string `yaml:"prefix"`
+}
+
+// Representation of a shell command run before requests are made, e.g. a CLI
+// that mints auth tokens. Its trimmed stdout is stored under Var and can be
+// referenced as {{captured.<var>}}.
+type ScriptConfig struct {
+	Command string        `yaml:"command"`
+	Var     string        `yaml:"var"`
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// Representation of a response-time service level objective, e.g.
+// 99% of requests succeed in under 200ms.
+type SLOConfig struct {
+	// Target is the fraction of requests that must meet the objective,
+	// given either as a fraction (0.99) or a percentage (99).
+	Target    float64       `yaml:"target"`
+	Threshold time.Duration `yaml:"threshold"`
+}
+
+// TargetFraction returns the SLO target as a fraction between 0 and 1.
+func (s SLOConfig) TargetFraction() float64 {
+	if s.Target > 1 {
+		return s.Target / 100
+	}
+	return s.Target
+}
+
+// Capture stores the value found at Path in a successful response under Name,
+// so later endpoints can reference it as {{captured.<name>}}.
+//
+// With Increasing, the value must be a number greater than the one previously
+// captured under Name, e.g. to check that generated IDs increase from request
+// to request. The order is that in which responses complete, so the check is
+// only meaningful for sequential requests.
+type Capture struct {
+	Name       string `yaml:"name"`
+	Path       string `yaml:"path"`
+	Increasing bool   `yaml:"increasing"`
 }
 
+// MatchAny is the wildcard of Expectation.Match and jsonEquals value checks,
+// matching any value, e.g. of generated IDs and timestamps.
+const MatchAny = "<any>"
+
 // Representation of the expected response
 type Expectation struct {
-	Status  int           `yaml:"status"`
+	// Profile names an entry of the top-level profiles whose checks the
+	// expectation extends, resolved when the config is loaded
+	Profile string        `yaml:"profile"`
+	Status  Status        `yaml:"status"`
 	MaxTime time.Duration `yaml:"maxTime"`
 	Values  []ValueCheck  `yaml:"values"`
+	AnyOf   []Variant     `yaml:"anyOf"`
+	Cookies []CookieCheck `yaml:"cookies"`
+	// Trailers are HTTP trailers the response must send after its body, as
+	// streaming APIs do to report their final status.
+	Trailers []TrailerCheck `yaml:"trailers"`
+	// Headers are response headers that must be present, optionally with a
+	// value, or absent, e.g. Server or X-Powered-By disclosing the software
+	// serving the API.
+	Headers []HeaderCheck `yaml:"headers"`
+	// ExactHeaders requires the response to send no headers other than those
+	// of the header checks, besides hop-by-hop headers, Date, Content-Length
+	// and IgnoreHeaders.
+	ExactHeaders  bool     `yaml:"exactHeaders"`
+	IgnoreHeaders []string `yaml:"ignoreHeaders"`
+	// JSON requires the body to be valid JSON, even without value checks.
+	JSON bool `yaml:"json"`
+	// Match is an example of the whole response body, compared structurally
+	// with it: objects must have the same keys, in any order, and arrays the
+	// same elements. The MatchAny string matches any value. It is given as
+	// YAML or as a string of JSON.
+	Match interface{} `yaml:"match"`
+	// BodyOneOf lists acceptable response bodies, of endpoints returning one
+	// of a few canned responses. The body must deeply equal one of them,
+	// compared like Match.
+	BodyOneOf []BodyCandidate `yaml:"bodyOneOf"`
+	// ContentEncoding is the expected Content-Encoding of the response, e.g.
+	// br or gzip ("identity" for none). Unless the endpoint sets an
+	// Accept-Encoding header, it is requested as the only accepted encoding.
+	ContentEncoding string `yaml:"contentEncoding"`
+	// ContentType is the expected media type of the response, ignoring
+	// parameters such as charset: a shorthand (json, xml, html, text, form)
+	// or a media type, e.g. application/pdf.
+	ContentType string `yaml:"contentType"`
+	// CheckContentLength fails responses whose Content-Length header differs
+	// from the number of body bytes read, e.g. truncated by a proxy.
+	CheckContentLength bool `yaml:"checkContentLength"`
+	// MaxCompressedRatio fails responses whose compressed body is larger than
+	// this fraction of the decompressed body, e.g. 0.3 for at most 30%, or
+	// that are not compressed. Unless the endpoint sets an Accept-Encoding
+	// header, gzip and deflate are requested.
+	MaxCompressedRatio float64 `yaml:"maxCompressedRatio"`
+	// ByStatus maps a response status to the checks applied when the response
+	// has that status, in addition to the checks above. Responses with another
+	// status are checked against the top-level checks when Status is set, and
+	// fail otherwise.
+	ByStatus map[int]Expectation `yaml:"byStatus"`
+	// AllowRedirects lets any 3xx response pass the status check. Redirects
+	// are not followed, so the redirect response itself is validated.
+	AllowRedirects bool `yaml:"allowRedirects"`
+	// RedirectLocation is the expected Location header of 3xx responses.
+	RedirectLocation *LocationCheck `yaml:"redirectLocation"`
+	// Unreachable inverts the verdict: the request passes when the endpoint
+	// cannot be reached and fails when it returns any response.
+	Unreachable bool `yaml:"unreachable"`
+	// MinRPS is the minimum number of requests per second the endpoint must
+	// sustain. It is checked once the endpoint finished, only for endpoints
+	// run concurrently or in a scenario, as the rate of a single request is
+	// meaningless.
+	MinRPS float64 `yaml:"minRps"`
+	// TLS, when set, checks the certificate presented by the server.
+	TLS *TLSExpectation `yaml:"tls"`
+}
+
+// Representation of the expected TLS certificate of a server, checked on every
+// response, e.g. to guard against certificates about to expire.
+type TLSExpectation struct {
+	// MinDaysToExpiry fails responses whose certificate expires in fewer days
+	MinDaysToExpiry int `yaml:"minDaysToExpiry"`
+	// Subject and Issuer, when set, must equal the common name or one of the
+	// organizations of the subject and issuer of the certificate
+	Subject string `yaml:"subject"`
+	Issuer  string `yaml:"issuer"`
+}
+
+// IsConcurrent reports whether the endpoint is run with several users, by
+// number, load profile or capacity search.
+func (c ConcurrentConfig) IsConcurrent() bool {
+	return c.Users > 0 || len(c.LoadProfile) > 0 || c.Autotune != nil
 }
 
-// Check if the response matches the expected values
+// Check if the response matches the expected values.
+// Path is a dot separated path into the JSON body (e.g. "data.items.0.id").
+// Value may reference a captured variable as "{{captured.<name>}}".
+// Op selects how the value is compared, see the ValueOp constants.
 type ValueCheck struct {
 	Path  string      `yaml:"path"`
 	Value interface{} `yaml:"value"`
+	Op    string      `yaml:"op"`
+	// Optional checks pass when the path is absent and only fail on a wrong value
+	Optional bool `yaml:"optional"`
+	// By is the field of the array elements compared by the sorted op, e.g.
+	// "createdAt"; without it the elements themselves are compared
+	By string `yaml:"by"`
+	// Direction is the order checked by the sorted op, asc (default) or desc
+	Direction string `yaml:"direction"`
+	// OtherPath is the path of the value the equalsPath op compares with,
+	// e.g. "updatedBy" for a Path of "createdBy"
+	OtherPath string `yaml:"otherPath"`
+	// Quantifier, all or any, makes the check apply to the elements of the
+	// array at Path: all of them, or at least one, must have the value at
+	// Element, compared with the equals (default) or jsonEquals op
+	Quantifier string `yaml:"quantifier"`
+	// Element is the path within each array element of a quantified check,
+	// e.g. "status"; without it the elements themselves are compared
+	Element string `yaml:"element"`
+	// ValueType, when set, converts Value to a JSON type before comparing,
+	// see the ValueType constants
+	ValueType string `yaml:"valueType"`
+	// IgnoreOrder makes the jsonEquals op compare arrays as multisets, so
+	// their elements may come in any order
+	IgnoreOrder bool `yaml:"ignoreOrder"`
+}
+
+// Quantifiers of value checks on array elements
+const (
+	QuantifierAll = "all"
+	QuantifierAny = "any"
+)
+
+// Value check operators
+const (
+	// ValueOpEquals compares the values keeping their types (the default)
+	ValueOpEquals = "equals"
+	// ValueOpJSONEquals deeply compares a structured value with the subtree at
+	// the path, ignoring the order of object keys
+	ValueOpJSONEquals = "jsonEquals"
+	// ValueOpSorted checks that the array at the path is sorted, see By and Direction
+	ValueOpSorted = "sorted"
+	// ValueOpEqualsPath deeply compares the values at the path and at OtherPath
+	// of the same response
+	ValueOpEqualsPath = "equalsPath"
+)
+
+// Sort directions of the sorted op
+const (
+	SortAscending  = "asc"
+	SortDescending = "desc"
+)
+
+// validateValueChecks checks that the value checks use known operators.
+func validateValueChecks(checks []ValueCheck) error {
+	for _, check := range checks {
+		switch check.Quantifier {
+		case "":
+			if check.Element != "" {
+				return fmt.Errorf("value check %s: element requires a quantifier", check.Path)
+			}
+		case QuantifierAll, QuantifierAny:
+			if check.Op != "" && check.Op != ValueOpEquals && check.Op != ValueOpJSONEquals {
+				return fmt.Errorf("value check %s: quantifier %s requires op %s or %s", check.Path, check.Quantifier, ValueOpEquals, ValueOpJSONEquals)
+			}
+		default:
+			return fmt.Errorf("value check %s: unknown quantifier %s, expected %s or %s", check.Path, check.Quantifier, QuantifierAll, QuantifierAny)
+		}
+		if check.IgnoreOrder && check.Op != ValueOpJSONEquals {
+			return fmt.Errorf("value check %s: ignoreOrder requires op %s", check.Path, ValueOpJSONEquals)
+		}
+		switch check.Op {
+		case "", ValueOpEquals, ValueOpJSONEquals:
+		case ValueOpSorted:
+			if check.Direction != "" && check.Direction != SortAscending && check.Direction != SortDescending {
+				return fmt.Errorf("value check %s: unknown direction %s, expected %s or %s",
+					check.Path, check.Direction, SortAscending, SortDescending)
+			}
+		case ValueOpEqualsPath:
+			if check.OtherPath == "" {
+				return fmt.Errorf("value check %s: op %s requires otherPath", check.Path, ValueOpEqualsPath)
+			}
+		default:
+			return fmt.Errorf("value check %s: unknown op %s", check.Path, check.Op)
+		}
+		if err := validateValueType(check); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateExpectation checks the value and header checks of the expectation,
+// its anyOf variants and byStatus blocks.
+func validateExpectation(expect Expectation) error {
+	if err := validateValueChecks(expect.Values); err != nil {
+		return err
+	}
+	for _, variant := range expect.AnyOf {
+		if err := validateValueChecks(variant.Values); err != nil {
+			return err
+		}
+	}
+	for i, candidate := range expect.BodyOneOf {
+		if !candidate.hasValue && candidate.File == "" {
+			return fmt.Errorf("bodyOneOf candidate %d: value or file is required", i+1)
+		}
+	}
+	if expect.ContentType != "" {
+		if err := validateContentType(expect.ContentType); err != nil {
+			return err
+		}
+	}
+	if expect.MaxCompressedRatio < 0 || expect.MaxCompressedRatio > 1 {
+		return fmt.Errorf("maxCompressedRatio must be between 0 and 1, got %g", expect.MaxCompressedRatio)
+	}
+	if expect.TLS != nil && expect.TLS.MinDaysToExpiry < 0 {
+		return fmt.Errorf("tls.minDaysToExpiry must not be negative")
+	}
+	if len(expect.IgnoreHeaders) > 0 && !expect.ExactHeaders {
+		log.Println("ignoreHeaders only applies with exactHeaders")
+	}
+	for _, check := range expect.Headers {
+		if check.Name == "" {
+			return fmt.Errorf("headers: name is required")
+		}
+		if check.Absent && check.Value != nil {
+			return fmt.Errorf("headers: %s cannot be absent and have a value", check.Name)
+		}
+	}
+	if l := expect.RedirectLocation; l != nil {
+		if (l.Value == "") == (l.Regex == "") {
+			return fmt.Errorf("redirectLocation requires either value or regex")
+		}
+		if l.Regex != "" {
+			if _, err := regexp.Compile(l.Regex); err != nil {
+				return fmt.Errorf("redirectLocation: invalid regex: %w", err)
+			}
+		}
+	}
+	for _, block := range expect.ByStatus {
+		if err := validateExpectation(block); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Representation of an expected Location header, either an exact Value or a
+// Regex the header must match. It can be given as a plain string for an
+// exact value, e.g. `redirectLocation: /login`.
+type LocationCheck struct {
+	Value string `yaml:"value"`
+	Regex string `yaml:"regex"`
+}
+
+// UnmarshalYAML accepts either a string or a value/regex mapping.
+func (l *LocationCheck) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var value string
+	if err := unmarshal(&value); err == nil {
+		*l = LocationCheck{Value: value}
+		return nil
+	}
+
+	type plainLocationCheck LocationCheck
+	return unmarshal((*plainLocationCheck)(l))
+}
+
+// Matches reports whether the Location header matches the check. An invalid
+// regex, rejected by Validate, matches nothing.
+func (l LocationCheck) Matches(location string) bool {
+	if l.Regex == "" {
+		return location == l.Value
+	}
+	matched, err := regexp.MatchString(l.Regex, location)
+	return err == nil && matched
+}
+
+// String describes the check in messages.
+func (l LocationCheck) String() string {
+	if l.Regex == "" {
+		return l.Value
+	}
+	return "matching " + l.Regex
+}
+
+// Variant is one of several acceptable response bodies. The body matches the
+// variant when all of its value checks pass.
+type Variant struct {
+	Name   string       `yaml:"name"`
+	Values []ValueCheck `yaml:"values"`
+}
+
+// errorFields are the top-level JSON fields of typical error responses.
+var errorFields = []string{"error", "errors", "fault"}
+
+// warnSuspicious warns about expectations that are valid but unlikely to be
+// meant: a maximum response time the request timeout preempts, value checks
+// on error fields of responses expected to succeed, and quoted numbers and
+// booleans, see warnQuotedValues.
+func (e Expectation) warnSuspicious(endpoint string, timeout time.Duration) {
+	e.warnQuotedValues(endpoint)
+	if e.MaxTime > timeout {
+		log.Println("endpoint", endpoint, "expect.maxTime", e.MaxTime, "exceeds the request timeout", timeout, "and cannot fail")
+	}
+	if !e.Status.IsSet() || e.Unreachable {
+		return
+	}
+	for _, r := range e.Status {
+		if r.Min < 200 || r.Max > 299 {
+			return
+		}
+	}
+	for _, check := range e.Values {
+		field := strings.SplitN(strings.TrimPrefix(check.Path, "$."), ".", 2)[0]
+		if containsFold(errorFields, field) {
+			log.Println("endpoint", endpoint, "expects status", e.Status, "but checks the error field", check.Path,
+				"- did you mean an error status or expect.byStatus?")
+			return
+		}
+	}
+}
+
+// Check if the response sets a cookie with the expected attributes.
+// Attributes left unset are not checked.
+type CookieCheck struct {
+	Name     string  `yaml:"name"`
+	Value    *string `yaml:"value"`
+	HttpOnly *bool   `yaml:"httpOnly"`
+	Secure   *bool   `yaml:"secure"`
+	SameSite string  `yaml:"sameSite"`
+}
+
+// DefaultStatus is the status expected when an expectation leaves it unset.
+const DefaultStatus = 200
+
+// applyDefaults sets the status and maximum response time of an expectation
+// that does not set them, and warns about the defaulted status, which would
+// otherwise fail every response. The maximum response time defaults to the
+// request timeout, i.e. no limit, so that the report shows the effective
+// limit; the validator also treats a zero maximum as no limit. An expectation
+// with byStatus blocks or expecting the endpoint to be unreachable keeps a
+// zero status.
+func (e *Expectation) applyDefaults(endpoint string, timeout time.Duration) {
+	if !e.Status.IsSet() && len(e.ByStatus) == 0 && !e.Unreachable {
+		log.Println("endpoint", endpoint, "sets no expect.status, expecting", DefaultStatus)
+		e.Status = ExactStatus(DefaultStatus)
+	}
+	if e.MaxTime == 0 {
+		e.MaxTime = timeout
+	}
+}
+
+// Representation of an expected HTTP trailer. Without a value, the trailer only
+// has to be present.
+type TrailerCheck struct {
+	Name  string  `yaml:"name"`
+	Value *string `yaml:"value"`
+}
+
+// Representation of an expected response header. Without a value, the header
+// only has to be present; with Absent it must not be sent at all.
+type HeaderCheck struct {
+	Name   string  `yaml:"name"`
+	Value  *string `yaml:"value"`
+	Absent bool    `yaml:"absent"`
 }
 
 // Representation of the retry configuration
+//
+// Count retries the whole request when validation fails. TransportRetries
+// reconnects when a request fails before a response is received (e.g. a dropped
+// connection), and StatusRetries resends a request answered with one of the
+// RetryOn statuses (502, 503 and 504 by default). All retries wait Delay.
 type RetryConfig struct {
-	Count int           `yaml:"count"`
-	Delay time.Duration `yaml:"delay"`
+	Count            int           `yaml:"count"`
+	Delay            time.Duration `yaml:"delay"`
+	TransportRetries int           `yaml:"transportRetries"`
+	StatusRetries    int           `yaml:"statusRetries"`
+	RetryOn          []int         `yaml:"retryOn"`
+}
+
+// DefaultRetryStatuses are the statuses retried by StatusRetries when RetryOn is empty.
+var DefaultRetryStatuses = []int{502, 503, 504}
+
+// ShouldRetryStatus reports whether a response with the given status should be
+// retried by StatusRetries.
+func (r RetryConfig) ShouldRetryStatus(status int) bool {
+	statuses := r.RetryOn
+	if len(statuses) == 0 {
+		statuses = DefaultRetryStatuses
+	}
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Count is a number of users or requests. Besides an integer, it accepts a
+// string holding one, so a quoted environment variable reference such as
+// users: "${LOAD_USERS}" can scale the load without editing the config.
+type Count int
+
+// UnmarshalYAML accepts an integer or a string containing an integer.
+func (c *Count) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var n int
+	if err := unmarshal(&n); err == nil {
+		*c = Count(n)
+		return nil
+	}
+
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	missing := map[string]bool{}
+	s = expandString(s, missing)
+	if err := missingEnvError(missing); err != nil {
+		return err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return fmt.Errorf("expected an integer, got %q", s)
+	}
+	*c = Count(n)
+	return nil
 }
 
 // Representation of the concurrent configuration
+//
+// With a LoadProfile, the endpoint is run for the duration of every stage in
+// turn with the stage's number of users, and Users and Total are not used.
 type ConcurrentConfig struct {
-	Users int           `yaml:"users"`
-	Delay time.Duration `yaml:"delay"`
-	Total int           `yaml:"total"`
+	Users       Count         `yaml:"users"`
+	Delay       time.Duration `yaml:"delay"`
+	Total       Count         `yaml:"total"`
+	LoadProfile []LoadStage   `yaml:"loadProfile"`
+	ThinkTime   *ThinkTime    `yaml:"thinkTime"`
+	Autotune    *Autotune     `yaml:"autotune"`
+	Benchmark   *Benchmark    `yaml:"benchmark"`
+	// Jitter, when set, is the maximum random wait of a user before each
+	// request, drawn from the seeded random source, to spread out the
+	// requests of users firing at the same cadence.
+	Jitter time.Duration `yaml:"jitter"`
+	// TargetRPS, when set, replaces Delay and ThinkTime with a pause adjusted
+	// during the run to hold the endpoint at this many requests per second as
+	// the latency of the server varies. Delay is the initial pause.
+	TargetRPS float64 `yaml:"targetRps"`
+	// MaxInFlight, when set, caps the number of requests of the endpoint in
+	// flight at once, whatever the number of users. A request waiting for a
+	// slot counts the wait towards its latency.
+	MaxInFlight Count `yaml:"maxInFlight"`
+}
+
+// Representation of a capacity search: the endpoint is run in steps of
+// StepDuration, starting with Start users and adding Step users per step up to
+// Max. The search stops when the P95 latency exceeds TargetP95, the error rate
+// exceeds MaxErrorRate (percent) or the requests per second improve by less
+// than MinImprovement percent (5 by default). Users and Total are not used.
+type Autotune struct {
+	Start          Count         `yaml:"start"`
+	Step           Count         `yaml:"step"`
+	Max            Count         `yaml:"max"`
+	StepDuration   time.Duration `yaml:"stepDuration"`
+	TargetP95      time.Duration `yaml:"targetP95"`
+	MaxErrorRate   float64       `yaml:"maxErrorRate"`
+	MinImprovement *float64      `yaml:"minImprovement"`
+}
+
+// Representation of a benchmark: the batch of Users sending Total requests is
+// run Cycles times, each cycle after Warmup requests that are not measured,
+// and the statistics of the cycles are aggregated to reduce noise.
+type Benchmark struct {
+	Cycles Count `yaml:"cycles"`
+	Warmup Count `yaml:"warmup"`
+}
+
+// DefaultMinImprovement is the RPS improvement in percent an autotune step
+// needs over the best one to continue the search.
+const DefaultMinImprovement = 5.0
+
+// MinImprovementPercent returns the configured or default minimum improvement.
+func (a Autotune) MinImprovementPercent() float64 {
+	if a.MinImprovement == nil {
+		return DefaultMinImprovement
+	}
+	return *a.MinImprovement
+}
+
+// Think time distributions
+const (
+	ThinkTimeUniform     = "uniform"
+	ThinkTimeExponential = "exponential"
+)
+
+// Representation of a randomized pause of every virtual user between its
+// requests, in addition to Delay. Uniform (the default) draws it evenly from
+// [Min, Max]; exponential draws Min plus an exponentially distributed time
+// with a mean of half the range, capped at Max, so most pauses are short.
+type ThinkTime struct {
+	Min          time.Duration `yaml:"min"`
+	Max          time.Duration `yaml:"max"`
+	Distribution string        `yaml:"distribution"`
+}
+
+// Representation of a stage of a stepped load profile
+type LoadStage struct {
+	Users    Count         `yaml:"users"`
+	Duration time.Duration `yaml:"duration"`
+}
+
+// LoadOptions configures how a configuration file is loaded.
+type LoadOptions struct {
+	// Strict rejects unknown keys, e.g. a misspelled `conncurrent:`, which are
+	// silently ignored otherwise.
+	Strict bool
+	// SkipFiles leaves the files referenced by the config, listed by
+	// ReferencedFiles, unread, e.g. to check them separately.
+	SkipFiles bool
 }
 
 // LoadConfig loads a configuration from a YAML file at the given path.
-// It returns an error if the file cannot be read or if the YAML is invalid.
+// References to environment variables (${NAME}) in string values are
+// substituted once the YAML is parsed.
+// It returns an error if the file cannot be read, if a referenced variable
+// is not set or if the YAML is invalid.
 func LoadConfig(path string) (*Config, error) {
+	return LoadConfigWithOptions(path, LoadOptions{})
+}
+
+// LoadConfigWithOptions loads a configuration like LoadConfig, with the
+// loading behaviour configured by opts.
+func LoadConfigWithOptions(path string, opts LoadOptions) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
 
 	var config Config
-	if err := yaml.Unmarshal(data, &config); err != nil {
+	if opts.Strict {
+		if err := yaml.UnmarshalStrict(data, &config); err != nil {
+			return nil, explainUnknownFields(err)
+		}
+	} else if err := yaml.Unmarshal(data, &config); err != nil {
 		return nil, err
 	}
 
+	if err := config.expandEnv(); err != nil {
+		return nil, err
+	}
+	if err := config.applyProfiles(); err != nil {
+		return nil, err
+	}
+	if err := config.applyDefaults(); err != nil {
+		return nil, err
+	}
+	if opts.SkipFiles {
+		return &config, nil
+	}
+	if err := config.loadBodyCandidates(); err != nil {
+		return nil, err
+	}
 	return &config, nil
 }
 
+// applyDefaults completes a loaded configuration: the redactHeaders are
+// merged into redact, whose patterns are compiled, and expectations get their
+// default status and maximum response time, see Expectation.applyDefaults.
+func (c *Config) applyDefaults() error {
+	if len(c.RedactHeaders) > 0 {
+		if c.Redact == nil {
+			c.Redact = &Redact{}
+		}
+		c.Redact.Headers = append(c.Redact.Headers, c.RedactHeaders...)
+		c.RedactHeaders = nil
+	}
+	if c.Redact != nil {
+		if err := c.Redact.compile(); err != nil {
+			return err
+		}
+	}
+	for i := range c.Endpoints {
+		c.Endpoints[i].Expect.applyDefaults(c.Endpoints[i].Name, c.Endpoints[i].RequestTimeout())
+	}
+	return nil
+}
+
+// Validate checks the configuration, returning the first problem found. It
+// logs warnings about valid but suspicious settings and does not change the
+// configuration.
 func (c *Config) Validate() error {
 	if len(c.Endpoints) == 0 {
 		log.Println("no endpoints defined")
 		return fmt.Errorf("no endpoints defined")
 	}
 
+	if c.Expect != nil && c.Expect.TotalRequests != nil {
+		t := c.Expect.TotalRequests
+		if (t.Min == nil && t.Max == nil) || (t.Min != nil && *t.Min < 0) || (t.Min != nil && t.Max != nil && *t.Max < *t.Min) {
+			log.Println("expect.totalRequests requires 0 <= min <= max")
+			return fmt.Errorf("expect.totalRequests: requires 0 <= min <= max")
+		}
+	}
+
+	if c.Redact != nil {
+		if err := c.Redact.validate(); err != nil {
+			log.Println(err)
+			return err
+		}
+	}
+
+	if c.AllowedTargets != nil {
+		if err := c.AllowedTargets.validate(); err != nil {
+			log.Println(err)
+			return err
+		}
+	}
+
+	if c.HealthCheck != nil {
+		if err := c.HealthCheck.validate(c.AllowedTargets); err != nil {
+			log.Println(err)
+			return err
+		}
+	}
+
+	if c.Scenario != nil {
+		if err := c.validateScenario(); err != nil {
+			log.Println(err)
+			return err
+		}
+	}
+
+	if err := c.validateDependencies(); err != nil {
+		log.Println(err)
+		return err
+	}
+	if c.Scenario != nil && c.HasDependencies() {
+		log.Println("the scenario ignores dependsOn")
+	}
+
+	for _, s := range c.PreScripts {
+		if s.Command == "" || s.Var == "" {
+			log.Println("preScripts require command and var")
+			return fmt.Errorf("preScripts: command and var are required")
+		}
+	}
+
+	for _, e := range c.Endpoints {
+		e.Expect.warnSuspicious(e.Name, e.RequestTimeout())
+	}
+
 	for _, e := range c.Endpoints {
 		if e.URL == "" {
 			log.Println("endpoint", e.Name, "missing URL")
 			return fmt.Errorf("endpoint %s: missing URL", e.Name)
 		}
+		if err := validateScheme(e.URL); err != nil {
+			log.Println("endpoint", e.Name, err)
+			return fmt.Errorf("endpoint %s: %w", e.Name, err)
+		}
+		// URLs with placeholders are checked once interpolated, at run time
+		if c.AllowedTargets != nil && !strings.Contains(e.URL, "{{") {
+			if err := c.AllowedTargets.Check(e.URL); err != nil {
+				log.Println("endpoint", e.Name, err)
+				return fmt.Errorf("endpoint %s: %w", e.Name, err)
+			}
+		}
 		if e.Method == "" {
 			log.Println("endpoint", e.Name, "missing method")
 			return fmt.Errorf("endpoint %s: missing method", e.Name)
 		}
-		if e.Concurrent.Users > 0 && e.Concurrent.Total == 0 {
+		if e.Expect.MinRPS < 0 {
+			log.Println("endpoint", e.Name, "expect.minRps must not be negative")
+			return fmt.Errorf("endpoint %s: expect.minRps must not be negative", e.Name)
+		}
+		if e.Expect.MinRPS > 0 && !e.Concurrent.IsConcurrent() && c.Scenario == nil {
+			log.Println("endpoint", e.Name, "is not concurrent, expect.minRps is ignored")
+		}
+		if e.Expect.TLS != nil && !strings.HasPrefix(strings.ToLower(e.URL), "https://") {
+			log.Println("endpoint", e.Name, "is not an https URL, expect.tls fails unless redirected to one")
+		}
+		if e.Protobuf != nil {
+			if err := validateProtobuf(*e.Protobuf); err != nil {
+				log.Println("endpoint", e.Name, err)
+				return fmt.Errorf("endpoint %s: %w", e.Name, err)
+			}
+		}
+		if e.SaveResponseTo != nil {
+			if err := validateSaveResponse(e, c.Scenario != nil); err != nil {
+				log.Println("endpoint", e.Name, err)
+				return fmt.Errorf("endpoint %s: %w", e.Name, err)
+			}
+		}
+		switch e.Latency {
+		case "", LatencySuccessful, LatencyAll:
+		default:
+			log.Println("endpoint", e.Name, "unknown latency", e.Latency)
+			return fmt.Errorf("endpoint %s: unknown latency %s, expected %s or %s",
+				e.Name, e.Latency, LatencySuccessful, LatencyAll)
+		}
+		if e.Expect.MaxTime < 0 {
+			log.Println("endpoint", e.Name, "expect.maxTime must not be negative")
+			return fmt.Errorf("endpoint %s: expect.maxTime must not be negative", e.Name)
+		}
+		if e.Timeout < 0 || e.DialTimeout < 0 || e.ResponseHeaderTimeout < 0 {
+			log.Println("endpoint", e.Name, "timeouts must not be negative")
+			return fmt.Errorf("endpoint %s: timeouts must not be negative", e.Name)
+		}
+		if e.SSE != nil && (e.SSE.Duration < 0 || e.SSE.Events < 0) {
+			log.Println("endpoint", e.Name, "sse duration and events must not be negative")
+			return fmt.Errorf("endpoint %s: sse duration and events must not be negative", e.Name)
+		}
+		for _, c := range e.Capture {
+			if c.Name == "" || c.Path == "" {
+				log.Println("endpoint", e.Name, "capture requires name and path")
+				return fmt.Errorf("endpoint %s: capture requires name and path", e.Name)
+			}
+		}
+		if e.Retry.Count < 0 || e.Retry.TransportRetries < 0 || e.Retry.StatusRetries < 0 {
+			log.Println("endpoint", e.Name, "retry counts must not be negative")
+			return fmt.Errorf("endpoint %s: retry counts must not be negative", e.Name)
+		}
+		if e.PreScript != nil && (e.PreScript.Command == "" || e.PreScript.Var == "") {
+			log.Println("endpoint", e.Name, "preScript requires command and var")
+			return fmt.Errorf("endpoint %s: preScript requires command and var", e.Name)
+		}
+		if err := validateExpectation(e.Expect); err != nil {
+			log.Println("endpoint", e.Name, err)
+			return fmt.Errorf("endpoint %s: %w", e.Name, err)
+		}
+		for status, block := range e.Expect.ByStatus {
+			if len(block.ByStatus) > 0 {
+				log.Println("endpoint", e.Name, "byStatus", status, "must not be nested")
+				return fmt.Errorf("endpoint %s: byStatus %d must not be nested", e.Name, status)
+			}
+			if block.Status.IsSet() && !block.Status.Matches(status) {
+				log.Println("endpoint", e.Name, "byStatus", status, "has conflicting status", block.Status)
+				return fmt.Errorf("endpoint %s: byStatus %d has conflicting status %s", e.Name, status, block.Status)
+			}
+		}
+		if e.Fallback != nil {
+			if err := c.validateFallback(e); err != nil {
+				log.Println("endpoint", e.Name, err)
+				return fmt.Errorf("endpoint %s: %w", e.Name, err)
+			}
+		}
+		if e.HMAC != nil && e.HMAC.Secret == "" {
+			log.Println("endpoint", e.Name, "hmac requires a secret")
+			return fmt.Errorf("endpoint %s: hmac requires a secret", e.Name)
+		}
+		if e.SLO != nil {
+			if target := e.SLO.TargetFraction(); target <= 0 || target >= 1 {
+				log.Println("endpoint", e.Name, "slo target must be between 0 and 100%")
+				return fmt.Errorf("endpoint %s: slo target must be between 0 and 100%%", e.Name)
+			}
+			if e.SLO.Threshold <= 0 {
+				log.Println("endpoint", e.Name, "slo threshold must be positive")
+				return fmt.Errorf("endpoint %s: slo threshold must be positive", e.Name)
+			}
+		}
+		if t := e.Concurrent.ThinkTime; t != nil {
+			if t.Min < 0 || t.Max < t.Min {
+				log.Println("endpoint", e.Name, "thinkTime requires 0 <= min <= max")
+				return fmt.Errorf("endpoint %s: thinkTime requires 0 <= min <= max", e.Name)
+			}
+			if t.Distribution != "" && t.Distribution != ThinkTimeUniform && t.Distribution != ThinkTimeExponential {
+				log.Println("endpoint", e.Name, "unknown thinkTime distribution", t.Distribution)
+				return fmt.Errorf("endpoint %s: unknown thinkTime distribution %s, expected %s or %s",
+					e.Name, t.Distribution, ThinkTimeUniform, ThinkTimeExponential)
+			}
+		}
+		if e.Concurrent.Jitter < 0 {
+			log.Println("endpoint", e.Name, "jitter must not be negative")
+			return fmt.Errorf("endpoint %s: jitter must not be negative", e.Name)
+		}
+		if m := e.Concurrent.MaxInFlight; m != 0 {
+			if m < 0 {
+				log.Println("endpoint", e.Name, "maxInFlight must be positive")
+				return fmt.Errorf("endpoint %s: maxInFlight must be positive", e.Name)
+			}
+			switch {
+			case !e.Concurrent.IsConcurrent() && c.Scenario == nil:
+				log.Println("endpoint", e.Name, "is not concurrent, maxInFlight is ignored")
+			case len(e.Concurrent.LoadProfile) == 0 && e.Concurrent.Autotune == nil && c.Scenario == nil && m >= e.Concurrent.Users:
+				log.Println("endpoint", e.Name, "maxInFlight", m, "is not below the", e.Concurrent.Users, "users and has no effect")
+			}
+		}
+		if t := e.Concurrent.TargetRPS; t != 0 {
+			if t < 0 {
+				log.Println("endpoint", e.Name, "targetRps must be positive")
+				return fmt.Errorf("endpoint %s: targetRps must be positive", e.Name)
+			}
+			if e.Concurrent.Autotune != nil || e.Concurrent.ThinkTime != nil {
+				log.Println("endpoint", e.Name, "targetRps cannot be combined with autotune or thinkTime")
+				return fmt.Errorf("endpoint %s: targetRps cannot be combined with autotune or thinkTime", e.Name)
+			}
+			if e.Concurrent.Users <= 0 && len(e.Concurrent.LoadProfile) == 0 {
+				log.Println("endpoint", e.Name, "targetRps requires concurrent users or a loadProfile")
+				return fmt.Errorf("endpoint %s: targetRps requires concurrent users or a loadProfile", e.Name)
+			}
+		}
+		for i, stage := range e.Concurrent.LoadProfile {
+			if stage.Users <= 0 || stage.Duration <= 0 {
+				log.Println("endpoint", e.Name, "load profile stage", i+1, "requires positive users and duration")
+				return fmt.Errorf("endpoint %s: load profile stage %d requires positive users and duration", e.Name, i+1)
+			}
+		}
+		if a := e.Concurrent.Autotune; a != nil {
+			if a.Start <= 0 || a.Step <= 0 || a.Max < a.Start || a.StepDuration <= 0 {
+				log.Println("endpoint", e.Name, "autotune requires positive start, step and stepDuration and max >= start")
+				return fmt.Errorf("endpoint %s: autotune requires positive start, step and stepDuration and max >= start", e.Name)
+			}
+			if a.TargetP95 <= 0 && a.MaxErrorRate <= 0 && a.MinImprovementPercent() <= 0 {
+				log.Println("endpoint", e.Name, "autotune requires a targetP95, maxErrorRate or minImprovement")
+				return fmt.Errorf("endpoint %s: autotune requires a targetP95, maxErrorRate or minImprovement", e.Name)
+			}
+			if len(e.Concurrent.LoadProfile) > 0 {
+				log.Println("endpoint", e.Name, "autotune and loadProfile are mutually exclusive")
+				return fmt.Errorf("endpoint %s: autotune and loadProfile are mutually exclusive", e.Name)
+			}
+		}
+		if b := e.Concurrent.Benchmark; b != nil {
+			if b.Cycles <= 0 || b.Warmup < 0 {
+				log.Println("endpoint", e.Name, "benchmark requires positive cycles and a warmup that is not negative")
+				return fmt.Errorf("endpoint %s: benchmark requires positive cycles and a warmup that is not negative", e.Name)
+			}
+			if len(e.Concurrent.LoadProfile) > 0 || e.Concurrent.Autotune != nil {
+				log.Println("endpoint", e.Name, "benchmark cannot be combined with loadProfile or autotune")
+				return fmt.Errorf("endpoint %s: benchmark cannot be combined with loadProfile or autotune", e.Name)
+			}
+			if e.Concurrent.Users <= 0 {
+				log.Println("endpoint", e.Name, "benchmark requires concurrent users and total requests")
+				return fmt.Errorf("endpoint %s: benchmark requires concurrent users and total requests", e.Name)
+			}
+		}
+		if len(e.Concurrent.LoadProfile) == 0 && e.Concurrent.Autotune == nil && e.Concurrent.Users > 0 && e.Concurrent.Total == 0 {
 			log.Println("endpoint", e.Name, "concurrent users set but total requests not specified")
 			return fmt.Errorf("endpoint %s: concurrent users set but total requests not specified", e.Name)
 		}
 	}
 	return nil
 }
+
+// SupportedSchemes are the URL schemes endpoints can be tested with.
+var SupportedSchemes = []string{"http", "https"}
+
+// validateScheme checks that a URL uses a supported scheme. URLs starting
+// with a template placeholder are only known at run time and are not checked.
+func validateScheme(rawURL string) error {
+	if strings.HasPrefix(rawURL, "{{") {
+		return nil
+	}
+
+	i := strings.Index(rawURL, "://")
+	if i <= 0 {
+		return fmt.Errorf("URL %s has no scheme, expected one of %s", rawURL, strings.Join(SupportedSchemes, ", "))
+	}
+	scheme := strings.ToLower(rawURL[:i])
+	for _, s := range SupportedSchemes {
+		if scheme == s {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported scheme %s in URL %s, expected one of %s", scheme, rawURL, strings.Join(SupportedSchemes, ", "))
+}
+
+// validateFallback checks the URL of the fallback of the endpoint like that
+// of the endpoint itself.
+func (c *Config) validateFallback(e Endpoint) error {
+	if e.Fallback.URL == "" {
+		return fmt.Errorf("fallback: missing URL")
+	}
+	if err := validateScheme(e.Fallback.URL); err != nil {
+		return fmt.Errorf("fallback: %w", err)
+	}
+	if c.AllowedTargets != nil && !strings.Contains(e.Fallback.URL, "{{") {
+		if err := c.AllowedTargets.Check(e.Fallback.URL); err != nil {
+			return fmt.Errorf("fallback: %w", err)
+		}
+	}
+	if e.Expect.Unreachable {
+		return fmt.Errorf("fallback cannot be used with expect.unreachable")
+	}
+	return nil
+}
+
+// validateScenario checks that the scenario has users and requests, and that
+// it references existing endpoints with positive weights.
+func (c *Config) validateScenario() error {
+	s := c.Scenario
+	if s.Users <= 0 || s.Total <= 0 {
+		return fmt.Errorf("scenario: users and total must be positive")
+	}
+	if len(s.Endpoints) == 0 {
+		return fmt.Errorf("scenario: no endpoints defined")
+	}
+	if s.Jitter < 0 {
+		return fmt.Errorf("scenario: jitter must not be negative")
+	}
+	for _, we := range s.Endpoints {
+		if we.Weight <= 0 {
+			return fmt.Errorf("scenario: endpoint %s: weight must be positive", we.Name)
+		}
+		if _, ok := c.Endpoint(we.Name); !ok {
+			return fmt.Errorf("scenario: unknown endpoint %s", we.Name)
+		}
+	}
+	return nil
+}
+
+// Endpoint returns the endpoint with the given name.
+func (c *Config) Endpoint(name string) (Endpoint, bool) {
+	for _, e := range c.Endpoints {
+		if e.Name == name {
+			return e, true
+		}
+	}
+	return Endpoint{}, false
+}
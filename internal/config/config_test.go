@@ -0,0 +1,91 @@
+package config
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestLoadConfigAppliesDefaultsAndValidateIsReadOnly(t *testing.T) {
+	path := writeConfig(t, `
+redactHeaders: [X-Api-Key]
+redact:
+  patterns: ["token=([^&]+)"]
+endpoints:
+  - name: users
+    url: https://api.example.com/users
+    method: GET
+    timeout: 5s
+  - name: missing
+    url: https://api.example.com/missing
+    method: GET
+    expect:
+      status: 404
+      maxTime: 1s
+`)
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	users := cfg.Endpoints[0].Expect
+	if !users.Status.Matches(DefaultStatus) || users.MaxTime != cfg.Endpoints[0].RequestTimeout() {
+		t.Errorf("defaults not applied on load: status %v, maxTime %s", users.Status, users.MaxTime)
+	}
+	if missing := cfg.Endpoints[1].Expect; !missing.Status.Matches(404) || missing.Status.Matches(200) || missing.MaxTime.String() != "1s" {
+		t.Errorf("set values were overridden: status %v, maxTime %s", missing.Status, missing.MaxTime)
+	}
+	if !cfg.Redact.IsHeader("X-Api-Key") || cfg.RedactHeaders != nil {
+		t.Errorf("redactHeaders not merged into redact: %+v", cfg.Redact)
+	}
+	if got := cfg.Redact.Text("?token=abc&x=1"); got != "?token=***&x=1" {
+		t.Errorf("patterns not compiled on load: %s", got)
+	}
+
+	before, err := yaml.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	after, err := yaml.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(before) != string(after) {
+		t.Errorf("Validate changed the config:\n%s\nbecame\n%s", before, after)
+	}
+}
+
+func TestRedactHeadersCombineWithRedact(t *testing.T) {
+	path := writeConfig(t, `
+redactHeaders: [X-Api-Key]
+redact:
+  headers: [X-Session]
+  paths: [user.password]
+endpoints:
+  - name: users
+    url: https://api.example.com/users
+    method: GET
+`)
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"X-Api-Key", "x-session", "Authorization"} {
+		if !cfg.Redact.IsHeader(name) {
+			t.Errorf("%s is not redacted: %+v", name, cfg.Redact.Headers)
+		}
+	}
+	if cfg.Redact.IsHeader("Accept") {
+		t.Error("Accept is redacted")
+	}
+	if len(cfg.Redact.Paths) != 1 || cfg.Redact.Paths[0] != "user.password" {
+		t.Errorf("redact paths lost when merging redactHeaders: %v", cfg.Redact.Paths)
+	}
+	if cfg.RedactHeaders != nil {
+		t.Errorf("redactHeaders not cleared once merged: %v", cfg.RedactHeaders)
+	}
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"fmt"
+	"mime"
+	"strings"
+)
+
+// contentTypeShorthands are the media types matched by the shorthands of
+// expect.contentType. The json and xml shorthands also match structured
+// syntax suffixes, e.g. application/problem+json.
+var contentTypeShorthands = map[string][]string{
+	"json": {"application/json"},
+	"xml":  {"application/xml", "text/xml"},
+	"html": {"text/html"},
+	"text": {"text/plain"},
+	"form": {"application/x-www-form-urlencoded"},
+}
+
+// ContentTypeMatches reports whether a Content-Type header matches the
+// expected content type, a shorthand (json, xml, html, text, form) or a media
+// type. Parameters such as charset are ignored, and a missing or invalid
+// header matches nothing.
+func ContentTypeMatches(expected, header string) bool {
+	actual, _, err := mime.ParseMediaType(header)
+	if err != nil {
+		return false
+	}
+
+	expected = strings.ToLower(strings.TrimSpace(expected))
+	if types, ok := contentTypeShorthands[expected]; ok {
+		for _, t := range types {
+			if actual == t {
+				return true
+			}
+		}
+		return (expected == "json" || expected == "xml") && strings.HasSuffix(actual, "+"+expected)
+	}
+
+	want, _, err := mime.ParseMediaType(expected)
+	return err == nil && actual == want
+}
+
+// validateContentType checks that the expected content type is a shorthand
+// or a valid media type.
+func validateContentType(expected string) error {
+	if _, ok := contentTypeShorthands[strings.ToLower(strings.TrimSpace(expected))]; ok {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(expected)
+	if err != nil || !strings.Contains(mediaType, "/") {
+		return fmt.Errorf("invalid contentType %q, expected json, xml, html, text, form or a media type", expected)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package config
+
+import "testing"
+
+func TestContentTypeMatches(t *testing.T) {
+	tests := []struct {
+		expected, header string
+		want             bool
+	}{
+		{"json", "application/json; charset=utf-8", true},
+		{"json", "text/html", false},
+		{"JSON", "Application/JSON", true},
+		{"json", "application/problem+json", true},
+		{"json", "", false},
+		{"json", "application/json; charset", false},
+		{"xml", "text/xml; charset=iso-8859-1", true},
+		{"html", "text/html;charset=UTF-8", true},
+		{"text", "text/html", false},
+		{"form", "application/x-www-form-urlencoded", true},
+		{"application/vnd.api+json", "application/vnd.api+json; version=2", true},
+		{"application/vnd.api+json", "application/json", false},
+		{"text/csv; charset=utf-8", "text/csv", true},
+	}
+	for _, tt := range tests {
+		if got := ContentTypeMatches(tt.expected, tt.header); got != tt.want {
+			t.Errorf("ContentTypeMatches(%q, %q) = %v, want %v", tt.expected, tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestValidateContentType(t *testing.T) {
+	for expected, valid := range map[string]bool{"json": true, " Html ": true, "image/png": true, "jsonn": false, "text/": false} {
+		if err := validateContentType(expected); (err == nil) != valid {
+			t.Errorf("validateContentType(%q) = %v, want valid %v", expected, err, valid)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// capturedRef matches a reference to a captured variable, e.g. {{captured.userId}}.
+var capturedRef = regexp.MustCompile(`\{\{\s*captured\.([A-Za-z0-9_\-]+)\s*\}\}`)
+
+// HasDependencies reports whether any endpoint depends on others, in which
+// case the endpoints run as a dependency graph instead of one after another.
+func (c *Config) HasDependencies() bool {
+	for _, e := range c.Endpoints {
+		if len(e.DependsOn) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// capturedRefs returns the names of the captured variables referenced by the
+// endpoint in any of its fields, e.g. its URL or an expected value.
+func (e Endpoint) capturedRefs() []string {
+	data, err := yaml.Marshal(e)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, m := range capturedRef.FindAllStringSubmatch(string(data), -1) {
+		names = append(names, m[1])
+	}
+	return names
+}
+
+// implicitDependencies returns the endpoints every endpoint needs without
+// listing them in dependsOn, by name: for each captured variable it
+// references, the closest endpoint before it in the file capturing the
+// variable. Endpoints capturing a variable after it are not waited for.
+func (c *Config) implicitDependencies() map[string][]string {
+	implicit := make(map[string][]string)
+	capturedBy := make(map[string]string)
+	for _, e := range c.Endpoints {
+		for _, name := range e.capturedRefs() {
+			dep, ok := capturedBy[name]
+			if ok && dep != e.Name && !containsString(e.DependsOn, dep) && !containsString(implicit[e.Name], dep) {
+				implicit[e.Name] = append(implicit[e.Name], dep)
+			}
+		}
+		for _, capture := range e.Capture {
+			capturedBy[capture.Name] = e.Name
+		}
+	}
+	return implicit
+}
+
+// DependencyGraph returns the endpoints every endpoint waits for when they
+// run as a dependency graph, by name: those in its dependsOn and those
+// capturing the variables it references, see implicitDependencies.
+func (c *Config) DependencyGraph() map[string][]string {
+	graph := c.implicitDependencies()
+	for _, e := range c.Endpoints {
+		graph[e.Name] = append(append([]string(nil), e.DependsOn...), graph[e.Name]...)
+	}
+	return graph
+}
+
+// containsString reports whether values contains s.
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// validateDependencies checks that the endpoints depend on known endpoints,
+// with unique names, and that their dependencies, including the implicit
+// ones, have no cycle. Implicit dependencies are logged, as they order the
+// graph without being declared.
+func (c *Config) validateDependencies() error {
+	if !c.HasDependencies() {
+		return nil
+	}
+
+	byName := make(map[string]Endpoint, len(c.Endpoints))
+	for _, e := range c.Endpoints {
+		if _, ok := byName[e.Name]; ok {
+			return fmt.Errorf("endpoint %s: names must be unique when endpoints use dependsOn", e.Name)
+		}
+		byName[e.Name] = e
+	}
+	for _, e := range c.Endpoints {
+		for _, dep := range e.DependsOn {
+			if _, ok := byName[dep]; !ok {
+				return fmt.Errorf("endpoint %s: depends on unknown endpoint %s", e.Name, dep)
+			}
+		}
+	}
+	implicit := c.implicitDependencies()
+	for _, e := range c.Endpoints {
+		for _, dep := range implicit[e.Name] {
+			log.Printf("endpoint %s references a variable captured by %s without listing it in dependsOn, so it waits for %s", e.Name, dep, dep)
+		}
+	}
+	graph := c.DependencyGraph()
+
+	// depth-first search, reporting the first cycle found as a path
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int, len(c.Endpoints))
+	var path []string
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			start := 0
+			for path[start] != name {
+				start++
+			}
+			cycle := append(append([]string{}, path[start:]...), name)
+			return fmt.Errorf("dependency cycle: %s", strings.Join(cycle, " -> "))
+		}
+		state[name] = visiting
+		path = append(path, name)
+		for _, dep := range graph[name] {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+		return nil
+	}
+	for _, e := range c.Endpoints {
+		if err := visit(e.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDependencyGraphInfersCapturedReferences(t *testing.T) {
+	cfg := Config{Endpoints: []Endpoint{
+		{Name: "login", Capture: []Capture{{Name: "token", Path: "token"}}},
+		{Name: "create", Headers: map[string]string{"Authorization": "Bearer {{captured.token}}"}, Capture: []Capture{{Name: "id", Path: "id"}}},
+		{Name: "get", URL: "https://api.example.com/items/{{ captured.id }}", DependsOn: []string{"login"}},
+		{Name: "check", Expect: Expectation{Values: []ValueCheck{{Path: "owner", Value: "{{captured.id}}"}}}},
+		{Name: "early", Body: `{"later": "{{captured.late}}"}`},
+		{Name: "late", Capture: []Capture{{Name: "late", Path: "x"}}},
+	}}
+
+	graph := cfg.DependencyGraph()
+	want := map[string][]string{
+		"login":  nil,
+		"create": {"login"},
+		"get":    {"login", "create"},
+		"check":  {"create"},
+		"early":  nil,
+		"late":   nil,
+	}
+	for name, deps := range want {
+		if !reflect.DeepEqual(graph[name], deps) && (len(graph[name]) > 0 || len(deps) > 0) {
+			t.Errorf("%s depends on %v, want %v", name, graph[name], deps)
+		}
+	}
+}
+
+func TestValidateDependenciesRejectsImplicitCycles(t *testing.T) {
+	cfg := Config{Endpoints: []Endpoint{
+		{Name: "a", Capture: []Capture{{Name: "id", Path: "id"}}, DependsOn: []string{"b"}},
+		{Name: "b", URL: "https://api.example.com/{{captured.id}}"},
+	}}
+	err := cfg.validateDependencies()
+	if err == nil || !strings.Contains(err.Error(), "dependency cycle") {
+		t.Fatalf("err = %v, want a dependency cycle", err)
+	}
+}
@@ -0,0 +1,192 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Formats of Dump.
+const (
+	DumpYAML = "yaml"
+	DumpJSON = "json"
+)
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	statusType   = reflect.TypeOf(Status(nil))
+)
+
+// Dump encodes the config as YAML or JSON, as it will run once loaded and
+// validated: with the environment variables substituted and the defaults
+// applied. Unset settings are left out. Secrets are redacted: the values of
+// redacted headers, HMAC secrets, bodies and checked values at the redacted
+// paths, and the matches of the redaction patterns in any text.
+func (c *Config) Dump(format string) ([]byte, error) {
+	d := dumper{redact: c.Redact}
+	tree := d.value(reflect.ValueOf(c))
+	switch format {
+	case DumpYAML:
+		return yaml.Marshal(tree)
+	case DumpJSON:
+		data, err := json.MarshalIndent(jsonTree(tree), "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	default:
+		return nil, fmt.Errorf("unknown format %q, expected %s or %s", format, DumpYAML, DumpJSON)
+	}
+}
+
+// dumper converts a config into a tree of ordered mappings, lists and
+// scalars, keyed by the YAML names of the fields.
+type dumper struct {
+	redact *Redact
+	// raw disables redaction, inside the redact settings themselves
+	raw bool
+}
+
+// value converts v, returning nil for unset values.
+func (d dumper) value(v reflect.Value) interface{} {
+	if !v.IsValid() || v.IsZero() {
+		return nil
+	}
+	switch v.Type() {
+	case durationType:
+		return time.Duration(v.Int()).String()
+	case statusType:
+		return v.Interface().(Status).String()
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return d.value(v.Elem())
+	case reflect.String:
+		return d.text(v.String())
+	case reflect.Struct:
+		return d.fields(v)
+	case reflect.Slice, reflect.Array:
+		if v.Len() == 0 {
+			return nil
+		}
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			items[i] = d.value(v.Index(i))
+		}
+		return items
+	case reflect.Map:
+		if v.Len() == 0 {
+			return nil
+		}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+		out := make(yaml.MapSlice, 0, len(keys))
+		for _, key := range keys {
+			out = append(out, yaml.MapItem{Key: fmt.Sprint(key), Value: d.value(v.MapIndex(key))})
+		}
+		return out
+	default:
+		return v.Interface()
+	}
+}
+
+// fields converts the exported fields of a struct, redacting the secrets it
+// holds by their field names.
+func (d dumper) fields(v reflect.Value) yaml.MapSlice {
+	out := yaml.MapSlice{}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fv := v.Field(i)
+		if fv.IsZero() {
+			continue
+		}
+
+		var value interface{}
+		switch {
+		case d.raw:
+			value = d.value(fv)
+		case name == "redact":
+			value = dumper{raw: true}.value(fv)
+		case name == "secret":
+			value = RedactedValue
+		case name == "body" && fv.Kind() == reflect.String:
+			value = d.redact.Body(fv.String())
+		case name == "headers" && fv.Kind() == reflect.Map:
+			headers := yaml.MapSlice{}
+			for _, key := range sortedKeys(fv.Interface().(map[string]string)) {
+				headers = append(headers, yaml.MapItem{Key: key, Value: d.redact.Header(key, fv.MapIndex(reflect.ValueOf(key)).String())})
+			}
+			value = headers
+		case name == "value" && d.redactsPath(v):
+			value = RedactedValue
+		default:
+			value = d.value(fv)
+		}
+		if value != nil {
+			out = append(out, yaml.MapItem{Key: name, Value: value})
+		}
+	}
+	return out
+}
+
+// redactsPath reports whether v is a check of a value at a redacted path.
+func (d dumper) redactsPath(v reflect.Value) bool {
+	path := v.FieldByName("Path")
+	return path.IsValid() && path.Kind() == reflect.String && d.redact.IsPath(path.String())
+}
+
+// text redacts the matches of the patterns in s.
+func (d dumper) text(s string) string {
+	if d.raw {
+		return s
+	}
+	return d.redact.Text(s)
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// jsonTree converts the ordered mappings of a dumped tree into maps, which
+// encoding/json writes with sorted keys.
+func jsonTree(v interface{}) interface{} {
+	switch v := v.(type) {
+	case yaml.MapSlice:
+		out := make(map[string]interface{}, len(v))
+		for _, item := range v {
+			out[fmt.Sprint(item.Key)] = jsonTree(item.Value)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = jsonTree(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
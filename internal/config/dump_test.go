@@ -0,0 +1,83 @@
+package config
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestDumpResolvesTheConfig(t *testing.T) {
+	t.Setenv("API_HOST", "api.example.com")
+	t.Setenv("API_TOKEN", "token-secret")
+
+	path := writeConfig(t, `
+redact:
+  paths: [password]
+endpoints:
+  - name: login
+    url: https://${API_HOST}/login
+    method: POST
+    headers:
+      Authorization: Bearer ${API_TOKEN}
+      X-Trace: "1"
+    hmac:
+      secret: hmac-secret
+    expect:
+      values:
+        - path: password
+          value: hunter2
+`)
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := cfg.Dump(DumpYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dump := string(data)
+	for _, secret := range []string{"token-secret", "hmac-secret", "hunter2"} {
+		if strings.Contains(dump, secret) {
+			t.Errorf("dump contains %q:\n%s", secret, dump)
+		}
+	}
+
+	var dumped Config
+	if err := yaml.Unmarshal(data, &dumped); err != nil {
+		t.Fatalf("%v:\n%s", err, dump)
+	}
+	e := dumped.Endpoints[0]
+	if e.URL != "https://api.example.com/login" {
+		t.Errorf("url = %q, want the substituted host", e.URL)
+	}
+	if e.Expect.Status.String() != "200" {
+		t.Errorf("status = %q, want the default 200", e.Expect.Status)
+	}
+	if e.Expect.MaxTime != DefaultTimeout {
+		t.Errorf("maxTime = %s, want the default %s", e.Expect.MaxTime, DefaultTimeout)
+	}
+	if e.Headers["Authorization"] != RedactedValue || e.Headers["X-Trace"] != "1" {
+		t.Errorf("headers = %v", e.Headers)
+	}
+	if strings.Contains(dump, "retry:") || strings.Contains(dump, "concurrent:") {
+		t.Errorf("dump contains unset settings:\n%s", dump)
+	}
+
+	data, err = cfg.Dump(DumpJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tree map[string]interface{}
+	if err := json.Unmarshal(data, &tree); err != nil {
+		t.Fatalf("%v:\n%s", err, data)
+	}
+	if _, err := cfg.Dump("toml"); err == nil {
+		t.Error("unknown format accepted")
+	}
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// envRef matches a reference to an environment variable, e.g. ${API_SECRET}.
+var envRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandString substitutes the ${NAME} references in s with the values of the
+// environment variables. Other uses of $ are left untouched. The names of
+// unset variables are added to missing and their references are kept.
+func expandString(s string, missing map[string]bool) string {
+	return envRef.ReplaceAllStringFunc(s, func(ref string) string {
+		name := envRef.FindStringSubmatch(ref)[1]
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			missing[name] = true
+			return ref
+		}
+		return value
+	})
+}
+
+// missingEnvError reports the unset variables referenced by the config.
+func missingEnvError(missing map[string]bool) error {
+	if len(missing) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(missing))
+	for name := range missing {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return fmt.Errorf("environment variables not set: %s", strings.Join(names, ", "))
+}
+
+// expandEnv substitutes ${NAME} references in the string values of the parsed
+// config. Substituting after parsing keeps values containing YAML syntax such
+// as `:`, `#`, quotes or newlines from changing the structure of the file, and
+// references in comments are never looked up. It fails when a referenced
+// variable is not set.
+func (c *Config) expandEnv() error {
+	missing := map[string]bool{}
+	expandValue(reflect.ValueOf(c).Elem(), missing)
+	return missingEnvError(missing)
+}
+
+// expandValue walks v and substitutes the references in every string it
+// holds. Map keys are left as written.
+func expandValue(v reflect.Value, missing map[string]bool) {
+	switch v.Kind() {
+	case reflect.String:
+		if v.CanSet() {
+			v.SetString(expandString(v.String(), missing))
+		}
+	case reflect.Ptr:
+		if !v.IsNil() {
+			expandValue(v.Elem(), missing)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				expandValue(v.Field(i), missing)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			expandValue(v.Index(i), missing)
+		}
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			// Map values are not addressable, so they are expanded in a copy
+			value := reflect.New(v.Type().Elem()).Elem()
+			value.Set(v.MapIndex(key))
+			expandValue(value, missing)
+			v.SetMapIndex(key, value)
+		}
+	case reflect.Interface:
+		if v.IsNil() || !v.CanSet() {
+			return
+		}
+		value := reflect.New(v.Elem().Type()).Elem()
+		value.Set(v.Elem())
+		if value.Kind() == reflect.String {
+			v.Set(reflect.ValueOf(expandScalar(value.String(), missing)))
+			return
+		}
+		expandValue(value, missing)
+		v.Set(value)
+	}
+}
+
+// expandScalar expands an untyped value, e.g. an expected value. A value that
+// is a single reference, such as `equals: ${USER_ID}`, takes the YAML type of
+// the variable's value, so USER_ID=42 is compared as a number. Values that
+// would parse as a mapping or a sequence stay strings.
+func expandScalar(s string, missing map[string]bool) interface{} {
+	expanded := expandString(s, missing)
+	if expanded == s || envRef.FindString(s) != s {
+		return expanded
+	}
+	var value interface{}
+	if err := yaml.Unmarshal([]byte(expanded), &value); err != nil {
+		return expanded
+	}
+	switch value.(type) {
+	case bool, int, int64, uint64, float64:
+		return value
+	}
+	return expanded
+}
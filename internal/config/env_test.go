@@ -0,0 +1,115 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeConfig writes a config file into a temporary directory and returns its path.
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfigExpandsEnv(t *testing.T) {
+	t.Setenv("API_HOST", "api.example.com")
+	t.Setenv("API_SECRET", `s3cr#t: "quoted"`+"\nnext: line")
+	t.Setenv("USER_ID", "42")
+	t.Setenv("LOAD_USERS", "5")
+
+	path := writeConfig(t, `
+# ${NOT_SET} in a comment is ignored
+endpoints:
+  - name: users
+    url: https://${API_HOST}/users
+    method: GET
+    hmac:
+      secret: ${API_SECRET}
+    expect:
+      values:
+        - path: id
+          value: ${USER_ID}
+        - path: label
+          value: user-${USER_ID}
+    concurrent:
+      users: "${LOAD_USERS}"
+      total: 10
+`)
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := cfg.Endpoints[0]
+	if e.URL != "https://api.example.com/users" {
+		t.Errorf("url = %q", e.URL)
+	}
+	if want := `s3cr#t: "quoted"` + "\nnext: line"; e.HMAC.Secret != want {
+		t.Errorf("secret = %q, want %q", e.HMAC.Secret, want)
+	}
+	if got := e.Expect.Values[0].Value; got != 42 {
+		t.Errorf("single reference = %#v, want the number 42", got)
+	}
+	if got := e.Expect.Values[1].Value; got != "user-42" {
+		t.Errorf("embedded reference = %#v, want \"user-42\"", got)
+	}
+	if e.Concurrent.Users != 5 {
+		t.Errorf("users = %d, want 5", e.Concurrent.Users)
+	}
+}
+
+func TestLoadConfigMissingEnv(t *testing.T) {
+	os.Unsetenv("TMAGO_TEST_UNSET")
+	path := writeConfig(t, `
+endpoints:
+  - name: users
+    url: https://${TMAGO_TEST_UNSET}/users
+    method: GET
+`)
+	_, err := LoadConfig(path)
+	if err == nil || !strings.Contains(err.Error(), "TMAGO_TEST_UNSET") {
+		t.Fatalf("err = %v, want the unset variable named", err)
+	}
+}
+
+func TestLoadConfigParsesCountsFromEnv(t *testing.T) {
+	t.Setenv("LOAD_USERS", " 8 ")
+	t.Setenv("LOAD_TOTAL", "200")
+
+	path := writeConfig(t, `
+endpoints:
+  - name: users
+    url: https://api.example.com/users
+    method: GET
+    concurrent:
+      loadProfile:
+        - users: "${LOAD_USERS}"
+          duration: 1s
+scenario:
+  users: "${LOAD_USERS}"
+  total: "${LOAD_TOTAL}"
+  endpoints:
+    - name: users
+      weight: 1
+`)
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Endpoints[0].Concurrent.LoadProfile[0].Users; got != 8 {
+		t.Errorf("stage users = %d, want 8", got)
+	}
+	if cfg.Scenario.Users != 8 || cfg.Scenario.Total != 200 {
+		t.Errorf("scenario users, total = %d, %d, want 8, 200", cfg.Scenario.Users, cfg.Scenario.Total)
+	}
+
+	t.Setenv("LOAD_USERS", "many")
+	if _, err := LoadConfig(path); err == nil || !strings.Contains(err.Error(), `expected an integer, got "many"`) {
+		t.Fatalf("err = %v, want the non-integer count reported", err)
+	}
+}
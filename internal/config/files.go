@@ -0,0 +1,45 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+)
+
+// FileRef is a file the config references, read when it is loaded or when
+// the run starts.
+type FileRef struct {
+	// Endpoint is the name of the endpoint referencing the file
+	Endpoint string
+	// Use describes what the file is used for, e.g. "bodyOneOf candidate 2"
+	Use  string
+	Path string
+}
+
+// ReferencedFiles returns the files referenced by the endpoints: the files of
+// bodyOneOf candidates, unless they were read when loading, and protobuf
+// descriptor sets.
+func (c *Config) ReferencedFiles() []FileRef {
+	var refs []FileRef
+	addCandidates := func(endpoint, prefix string, candidates []BodyCandidate) {
+		for i, candidate := range candidates {
+			if candidate.File != "" {
+				refs = append(refs, FileRef{Endpoint: endpoint, Use: fmt.Sprintf("%sbodyOneOf candidate %d", prefix, i+1), Path: candidate.File})
+			}
+		}
+	}
+	for _, e := range c.Endpoints {
+		addCandidates(e.Name, "", e.Expect.BodyOneOf)
+		statuses := make([]int, 0, len(e.Expect.ByStatus))
+		for status := range e.Expect.ByStatus {
+			statuses = append(statuses, status)
+		}
+		sort.Ints(statuses)
+		for _, status := range statuses {
+			addCandidates(e.Name, fmt.Sprintf("byStatus %d ", status), e.Expect.ByStatus[status].BodyOneOf)
+		}
+		if e.Protobuf != nil && e.Protobuf.Descriptor != "" {
+			refs = append(refs, FileRef{Endpoint: e.Name, Use: "protobuf descriptor", Path: e.Protobuf.Descriptor})
+		}
+	}
+	return refs
+}
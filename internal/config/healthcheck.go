@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Health check failure policies.
+const (
+	// HealthCheckFail aborts the run as failed when the health check fails
+	HealthCheckFail = "fail"
+	// HealthCheckSkip aborts the run as skipped, with its own exit code, so
+	// an environment that is down does not count as failed tests
+	HealthCheckSkip = "skip"
+)
+
+// DefaultHealthCheckTimeout bounds a health check without a timeout.
+const DefaultHealthCheckTimeout = 10 * time.Second
+
+// HealthCheck is a request sent before the run to verify the target is up.
+// No endpoint runs when it fails.
+type HealthCheck struct {
+	URL     string            `yaml:"url"`
+	Headers map[string]string `yaml:"headers"`
+	// Status is the expected status code, 200 when unset
+	Status  int           `yaml:"status"`
+	Timeout time.Duration `yaml:"timeout"`
+	// OnFailure is fail (the default) or skip
+	OnFailure string `yaml:"onFailure"`
+}
+
+// ExpectedStatus returns the status code the health check expects.
+func (h HealthCheck) ExpectedStatus() int {
+	if h.Status == 0 {
+		return http.StatusOK
+	}
+	return h.Status
+}
+
+// RequestTimeout returns the timeout of the health check.
+func (h HealthCheck) RequestTimeout() time.Duration {
+	if h.Timeout == 0 {
+		return DefaultHealthCheckTimeout
+	}
+	return h.Timeout
+}
+
+// validate checks the health check settings, including its URL against the
+// allowed targets.
+func (h HealthCheck) validate(allowed *AllowedTargets) error {
+	if h.URL == "" {
+		return fmt.Errorf("healthCheck: missing URL")
+	}
+	if err := validateScheme(h.URL); err != nil {
+		return fmt.Errorf("healthCheck: %w", err)
+	}
+	if allowed != nil {
+		if err := allowed.Check(h.URL); err != nil {
+			return fmt.Errorf("healthCheck: %w", err)
+		}
+	}
+	if h.Status != 0 && (h.Status < 100 || h.Status > 599) {
+		return fmt.Errorf("healthCheck: invalid status %d", h.Status)
+	}
+	if h.Timeout < 0 {
+		return fmt.Errorf("healthCheck: timeout cannot be negative")
+	}
+	switch h.OnFailure {
+	case "", HealthCheckFail, HealthCheckSkip:
+	default:
+		return fmt.Errorf("healthCheck: invalid onFailure %q, expected %s or %s", h.OnFailure, HealthCheckFail, HealthCheckSkip)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package config
+
+import "fmt"
+
+// applyProfiles merges the profile referenced by the expectation of every
+// endpoint into it. The checks of the profile come first, followed by those
+// of the endpoint, see mergeExpectation. Unknown profiles and profiles
+// referenced where they are not resolved, by profiles or byStatus blocks, are
+// errors. The reference is cleared once resolved, so that a dumped config
+// does not apply the profile twice.
+func (c *Config) applyProfiles() error {
+	for name, profile := range c.Profiles {
+		if profile.Profile != "" {
+			return fmt.Errorf("profile %s: profiles must not reference other profiles", name)
+		}
+		if err := checkNoProfile(profile.ByStatus); err != nil {
+			return fmt.Errorf("profile %s: %w", name, err)
+		}
+	}
+
+	for i := range c.Endpoints {
+		e := &c.Endpoints[i]
+		if err := checkNoProfile(e.Expect.ByStatus); err != nil {
+			return fmt.Errorf("endpoint %s: %w", e.Name, err)
+		}
+		if e.Expect.Profile == "" {
+			continue
+		}
+		profile, ok := c.Profiles[e.Expect.Profile]
+		if !ok {
+			return fmt.Errorf("endpoint %s: unknown profile %s", e.Name, e.Expect.Profile)
+		}
+		e.Expect = mergeExpectation(profile, e.Expect)
+	}
+	return nil
+}
+
+// checkNoProfile returns an error for byStatus blocks referencing a profile.
+func checkNoProfile(byStatus map[int]Expectation) error {
+	for status, block := range byStatus {
+		if block.Profile != "" {
+			return fmt.Errorf("byStatus %d: profile is only allowed at the top of expect", status)
+		}
+	}
+	return nil
+}
+
+// mergeExpectation returns the expectation of a profile extended by the
+// expectation of an endpoint. Lists of checks are concatenated, while the
+// status, maxTime and other single settings of the endpoint replace those of
+// the profile when set; anyOf variants replace them as a whole, as do
+// byStatus blocks for the same status. Flags such as json are set when set in
+// either.
+func mergeExpectation(profile, endpoint Expectation) Expectation {
+	merged := profile
+	if endpoint.Status.IsSet() {
+		merged.Status = endpoint.Status
+	}
+	if endpoint.MaxTime > 0 {
+		merged.MaxTime = endpoint.MaxTime
+	}
+	merged.Values = append(append([]ValueCheck{}, profile.Values...), endpoint.Values...)
+	if len(endpoint.AnyOf) > 0 {
+		merged.AnyOf = endpoint.AnyOf
+	}
+	merged.Cookies = append(append([]CookieCheck{}, profile.Cookies...), endpoint.Cookies...)
+	merged.Trailers = append(append([]TrailerCheck{}, profile.Trailers...), endpoint.Trailers...)
+	merged.Headers = append(append([]HeaderCheck{}, profile.Headers...), endpoint.Headers...)
+	merged.ExactHeaders = profile.ExactHeaders || endpoint.ExactHeaders
+	merged.IgnoreHeaders = append(append([]string{}, profile.IgnoreHeaders...), endpoint.IgnoreHeaders...)
+	merged.JSON = profile.JSON || endpoint.JSON
+	if endpoint.Match != nil {
+		merged.Match = endpoint.Match
+	}
+	if len(endpoint.BodyOneOf) > 0 {
+		merged.BodyOneOf = endpoint.BodyOneOf
+	}
+	if endpoint.ContentEncoding != "" {
+		merged.ContentEncoding = endpoint.ContentEncoding
+	}
+	if endpoint.ContentType != "" {
+		merged.ContentType = endpoint.ContentType
+	}
+	merged.CheckContentLength = profile.CheckContentLength || endpoint.CheckContentLength
+	if endpoint.MaxCompressedRatio != 0 {
+		merged.MaxCompressedRatio = endpoint.MaxCompressedRatio
+	}
+	if len(endpoint.ByStatus) > 0 {
+		merged.ByStatus = make(map[int]Expectation, len(profile.ByStatus)+len(endpoint.ByStatus))
+		for status, block := range profile.ByStatus {
+			merged.ByStatus[status] = block
+		}
+		for status, block := range endpoint.ByStatus {
+			merged.ByStatus[status] = block
+		}
+	}
+	merged.AllowRedirects = profile.AllowRedirects || endpoint.AllowRedirects
+	if endpoint.RedirectLocation != nil {
+		merged.RedirectLocation = endpoint.RedirectLocation
+	}
+	merged.Unreachable = profile.Unreachable || endpoint.Unreachable
+	if endpoint.MinRPS > 0 {
+		merged.MinRPS = endpoint.MinRPS
+	}
+	if endpoint.TLS != nil {
+		merged.TLS = endpoint.TLS
+	}
+	return merged
+}
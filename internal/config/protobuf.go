@@ -0,0 +1,28 @@
+package config
+
+import "fmt"
+
+// Protobuf sends and reads the bodies of an endpoint as binary protobuf
+// messages. Descriptor is a descriptor set holding the message types, written
+// by protoc with --include_imports --descriptor_set_out. The body, written in
+// the JSON form of the Request message, is encoded before it is sent, and
+// responses are decoded from the Response message to its JSON form for the
+// checks and captures. Either message may be omitted to keep that side as is.
+type Protobuf struct {
+	Descriptor string `yaml:"descriptor"`
+	Request    string `yaml:"request"`
+	Response   string `yaml:"response"`
+}
+
+// validateProtobuf checks that the protobuf settings of an endpoint name a
+// descriptor set and at least one message. The messages are resolved when
+// the runner loads the descriptor set.
+func validateProtobuf(p Protobuf) error {
+	if p.Descriptor == "" {
+		return fmt.Errorf("protobuf requires a descriptor")
+	}
+	if p.Request == "" && p.Response == "" {
+		return fmt.Errorf("protobuf requires a request or response message")
+	}
+	return nil
+}
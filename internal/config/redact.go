@@ -0,0 +1,226 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// RedactedValue replaces redacted values in reports, events and logs.
+const RedactedValue = "***"
+
+// DefaultRedactedHeaders are the headers whose values are always redacted.
+var DefaultRedactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie"}
+
+// Redact lists the sensitive data replaced with RedactedValue before requests
+// and responses are written to the reports, events and logs. The methods of
+// a nil Redact only redact the default headers.
+type Redact struct {
+	// Headers are request and response headers whose values are redacted, in
+	// addition to DefaultRedactedHeaders
+	Headers []string `yaml:"headers"`
+	// Paths are dot separated JSON paths (e.g. user.password) whose values are
+	// redacted in bodies and validation messages. A * segment matches any key
+	// or array index, e.g. users.*.email.
+	Paths []string `yaml:"paths"`
+	// Patterns are regular expressions whose matches are redacted in any text.
+	// When a pattern has groups, only the groups are redacted, e.g.
+	// `token=([^&]+)` keeps the parameter name.
+	Patterns []string `yaml:"patterns"`
+
+	patterns []*regexp.Regexp
+}
+
+// compile compiles the patterns, failing on the first invalid one.
+func (r *Redact) compile() error {
+	patterns, err := r.compiledPatterns()
+	if err != nil {
+		return err
+	}
+	r.patterns = patterns
+	return nil
+}
+
+// validate checks that the patterns compile and that no path is empty.
+func (r *Redact) validate() error {
+	if _, err := r.compiledPatterns(); err != nil {
+		return err
+	}
+	for _, p := range r.Paths {
+		if p == "" {
+			return fmt.Errorf("redact: empty path")
+		}
+	}
+	return nil
+}
+
+// compiledPatterns compiles the patterns, failing on the first invalid one.
+func (r *Redact) compiledPatterns() ([]*regexp.Regexp, error) {
+	patterns := make([]*regexp.Regexp, 0, len(r.Patterns))
+	for _, p := range r.Patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("redact: invalid pattern %q: %w", p, err)
+		}
+		patterns = append(patterns, re)
+	}
+	return patterns, nil
+}
+
+// IsHeader reports whether the value of the header must be redacted.
+func (r *Redact) IsHeader(name string) bool {
+	if containsFold(DefaultRedactedHeaders, name) {
+		return true
+	}
+	return r != nil && containsFold(r.Headers, name)
+}
+
+// Header returns the value of the header, or RedactedValue when it must be
+// redacted. Other values are redacted by the patterns.
+func (r *Redact) Header(name, value string) string {
+	if r.IsHeader(name) {
+		return RedactedValue
+	}
+	return r.Text(value)
+}
+
+// IsPath reports whether the value at the JSON path is, contains or is part
+// of a redacted value.
+func (r *Redact) IsPath(path string) bool {
+	if r == nil {
+		return false
+	}
+	segments := strings.Split(path, ".")
+	for _, p := range r.Paths {
+		if pathsOverlap(strings.Split(p, "."), segments) {
+			return true
+		}
+	}
+	return false
+}
+
+// Value returns v formatted for a message about the JSON path, or
+// RedactedValue when the path is redacted.
+func (r *Redact) Value(path string, v interface{}) string {
+	if r.IsPath(path) {
+		return RedactedValue
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// Text redacts the matches of the patterns in s.
+func (r *Redact) Text(s string) string {
+	if r == nil {
+		return s
+	}
+	for _, re := range r.patterns {
+		s = redactMatches(re, s)
+	}
+	return s
+}
+
+// Body redacts the values at the redacted paths of a JSON body and then the
+// matches of the patterns. A body that is not JSON, or without any redacted
+// path, is only redacted by the patterns. Redacting paths re-encodes the
+// body, sorting the keys of its objects.
+func (r *Redact) Body(body string) string {
+	if r == nil {
+		return body
+	}
+	if len(r.Paths) > 0 {
+		decoder := json.NewDecoder(strings.NewReader(body))
+		decoder.UseNumber()
+		var data interface{}
+		if err := decoder.Decode(&data); err == nil && !decoder.More() {
+			redacted := false
+			for _, p := range r.Paths {
+				if redactPath(data, strings.Split(p, ".")) {
+					redacted = true
+				}
+			}
+			if redacted {
+				var b bytes.Buffer
+				encoder := json.NewEncoder(&b)
+				encoder.SetEscapeHTML(false)
+				if err := encoder.Encode(data); err == nil {
+					body = strings.TrimSuffix(b.String(), "\n")
+				}
+			}
+		}
+	}
+	return r.Text(body)
+}
+
+// redactMatches replaces the matches of re in s, or only their groups when
+// re has any.
+func redactMatches(re *regexp.Regexp, s string) string {
+	if re.NumSubexp() == 0 {
+		return re.ReplaceAllLiteralString(s, RedactedValue)
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range re.FindAllStringSubmatchIndex(s, -1) {
+		for g := 1; g < len(m)/2; g++ {
+			start, end := m[2*g], m[2*g+1]
+			if start < last || start < 0 {
+				continue
+			}
+			b.WriteString(s[last:start])
+			b.WriteString(RedactedValue)
+			last = end
+		}
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// redactPath replaces the values at the path in decoded JSON data and
+// reports whether any was replaced.
+func redactPath(data interface{}, path []string) bool {
+	if len(path) == 0 {
+		return false
+	}
+	last := len(path) == 1
+	redacted := false
+	switch node := data.(type) {
+	case map[string]interface{}:
+		for key, val := range node {
+			if path[0] != "*" && path[0] != key {
+				continue
+			}
+			if last {
+				node[key] = RedactedValue
+				redacted = true
+			} else if redactPath(val, path[1:]) {
+				redacted = true
+			}
+		}
+	case []interface{}:
+		for i, val := range node {
+			if path[0] != "*" && path[0] != strconv.Itoa(i) {
+				continue
+			}
+			if last {
+				node[i] = RedactedValue
+				redacted = true
+			} else if redactPath(val, path[1:]) {
+				redacted = true
+			}
+		}
+	}
+	return redacted
+}
+
+// pathsOverlap reports whether one path is a prefix of the other, with *
+// segments matching any segment.
+func pathsOverlap(a, b []string) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != "*" && b[i] != "*" && a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
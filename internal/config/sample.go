@@ -0,0 +1,66 @@
+package config
+
+import (
+	"fmt"
+	"math"
+)
+
+// Sample returns a copy of the configuration with its load scaled down to a
+// fraction between 0 and 1, for a quick version of a load test: the total
+// requests, users, warm-up requests and in-flight cap of every concurrent
+// endpoint, the users of its load profile stages and autotune search, and the
+// users and total requests of the scenario. Scaled counts are rounded and
+// clamped to at least 1; counts that are not set stay unset. Throughput
+// targets and expectations (targetRps, expect.minRps) are scaled by the same
+// fraction, as fewer users cannot sustain the full throughput. Durations, such
+// as those of the stages, are not scaled. The configuration itself is left
+// unchanged.
+func (c *Config) Sample(fraction float64) (*Config, error) {
+	if fraction <= 0 || fraction > 1 || math.IsNaN(fraction) {
+		return nil, fmt.Errorf("sample must be greater than 0 and at most 1, got %v", fraction)
+	}
+	sampled := *c
+	sampled.Endpoints = append([]Endpoint(nil), c.Endpoints...)
+	for i := range sampled.Endpoints {
+		e := &sampled.Endpoints[i]
+		e.Expect.MinRPS *= fraction
+
+		concurrent := &e.Concurrent
+		concurrent.Total = concurrent.Total.scale(fraction)
+		concurrent.Users = concurrent.Users.scale(fraction)
+		concurrent.MaxInFlight = concurrent.MaxInFlight.scale(fraction)
+		concurrent.TargetRPS *= fraction
+		concurrent.LoadProfile = append([]LoadStage(nil), concurrent.LoadProfile...)
+		for j := range concurrent.LoadProfile {
+			concurrent.LoadProfile[j].Users = concurrent.LoadProfile[j].Users.scale(fraction)
+		}
+		if concurrent.Autotune != nil {
+			autotune := *concurrent.Autotune
+			autotune.Start = autotune.Start.scale(fraction)
+			autotune.Step = autotune.Step.scale(fraction)
+			autotune.Max = autotune.Max.scale(fraction)
+			concurrent.Autotune = &autotune
+		}
+		if concurrent.Benchmark != nil {
+			benchmark := *concurrent.Benchmark
+			benchmark.Warmup = benchmark.Warmup.scale(fraction)
+			concurrent.Benchmark = &benchmark
+		}
+	}
+	if c.Scenario != nil {
+		scenario := *c.Scenario
+		scenario.Total = scenario.Total.scale(fraction)
+		scenario.Users = scenario.Users.scale(fraction)
+		sampled.Scenario = &scenario
+	}
+	return &sampled, nil
+}
+
+// scale returns the count scaled by fraction, rounded and at least 1, or
+// zero when the count is not set.
+func (c Count) scale(fraction float64) Count {
+	if c <= 0 {
+		return c
+	}
+	return Count(max(1, int(math.Round(float64(c)*fraction))))
+}
@@ -0,0 +1,57 @@
+package config
+
+import "testing"
+
+func TestSampleScalesACopy(t *testing.T) {
+	cfg := &Config{
+		Endpoints: []Endpoint{{
+			Name:   "load",
+			Expect: Expectation{MinRPS: 200},
+			Concurrent: ConcurrentConfig{
+				Users:       20,
+				Total:       1000,
+				MaxInFlight: 8,
+				TargetRPS:   400,
+				LoadProfile: []LoadStage{{Users: 5}, {Users: 50}},
+				Autotune:    &Autotune{Start: 10, Step: 5, Max: 100},
+				Benchmark:   &Benchmark{Cycles: 3, Warmup: 40},
+			},
+		}},
+		Scenario: &Scenario{Users: 30, Total: 3},
+	}
+
+	sampled, err := cfg.Sample(0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := sampled.Endpoints[0].Concurrent
+	if c.Users != 2 || c.Total != 100 || c.MaxInFlight != 1 || c.TargetRPS != 40 {
+		t.Errorf("users %d, total %d, maxInFlight %d, targetRps %v, want 2, 100, 1, 40", c.Users, c.Total, c.MaxInFlight, c.TargetRPS)
+	}
+	if c.LoadProfile[0].Users != 1 || c.LoadProfile[1].Users != 5 {
+		t.Errorf("stage users %d and %d, want 1 and 5", c.LoadProfile[0].Users, c.LoadProfile[1].Users)
+	}
+	if a := c.Autotune; a.Start != 1 || a.Step != 1 || a.Max != 10 {
+		t.Errorf("autotune %+v, want start 1, step 1, max 10", *a)
+	}
+	if b := c.Benchmark; b.Cycles != 3 || b.Warmup != 4 {
+		t.Errorf("benchmark %+v, want 3 cycles with 4 warm-up requests", *b)
+	}
+	if minRPS := sampled.Endpoints[0].Expect.MinRPS; minRPS != 20 {
+		t.Errorf("minRps %v, want 20", minRPS)
+	}
+	if s := sampled.Scenario; s.Users != 3 || s.Total != 1 {
+		t.Errorf("scenario users %d, total %d, want 3 and 1", s.Users, s.Total)
+	}
+
+	// the sampled config shares nothing it scales with the original
+	original := cfg.Endpoints[0].Concurrent
+	if original.Users != 20 || original.LoadProfile[1].Users != 50 || original.Autotune.Max != 100 ||
+		original.Benchmark.Warmup != 40 || cfg.Endpoints[0].Expect.MinRPS != 200 || cfg.Scenario.Users != 30 {
+		t.Errorf("the original config was changed: %+v, scenario %+v", original, *cfg.Scenario)
+	}
+
+	if _, err := cfg.Sample(1.5); err == nil {
+		t.Error("expected an error for a fraction above 1")
+	}
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"fmt"
+	"log"
+)
+
+// SaveResponse writes the body of every response of an endpoint to a file,
+// e.g. to inspect or diff large or binary responses. Path may contain {name},
+// replaced with the endpoint name, and {id}, replaced with the request ID.
+// It can be given as the path alone, e.g. `saveResponseTo: out/{name}-{id}.bin`.
+type SaveResponse struct {
+	Path string `yaml:"path"`
+	// AllowConcurrent saves the responses of concurrent endpoints too, which
+	// are skipped by default so a load test does not flood the filesystem
+	AllowConcurrent bool `yaml:"allowConcurrent"`
+}
+
+// UnmarshalYAML accepts either a path or a path/allowConcurrent mapping.
+func (s *SaveResponse) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var path string
+	if err := unmarshal(&path); err == nil {
+		*s = SaveResponse{Path: path}
+		return nil
+	}
+
+	type plainSaveResponse SaveResponse
+	return unmarshal((*plainSaveResponse)(s))
+}
+
+// validateSaveResponse checks that the endpoint saves its responses to a
+// path, and warns when they will not be saved because it is concurrent.
+func validateSaveResponse(e Endpoint, scenario bool) error {
+	if e.SaveResponseTo.Path == "" {
+		return fmt.Errorf("saveResponseTo requires a path")
+	}
+	if !e.SaveResponseTo.AllowConcurrent && (e.Concurrent.IsConcurrent() || scenario) {
+		log.Println("endpoint", e.Name, "runs concurrently, its responses are not saved without saveResponseTo.allowConcurrent")
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Lowest and highest valid HTTP status codes.
+const (
+	minStatus = 100
+	maxStatus = 599
+)
+
+// StatusRange is an inclusive range of status codes, parsed from one term of
+// a status expression.
+type StatusRange struct {
+	Min, Max int
+	term     string
+}
+
+// Status is the set of acceptable response statuses, the union of its ranges.
+// It is written as an exact code (200), a class (4xx), a comparison (>=400,
+// <500), or a list of these, either as a YAML list or comma separated. An
+// empty Status, or 0, is unset.
+type Status []StatusRange
+
+// ExactStatus returns a Status accepting only code.
+func ExactStatus(code int) Status {
+	return Status{{Min: code, Max: code, term: strconv.Itoa(code)}}
+}
+
+// AnyStatus returns a Status accepting every valid status code.
+func AnyStatus() Status {
+	return Status{{Min: minStatus, Max: maxStatus, term: fmt.Sprintf("%d-%d", minStatus, maxStatus)}}
+}
+
+// IsSet reports whether any status is expected.
+func (s Status) IsSet() bool {
+	return len(s) > 0
+}
+
+// Matches reports whether code is one of the acceptable statuses.
+func (s Status) Matches(code int) bool {
+	for _, r := range s {
+		if code >= r.Min && code <= r.Max {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the expression the status was parsed from, e.g. "200" or
+// "4xx, 500".
+func (s Status) String() string {
+	terms := make([]string, len(s))
+	for i, r := range s {
+		terms[i] = r.term
+	}
+	return strings.Join(terms, ", ")
+}
+
+// UnmarshalYAML accepts a code, an expression string or a list of either.
+func (s *Status) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var terms []string
+	var list []interface{}
+	var value interface{}
+	if err := unmarshal(&list); err == nil {
+		for _, item := range list {
+			terms = append(terms, fmt.Sprint(item))
+		}
+	} else if err := unmarshal(&value); err != nil {
+		return err
+	} else {
+		terms = strings.Split(fmt.Sprint(value), ",")
+	}
+
+	status := Status{}
+	for _, term := range terms {
+		term = strings.TrimSpace(term)
+		if term == "0" && len(terms) == 1 {
+			break
+		}
+		r, err := parseStatusTerm(term)
+		if err != nil {
+			return err
+		}
+		status = append(status, r)
+	}
+	*s = status
+	return nil
+}
+
+// parseStatusTerm parses a single code, class or comparison.
+func parseStatusTerm(term string) (StatusRange, error) {
+	invalid := fmt.Errorf("invalid status %q, expected a code (200), a class (4xx) or a comparison (>=400)", term)
+
+	lower := strings.ToLower(term)
+	if len(lower) == 3 && strings.HasSuffix(lower, "xx") {
+		class, err := strconv.Atoi(lower[:1])
+		if err != nil || class < 1 || class > 5 {
+			return StatusRange{}, invalid
+		}
+		return StatusRange{Min: class * 100, Max: class*100 + 99, term: lower}, nil
+	}
+
+	for _, op := range []string{">=", "<=", ">", "<"} {
+		if !strings.HasPrefix(term, op) {
+			continue
+		}
+		code, err := strconv.Atoi(strings.TrimSpace(term[len(op):]))
+		if err != nil {
+			return StatusRange{}, invalid
+		}
+		r := StatusRange{Min: minStatus, Max: maxStatus, term: op + strconv.Itoa(code)}
+		switch op {
+		case ">=":
+			r.Min = code
+		case ">":
+			r.Min = code + 1
+		case "<=":
+			r.Max = code
+		case "<":
+			r.Max = code - 1
+		}
+		if r.Min > r.Max {
+			return StatusRange{}, fmt.Errorf("status %q matches no status code", term)
+		}
+		return r, nil
+	}
+
+	code, err := strconv.Atoi(term)
+	if err != nil || code < minStatus || code > maxStatus {
+		return StatusRange{}, invalid
+	}
+	return StatusRange{Min: code, Max: code, term: term}, nil
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestStatusExpressions(t *testing.T) {
+	tests := []struct {
+		expr     string
+		match    []int
+		mismatch []int
+		str      string
+	}{
+		{expr: "200", match: []int{200}, mismatch: []int{201, 404}, str: "200"},
+		{expr: `">=400"`, match: []int{400, 404, 599}, mismatch: []int{399, 200}, str: ">=400"},
+		{expr: `"<500"`, match: []int{100, 499}, mismatch: []int{500}, str: "<500"},
+		{expr: "4xx", match: []int{400, 499}, mismatch: []int{399, 500}, str: "4xx"},
+		{expr: "2XX, 404", match: []int{204, 404}, mismatch: []int{400, 500}, str: "2xx, 404"},
+		{expr: "[201, 3xx]", match: []int{201, 302}, mismatch: []int{200, 400}, str: "201, 3xx"},
+	}
+	for _, tt := range tests {
+		var v struct {
+			Status Status `yaml:"status"`
+		}
+		if err := yaml.Unmarshal([]byte("status: "+tt.expr), &v); err != nil {
+			t.Errorf("%s: %v", tt.expr, err)
+			continue
+		}
+		for _, code := range tt.match {
+			if !v.Status.Matches(code) {
+				t.Errorf("%s does not match %d", tt.expr, code)
+			}
+		}
+		for _, code := range tt.mismatch {
+			if v.Status.Matches(code) {
+				t.Errorf("%s matches %d", tt.expr, code)
+			}
+		}
+		if got := v.Status.String(); got != tt.str {
+			t.Errorf("%s: String() = %q, want %q", tt.expr, got, tt.str)
+		}
+	}
+}
+
+func TestStatusExpressionErrors(t *testing.T) {
+	for expr, want := range map[string]string{
+		"0":         "",
+		"6xx":       `invalid status "6xx"`,
+		"abc":       `invalid status "abc"`,
+		"700":       `invalid status "700"`,
+		`"<100"`:    `status "<100" matches no status code`,
+		`">=x"`:     `invalid status ">=x"`,
+		"200, what": `invalid status "what"`,
+	} {
+		var v struct {
+			Status Status `yaml:"status"`
+		}
+		err := yaml.Unmarshal([]byte("status: "+expr), &v)
+		if want == "" {
+			if err != nil || v.Status.IsSet() {
+				t.Errorf("%s: status %v, err %v, want unset", expr, v.Status, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: err = %v, want %q", expr, err, want)
+		}
+	}
+}
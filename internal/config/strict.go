@@ -0,0 +1,102 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// unknownField matches the yaml.v2 error for an unknown key in strict mode.
+var unknownField = regexp.MustCompile(`^(line \d+): field (\S+) not found in type (\S+)$`)
+
+// explainUnknownFields rewrites the unknown key errors of strict unmarshaling
+// into messages naming the offending key and, when there is a close match,
+// the key that was probably meant.
+func explainUnknownFields(err error) error {
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		return err
+	}
+
+	known := knownKeys(reflect.TypeOf(Config{}), map[string][]string{})
+	msgs := make([]string, 0, len(typeErr.Errors))
+	for _, msg := range typeErr.Errors {
+		m := unknownField.FindStringSubmatch(msg)
+		if m == nil {
+			msgs = append(msgs, msg)
+			continue
+		}
+		explained := fmt.Sprintf("%s: unknown key %q", m[1], m[2])
+		if suggestion := closestKey(m[2], known[m[3]]); suggestion != "" {
+			explained += fmt.Sprintf(", did you mean %q?", suggestion)
+		}
+		msgs = append(msgs, explained)
+	}
+	return fmt.Errorf("invalid config keys:\n  %s", strings.Join(msgs, "\n  "))
+}
+
+// knownKeys collects the YAML keys of t and of every struct type reachable
+// from it, indexed by the type name used in yaml.v2 errors (e.g. config.Endpoint).
+// Types with an UnmarshalYAML method decode their mapping through a local
+// alias named plain<Type>, e.g. config.plainBodyCandidate, whose errors find
+// the keys under that name too.
+func knownKeys(t reflect.Type, keys map[string][]string) map[string][]string {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || keys[t.String()] != nil {
+		return keys
+	}
+
+	names := []string{}
+	keys[t.String()] = names
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		names = append(names, name)
+		knownKeys(field.Type, keys)
+	}
+	keys[t.String()] = names
+	keys[path.Base(t.PkgPath())+".plain"+t.Name()] = names
+	return keys
+}
+
+// closestKey returns the known key closest to key, if it is within a few edits.
+func closestKey(key string, known []string) string {
+	best, bestDistance := "", 3
+	for _, candidate := range known {
+		if d := editDistance(strings.ToLower(key), strings.ToLower(candidate)); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr := make([]int, len(b)+1)
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev = curr
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStrictRejectsMisspelledKeys(t *testing.T) {
+	path := writeConfig(t, `
+endpoints:
+  - name: users
+    url: https://api.example.com/users
+    method: GET
+    conncurrent:
+      users: 2
+      total: 10
+    expect:
+      status: 200
+      bodyOneOf:
+        - nmae: empty
+          value: []
+`)
+	if _, err := LoadConfig(path); err != nil {
+		t.Fatalf("unknown keys are ignored without --strict: %v", err)
+	}
+
+	_, err := LoadConfigWithOptions(path, LoadOptions{Strict: true})
+	if err == nil {
+		t.Fatal("strict loading accepted the misspelled keys")
+	}
+	for _, want := range []string{
+		`line 6: unknown key "conncurrent", did you mean "concurrent"?`,
+		`line 12: unknown key "nmae", did you mean "name"?`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err, want)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// AllowedTargets restricts the URLs requests are sent to, once their
+// placeholders are interpolated and at every redirect, so a captured or
+// generated value or a redirect cannot send a data-driven run to another
+// host. Empty Schemes or Hosts allow any scheme or host.
+type AllowedTargets struct {
+	// Schemes are the allowed URL schemes, e.g. https
+	Schemes []string `yaml:"schemes"`
+	// Hosts are the allowed hosts. A host with a port (localhost:8080) only
+	// allows that port, and a leading wildcard (*.example.com) allows any
+	// subdomain.
+	Hosts []string `yaml:"hosts"`
+}
+
+// ErrTargetNotAllowed is wrapped by the errors of Check, which are not worth
+// retrying as the URL stays outside the allowed targets.
+var ErrTargetNotAllowed = errors.New("not in allowedTargets")
+
+// Check returns an error when rawURL does not match the allowed schemes and hosts.
+func (a AllowedTargets) Check(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("URL %s cannot be checked against allowedTargets: %w", rawURL, err)
+	}
+	if len(a.Schemes) > 0 && !containsFold(a.Schemes, u.Scheme) {
+		return fmt.Errorf("URL %s: scheme %q is %w", rawURL, u.Scheme, ErrTargetNotAllowed)
+	}
+	if len(a.Hosts) > 0 && !a.allowsHost(u) {
+		return fmt.Errorf("URL %s: host %q is %w", rawURL, u.Host, ErrTargetNotAllowed)
+	}
+	return nil
+}
+
+// allowsHost reports whether the host of u matches one of the allowed hosts.
+func (a AllowedTargets) allowsHost(u *url.URL) bool {
+	for _, pattern := range a.Hosts {
+		host := u.Hostname()
+		if strings.Contains(pattern, ":") {
+			host = u.Host
+		}
+		host = strings.ToLower(host)
+		pattern = strings.ToLower(pattern)
+
+		if suffix, ok := strings.CutPrefix(pattern, "*"); ok {
+			if strings.HasSuffix(host, suffix) && len(host) > len(suffix) {
+				return true
+			}
+		} else if host == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// validate checks that the schemes are supported and that wildcards only
+// prefix a domain.
+func (a AllowedTargets) validate() error {
+	if len(a.Schemes) == 0 && len(a.Hosts) == 0 {
+		return fmt.Errorf("allowedTargets: schemes or hosts are required")
+	}
+	for _, scheme := range a.Schemes {
+		if !containsFold(SupportedSchemes, scheme) {
+			return fmt.Errorf("allowedTargets: unsupported scheme %s, expected one of %s", scheme, strings.Join(SupportedSchemes, ", "))
+		}
+	}
+	for _, host := range a.Hosts {
+		if host == "" || strings.Contains(host, "/") {
+			return fmt.Errorf("allowedTargets: invalid host %q", host)
+		}
+		if i := strings.Index(host, "*"); i >= 0 && (i != 0 || !strings.HasPrefix(host, "*.") || strings.Count(host, "*") > 1) {
+			return fmt.Errorf("allowedTargets: invalid host %q, a wildcard is only allowed as *.domain", host)
+		}
+	}
+	return nil
+}
+
+// containsFold reports whether values contains s, ignoring case.
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
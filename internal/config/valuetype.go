@@ -0,0 +1,99 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// Value types of value checks. An expected value with a type is converted to
+// it before being compared, whatever its YAML type: with ValueTypeString the
+// YAML number 200 expects the JSON string "200", and with ValueTypeNumber the
+// YAML string "200" expects the JSON number 200. ValueTypeAny compares scalars
+// by their text, so 200 and "200" match both.
+const (
+	ValueTypeString  = "string"
+	ValueTypeNumber  = "number"
+	ValueTypeBoolean = "boolean"
+	ValueTypeAny     = "any"
+)
+
+// CoerceValue converts a scalar expected value to the JSON type named by
+// valueType: a string, a float64 number or a boolean (true or false). Values
+// without a type, or with ValueTypeAny, are returned as they are.
+func CoerceValue(value interface{}, valueType string) (interface{}, error) {
+	if valueType == "" || valueType == ValueTypeAny {
+		return value, nil
+	}
+	switch value.(type) {
+	case nil, map[interface{}]interface{}, map[string]interface{}, []interface{}:
+		return nil, fmt.Errorf("valueType %s requires a scalar value", valueType)
+	}
+
+	text := fmt.Sprintf("%v", value)
+	switch valueType {
+	case ValueTypeString:
+		return text, nil
+	case ValueTypeNumber:
+		number, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+		if err != nil {
+			return nil, fmt.Errorf("value %q is not a number", text)
+		}
+		return number, nil
+	case ValueTypeBoolean:
+		switch strings.TrimSpace(text) {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+		return nil, fmt.Errorf("value %q is not a boolean", text)
+	}
+	return nil, fmt.Errorf("unknown valueType %s", valueType)
+}
+
+// validateValueType checks the value type of a value check and, unless the
+// value references a captured variable, resolved when the request is sent,
+// that the value converts to it.
+func validateValueType(check ValueCheck) error {
+	switch check.ValueType {
+	case "":
+		return nil
+	case ValueTypeString, ValueTypeNumber, ValueTypeBoolean, ValueTypeAny:
+	default:
+		return fmt.Errorf("value check %s: unknown valueType %s, expected %s, %s, %s or %s", check.Path,
+			check.ValueType, ValueTypeString, ValueTypeNumber, ValueTypeBoolean, ValueTypeAny)
+	}
+	if check.Op != "" && check.Op != ValueOpEquals {
+		return fmt.Errorf("value check %s: valueType requires op %s", check.Path, ValueOpEquals)
+	}
+	if text, ok := check.Value.(string); ok && strings.Contains(text, "{{") {
+		return nil
+	}
+	if _, err := CoerceValue(check.Value, check.ValueType); err != nil {
+		return fmt.Errorf("value check %s: %w", check.Path, err)
+	}
+	return nil
+}
+
+// warnQuotedValues warns about value checks expecting a string that reads as
+// a number or a boolean, e.g. value: "200", as values are compared keeping
+// their YAML types and such a string does not match the JSON number 200.
+// Checks setting a valueType state the type they expect and are not reported.
+func (e Expectation) warnQuotedValues(endpoint string) {
+	for _, check := range e.Values {
+		text, ok := check.Value.(string)
+		if !ok || check.ValueType != "" || (check.Op != "" && check.Op != ValueOpEquals && check.Op != ValueOpJSONEquals) {
+			continue
+		}
+		if _, err := strconv.ParseFloat(text, 64); err != nil && text != "true" && text != "false" {
+			continue
+		}
+		log.Printf("endpoint %s: expect.values %s expects the string %q, which does not match the JSON number or boolean %s; unquote it, or set valueType: string to expect a string",
+			endpoint, check.Path, text, text)
+	}
+	for status, block := range e.ByStatus {
+		block.warnQuotedValues(fmt.Sprintf("%s byStatus %d", endpoint, status))
+	}
+}
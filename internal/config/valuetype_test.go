@@ -0,0 +1,67 @@
+package config
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+)
+
+// captureLog returns what f logs with the standard logger.
+func captureLog(t *testing.T, f func()) string {
+	t.Helper()
+	var buf bytes.Buffer
+	previous := log.Writer()
+	log.SetOutput(&buf)
+	defer log.SetOutput(previous)
+	f()
+	return buf.String()
+}
+
+func TestValidateWarnsAboutQuotedValues(t *testing.T) {
+	path := writeConfig(t, `
+endpoints:
+  - name: users
+    url: https://api.example.com/users
+    method: GET
+    expect:
+      status: 200
+      values:
+        - path: code
+          value: "200"
+        - path: active
+          value: "true"
+        - path: zip
+          value: "02134"
+          valueType: string
+        - path: count
+          value: 3
+        - path: name
+          value: Ada
+      byStatus:
+        404:
+          values:
+            - path: found
+              value: "false"
+`)
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var validateErr error
+	logged := captureLog(t, func() { validateErr = cfg.Validate() })
+	if validateErr != nil {
+		t.Fatal(validateErr)
+	}
+
+	for _, want := range []string{`values code expects the string "200"`, `values active expects the string "true"`, `users byStatus 404: expect.values found`} {
+		if !strings.Contains(logged, want) {
+			t.Errorf("log does not warn with %q:\n%s", want, logged)
+		}
+	}
+	for _, unwanted := range []string{"values zip", "values count", "values name"} {
+		if strings.Contains(logged, unwanted) {
+			t.Errorf("log warns about %q:\n%s", unwanted, logged)
+		}
+	}
+}
@@ -0,0 +1,310 @@
+// Package curl converts cURL commands, e.g. copied from the browser devtools,
+// into endpoint configurations.
+package curl
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/JakubPluta/tmago/internal/config"
+)
+
+// ignoredFlags are cURL options without an argument that do not change the
+// request itself, such as output and TLS settings.
+var ignoredFlags = map[string]bool{
+	"--compressed": true,
+	"-k":           true,
+	"--insecure":   true,
+	"-L":           true,
+	"--location":   true,
+	"-s":           true,
+	"--silent":     true,
+	"-S":           true,
+	"--show-error": true,
+	"-v":           true,
+	"--verbose":    true,
+	"-i":           true,
+	"--include":    true,
+	"-g":           true,
+	"--globoff":    true,
+}
+
+// Parse parses a cURL command into an endpoint with its method, URL, headers
+// and body. The supported options are -X/--request, -H/--header,
+// -d/--data/--data-raw/--data-binary/--data-ascii, --json, --url,
+// -u/--user, -b/--cookie, -A/--user-agent and -e/--referer; options that do
+// not change the request, such as --compressed, are ignored and others are
+// rejected. Like cURL, a request with data defaults to POST with a form
+// Content-Type. The endpoint is named after its method and path.
+func Parse(command string) (config.Endpoint, error) {
+	args, err := split(command)
+	if err != nil {
+		return config.Endpoint{}, err
+	}
+	if len(args) == 0 || args[0] != "curl" {
+		return config.Endpoint{}, fmt.Errorf("not a curl command")
+	}
+
+	endpoint := config.Endpoint{Headers: make(map[string]string)}
+	var data []string
+	isJSON := false
+
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		if ignoredFlags[arg] || ignoredShortFlags(arg) {
+			continue
+		}
+		if !strings.HasPrefix(arg, "-") {
+			if endpoint.URL != "" {
+				return config.Endpoint{}, fmt.Errorf("unexpected argument %q, only one URL is supported", arg)
+			}
+			endpoint.URL = arg
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(arg, "=")
+		if !hasValue || !strings.HasPrefix(name, "--") {
+			name = arg
+			// short options may be followed by their value, e.g. -XPOST
+			if len(arg) > 2 && !strings.HasPrefix(arg, "--") {
+				name, value, hasValue = arg[:2], arg[2:], true
+			}
+		}
+		if !hasValue {
+			i++
+			if i == len(args) {
+				return config.Endpoint{}, fmt.Errorf("option %s requires a value", name)
+			}
+			value = args[i]
+		}
+
+		switch name {
+		case "-X", "--request":
+			endpoint.Method = strings.ToUpper(value)
+		case "-H", "--header":
+			key, headerValue, ok := strings.Cut(value, ":")
+			if !ok {
+				return config.Endpoint{}, fmt.Errorf("invalid header %q", value)
+			}
+			endpoint.Headers[strings.TrimSpace(key)] = strings.TrimSpace(headerValue)
+		case "-d", "--data", "--data-raw", "--data-binary", "--data-ascii":
+			data = append(data, value)
+		case "--json":
+			data = append(data, value)
+			isJSON = true
+		case "--url":
+			endpoint.URL = value
+		case "-u", "--user":
+			endpoint.Headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(value))
+		case "-b", "--cookie":
+			endpoint.Headers["Cookie"] = value
+		case "-A", "--user-agent":
+			endpoint.Headers["User-Agent"] = value
+		case "-e", "--referer":
+			endpoint.Headers["Referer"] = value
+		default:
+			return config.Endpoint{}, fmt.Errorf("unsupported curl option %s", name)
+		}
+	}
+
+	if endpoint.URL == "" {
+		return config.Endpoint{}, fmt.Errorf("no URL in curl command")
+	}
+	if !strings.Contains(endpoint.URL, "://") {
+		// curl defaults to http for URLs without a scheme
+		endpoint.URL = "http://" + endpoint.URL
+	}
+
+	if len(data) > 0 {
+		endpoint.Body = strings.Join(data, "&")
+		if endpoint.Method == "" {
+			endpoint.Method = "POST"
+		}
+		if isJSON {
+			setDefaultHeader(endpoint.Headers, "Content-Type", "application/json")
+			setDefaultHeader(endpoint.Headers, "Accept", "application/json")
+		} else {
+			setDefaultHeader(endpoint.Headers, "Content-Type", "application/x-www-form-urlencoded")
+		}
+	}
+	if endpoint.Method == "" {
+		endpoint.Method = "GET"
+	}
+	if len(endpoint.Headers) == 0 {
+		endpoint.Headers = nil
+	}
+
+	endpoint.Name = EndpointName(endpoint.Method, endpoint.URL)
+	return endpoint, nil
+}
+
+// ignoredShortFlags reports whether arg combines ignored short options, e.g. -sSL.
+func ignoredShortFlags(arg string) bool {
+	if len(arg) < 3 || arg[0] != '-' || arg[1] == '-' {
+		return false
+	}
+	for _, c := range arg[1:] {
+		if !ignoredFlags["-"+string(c)] {
+			return false
+		}
+	}
+	return true
+}
+
+// setDefaultHeader sets a header unless it is already set, in any case.
+func setDefaultHeader(headers map[string]string, key, value string) {
+	for k := range headers {
+		if strings.EqualFold(k, key) {
+			return
+		}
+	}
+	headers[key] = value
+}
+
+// EndpointName returns the default name of an endpoint, its method and the
+// path of its URL, e.g. "GET /users".
+func EndpointName(method, rawURL string) string {
+	return method + " " + endpointPath(rawURL)
+}
+
+// endpointPath returns the path of a URL, or the URL itself when it cannot be parsed.
+func endpointPath(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	if u.Path == "" {
+		return "/"
+	}
+	return u.Path
+}
+
+// split splits a command line into arguments like a POSIX shell, handling
+// single quotes, double quotes, ANSI-C quotes ($'...'), backslash escapes and
+// line continuations.
+func split(command string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+
+	for i := 0; i < len(command); i++ {
+		c := command[i]
+		switch {
+		case c == '\\':
+			if i+1 == len(command) {
+				return nil, fmt.Errorf("unterminated escape at end of command")
+			}
+			i++
+			if command[i] == '\n' {
+				continue
+			}
+			if command[i] == '\r' && i+1 < len(command) && command[i+1] == '\n' {
+				i++
+				continue
+			}
+			current.WriteByte(command[i])
+			inArg = true
+		case c == '\'':
+			end := strings.IndexByte(command[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated single quote")
+			}
+			current.WriteString(command[i+1 : i+1+end])
+			i += end + 1
+			inArg = true
+		case c == '$' && i+1 < len(command) && command[i+1] == '\'':
+			n, err := ansiQuoted(command[i+2:], &current)
+			if err != nil {
+				return nil, err
+			}
+			i += n + 1
+			inArg = true
+		case c == '"':
+			n, err := doubleQuoted(command[i+1:], &current)
+			if err != nil {
+				return nil, err
+			}
+			i += n
+			inArg = true
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteByte(c)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+// doubleQuoted writes the content of a double-quoted string starting after
+// the opening quote to b, and returns the number of bytes consumed, including
+// the closing quote. Backslashes only escape $, `, ", \ and newlines.
+func doubleQuoted(s string, b *strings.Builder) (int, error) {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			return i + 1, nil
+		case '\\':
+			if i+1 < len(s) && strings.IndexByte("$`\"\\\n", s[i+1]) >= 0 {
+				i++
+				if s[i] != '\n' {
+					b.WriteByte(s[i])
+				}
+				continue
+			}
+			b.WriteByte('\\')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return 0, fmt.Errorf("unterminated double quote")
+}
+
+// ansiEscapes are the single character escapes of ANSI-C quoted strings.
+var ansiEscapes = map[byte]byte{
+	'n': '\n', 't': '\t', 'r': '\r', '\\': '\\', '\'': '\'', '"': '"', 'a': '\a', 'b': '\b', 'f': '\f', 'v': '\v', 'e': 0x1b,
+}
+
+// ansiQuoted writes the content of an ANSI-C quoted string ($'...') starting
+// after the opening quote to b, and returns the number of bytes consumed,
+// including the closing quote. Browsers use it for bodies with special
+// characters. Hex escapes (\xHH) are supported in addition to ansiEscapes.
+func ansiQuoted(s string, b *strings.Builder) (int, error) {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\'':
+			return i + 1, nil
+		case '\\':
+			if i+1 == len(s) {
+				break
+			}
+			i++
+			if e, ok := ansiEscapes[s[i]]; ok {
+				b.WriteByte(e)
+				continue
+			}
+			if s[i] == 'x' && i+2 < len(s) {
+				var v byte
+				if _, err := fmt.Sscanf(s[i+1:i+3], "%02x", &v); err == nil {
+					b.WriteByte(v)
+					i += 2
+					continue
+				}
+			}
+			b.WriteByte('\\')
+			b.WriteByte(s[i])
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return 0, fmt.Errorf("unterminated $' quote")
+}
@@ -0,0 +1,145 @@
+package curl
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/JakubPluta/tmago/internal/config"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		want    config.Endpoint
+	}{
+		{
+			name: "devtools copy",
+			command: `curl 'https://api.example.com/v1/orders?page=2' \
+  -H 'accept: application/json' \
+  -H 'authorization: Bearer abc' \
+  --data-raw $'{"note":"it\'s \\"new\\""}' \
+  --compressed`,
+			want: config.Endpoint{
+				Name:   "POST /v1/orders",
+				URL:    "https://api.example.com/v1/orders?page=2",
+				Method: "POST",
+				Headers: map[string]string{
+					"accept":        "application/json",
+					"authorization": "Bearer abc",
+					"Content-Type":  "application/x-www-form-urlencoded",
+				},
+				Body: `{"note":"it's \"new\""}`,
+			},
+		},
+		{
+			name:    "method, form data and combined flags",
+			command: `curl -sSL -XPUT example.com/users/1 -d name=ann -d "age=42" -u ann:secret`,
+			want: config.Endpoint{
+				Name:   "PUT /users/1",
+				URL:    "http://example.com/users/1",
+				Method: "PUT",
+				Headers: map[string]string{
+					"Authorization": "Basic YW5uOnNlY3JldA==",
+					"Content-Type":  "application/x-www-form-urlencoded",
+				},
+				Body: "name=ann&age=42",
+			},
+		},
+		{
+			name:    "json",
+			command: `curl --url https://example.com --json '{"a":1}' -H 'Content-Type: application/vnd.api+json'`,
+			want: config.Endpoint{
+				Name:   "POST /",
+				URL:    "https://example.com",
+				Method: "POST",
+				Headers: map[string]string{
+					"Content-Type": "application/vnd.api+json",
+					"Accept":       "application/json",
+				},
+				Body: `{"a":1}`,
+			},
+		},
+		{
+			name:    "get",
+			command: `curl https://example.com/health`,
+			want:    config.Endpoint{Name: "GET /health", URL: "https://example.com/health", Method: "GET"},
+		},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.command)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s:\n got %+v\nwant %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for command, want := range map[string]string{
+		`wget https://example.com`:         "not a curl command",
+		`curl -X POST`:                     "no URL in curl command",
+		`curl https://a.com https://b.com`: `unexpected argument "https://b.com"`,
+		`curl https://a.com -H`:            "option -H requires a value",
+		`curl https://a.com -H 'no colon'`: `invalid header "no colon"`,
+		`curl https://a.com -o out.json`:   "unsupported curl option -o",
+		`curl 'https://a.com`:              "unterminated single quote",
+	} {
+		if _, err := Parse(command); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: err = %v, want %q", command, err, want)
+		}
+	}
+}
+
+func TestAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	original := `# smoke tests
+endpoints:
+    - name: health # keep me
+      url: https://example.com/health
+      method: GET
+`
+	if err := os.WriteFile(path, []byte(original), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	endpoint, err := Parse(`curl -X DELETE https://example.com/users/1 -H 'X-Trace: 1'`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Append(path, endpoint); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), original) {
+		t.Errorf("existing content changed:\n%s", data)
+	}
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Endpoints) != 2 {
+		t.Fatalf("endpoints = %d, want 2:\n%s", len(cfg.Endpoints), data)
+	}
+	added := cfg.Endpoints[1]
+	if added.Name != "DELETE /users/1" || added.Method != "DELETE" || added.Headers["X-Trace"] != "1" || !added.Expect.Status.Matches(200) {
+		t.Errorf("appended endpoint = %+v", added)
+	}
+
+	if err := os.WriteFile(path, []byte("endpoints:\n  - name: a\nscenario:\n  users: 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Append(path, endpoint); err == nil || !strings.Contains(err.Error(), "endpoints must be the last top-level key") {
+		t.Errorf("err = %v, want endpoints to be required last", err)
+	}
+}
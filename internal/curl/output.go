@@ -0,0 +1,85 @@
+package curl
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/JakubPluta/tmago/internal/config"
+	"gopkg.in/yaml.v2"
+)
+
+// Marshal returns the endpoint as an item of the YAML endpoints list, with
+// its name, URL, method, headers and body and its expected status when it is
+// a single code, or 200 to start from.
+func Marshal(endpoint config.Endpoint) ([]byte, error) {
+	item := yaml.MapSlice{
+		{Key: "name", Value: endpoint.Name},
+		{Key: "url", Value: endpoint.URL},
+		{Key: "method", Value: endpoint.Method},
+	}
+	if len(endpoint.Headers) > 0 {
+		item = append(item, yaml.MapItem{Key: "headers", Value: endpoint.Headers})
+	}
+	if endpoint.Body != "" {
+		item = append(item, yaml.MapItem{Key: "body", Value: endpoint.Body})
+	}
+	status := config.DefaultStatus
+	if s := endpoint.Expect.Status; len(s) == 1 && s[0].Min == s[0].Max {
+		status = s[0].Min
+	}
+	item = append(item, yaml.MapItem{Key: "expect", Value: yaml.MapSlice{{Key: "status", Value: status}}})
+
+	return yaml.Marshal([]yaml.MapSlice{item})
+}
+
+// Append adds the endpoint to the endpoints list of the config file at path,
+// keeping the rest of the file, including comments, unchanged. The endpoints
+// list must be the last top-level key of the file.
+func Append(path string, endpoint config.Endpoint) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	item, err := Marshal(endpoint)
+	if err != nil {
+		return err
+	}
+
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	start := -1
+	for i, line := range lines {
+		if line == "" || line[0] == ' ' || line[0] == '\t' || line[0] == '#' || line[0] == '-' {
+			continue
+		}
+		if strings.HasPrefix(line, "endpoints:") {
+			start = i
+		} else if start >= 0 {
+			return fmt.Errorf("%s: endpoints must be the last top-level key to append to it", path)
+		}
+	}
+	if start < 0 {
+		return fmt.Errorf("%s: no endpoints list", path)
+	}
+	if rest := strings.TrimSpace(strings.TrimPrefix(lines[start], "endpoints:")); rest != "" && !strings.HasPrefix(rest, "#") {
+		return fmt.Errorf("%s: endpoints must be a block list to append to it", path)
+	}
+
+	// indent the new item like the existing ones
+	indent := "  "
+	for _, line := range lines[start+1:] {
+		trimmed := strings.TrimLeft(line, " ")
+		if strings.HasPrefix(trimmed, "- ") || trimmed == "-" {
+			indent = line[:len(line)-len(trimmed)]
+			break
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.Join(lines, "\n"))
+	b.WriteString("\n")
+	for _, line := range strings.Split(strings.TrimRight(string(item), "\n"), "\n") {
+		b.WriteString(indent + line + "\n")
+	}
+	return os.WriteFile(path, []byte(b.String()), 0644)
+}
@@ -0,0 +1,159 @@
+// Package doctor diagnoses common problems with the environment and the
+// configuration before a run.
+package doctor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/JakubPluta/tmago/internal/config"
+)
+
+// Check is the outcome of a single diagnostic. Skipped checks could not be
+// performed, e.g. because the config did not load.
+type Check struct {
+	Name    string
+	Err     error
+	Skipped string
+}
+
+// Passed reports whether the check ran and succeeded.
+func (c Check) Passed() bool {
+	return c.Err == nil && c.Skipped == ""
+}
+
+// Options configures the diagnostics.
+type Options struct {
+	// Strict rejects unknown keys in the config file.
+	Strict bool
+	// Dirs are the directories the run writes to, checked for write access.
+	Dirs []string
+	// DNSTimeout limits every DNS lookup, 5s when zero.
+	DNSTimeout time.Duration
+}
+
+// Run checks that the config at path loads and validates, that the files it
+// references exist, that the host of every endpoint resolves, that preScript
+// commands can be found and that the output directories are writable.
+func Run(ctx context.Context, path string, opts Options) []Check {
+	if opts.DNSTimeout == 0 {
+		opts.DNSTimeout = 5 * time.Second
+	}
+
+	checks := make([]Check, 0)
+	// the referenced files are checked on their own, by checkFiles
+	cfg, err := config.LoadConfigWithOptions(path, config.LoadOptions{Strict: opts.Strict, SkipFiles: true})
+	checks = append(checks, Check{Name: fmt.Sprintf("config %s parses", path), Err: err})
+	if err == nil {
+		checks = append(checks, Check{Name: "config is valid", Err: cfg.Validate()})
+		checks = append(checks, checkFiles(cfg)...)
+		checks = append(checks, checkHosts(ctx, cfg, opts.DNSTimeout)...)
+		checks = append(checks, checkScripts(cfg)...)
+	} else {
+		checks = append(checks, Check{Name: "endpoints", Skipped: "config did not load"})
+	}
+
+	for _, dir := range opts.Dirs {
+		checks = append(checks, Check{Name: fmt.Sprintf("directory %s is writable", dir), Err: checkWritable(dir)})
+	}
+	return checks
+}
+
+// placeholder matches a template placeholder in a URL, e.g. {{captured.host}}.
+var placeholder = regexp.MustCompile(`\{\{[^}]*\}\}`)
+
+// templatedHost replaces placeholders so templated URLs can be parsed.
+const templatedHost = "tmago-placeholder"
+
+// checkHosts resolves the host of every endpoint URL once.
+func checkHosts(ctx context.Context, cfg *config.Config, timeout time.Duration) []Check {
+	checks := make([]Check, 0)
+	seen := make(map[string]bool)
+	for _, e := range cfg.Endpoints {
+		name := fmt.Sprintf("endpoint %s: host resolves", e.Name)
+		u, err := url.Parse(placeholder.ReplaceAllString(e.URL, templatedHost))
+		if err != nil {
+			checks = append(checks, Check{Name: name, Err: fmt.Errorf("invalid URL: %w", err)})
+			continue
+		}
+		host := u.Hostname()
+		switch {
+		case host == "":
+			checks = append(checks, Check{Name: name, Err: fmt.Errorf("URL %s has no host", e.URL)})
+			continue
+		case strings.Contains(host, templatedHost):
+			checks = append(checks, Check{Name: name, Skipped: "host is templated"})
+			continue
+		case seen[host]:
+			continue
+		}
+		seen[host] = true
+
+		lookupCtx, cancel := context.WithTimeout(ctx, timeout)
+		_, err = net.DefaultResolver.LookupHost(lookupCtx, host)
+		cancel()
+		checks = append(checks, Check{Name: fmt.Sprintf("endpoint %s: host %s resolves", e.Name, host), Err: err})
+	}
+	return checks
+}
+
+// checkFiles checks that every file referenced by the config can be read.
+func checkFiles(cfg *config.Config) []Check {
+	refs := cfg.ReferencedFiles()
+	checks := make([]Check, 0, len(refs))
+	for _, ref := range refs {
+		name := fmt.Sprintf("endpoint %s: %s %s exists", ref.Endpoint, ref.Use, ref.Path)
+		f, err := os.Open(ref.Path)
+		if errors.Is(err, fs.ErrNotExist) {
+			err = fmt.Errorf("missing file %s", ref.Path)
+		}
+		if f != nil {
+			f.Close()
+		}
+		checks = append(checks, Check{Name: name, Err: err})
+	}
+	return checks
+}
+
+// checkScripts checks that the program of every preScript can be found.
+func checkScripts(cfg *config.Config) []Check {
+	scripts := append([]config.ScriptConfig{}, cfg.PreScripts...)
+	for _, e := range cfg.Endpoints {
+		if e.PreScript != nil {
+			scripts = append(scripts, *e.PreScript)
+		}
+	}
+
+	checks := make([]Check, 0, len(scripts))
+	for _, s := range scripts {
+		fields := strings.Fields(s.Command)
+		if len(fields) == 0 {
+			continue
+		}
+		_, err := exec.LookPath(fields[0])
+		checks = append(checks, Check{Name: fmt.Sprintf("preScript %s: command %s found", s.Var, fields[0]), Err: err})
+	}
+	return checks
+}
+
+// checkWritable creates dir if needed and writes a temporary file to it.
+func checkWritable(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".tmago-doctor-*")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
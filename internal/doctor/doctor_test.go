@@ -0,0 +1,49 @@
+package doctor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunReportsMissingFiles(t *testing.T) {
+	dir := t.TempDir()
+	present := filepath.Join(dir, "ok.json")
+	if err := os.WriteFile(present, []byte(`{"ok": true}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(dir, "missing.json")
+	path := filepath.Join(dir, "config.yaml")
+	config := `
+endpoints:
+  - name: users
+    url: http://127.0.0.1/users
+    method: GET
+    expect:
+      bodyOneOf:
+        - file: ` + present + `
+        - file: ` + missing + `
+`
+	if err := os.WriteFile(path, []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	checks := Run(context.Background(), path, Options{})
+	byName := make(map[string]Check, len(checks))
+	for _, c := range checks {
+		byName[c.Name] = c
+	}
+
+	if c := byName["config "+path+" parses"]; !c.Passed() {
+		t.Errorf("config check failed: %v", c.Err)
+	}
+	if c := byName["endpoint users: bodyOneOf candidate 1 "+present+" exists"]; !c.Passed() {
+		t.Errorf("present file check = %+v, want passed", c)
+	}
+	c, ok := byName["endpoint users: bodyOneOf candidate 2 "+missing+" exists"]
+	if !ok || c.Err == nil || !strings.Contains(c.Err.Error(), "missing file") {
+		t.Errorf("missing file check = %+v, want a missing file error among %+v", c, checks)
+	}
+}
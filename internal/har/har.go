@@ -0,0 +1,149 @@
+// Package har reads recorded traffic from HAR (HTTP Archive) files, e.g.
+// exported from the browser devtools or a proxy, to replay it.
+package har
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/JakubPluta/tmago/internal/config"
+	"github.com/JakubPluta/tmago/internal/curl"
+)
+
+// Request is a recorded request to replay.
+type Request struct {
+	// Offset is the time the request was started after the first request of
+	// the recording
+	Offset time.Duration
+	// Endpoint sends the request, named after its method and path like
+	// imported cURL commands, and expects the recorded status
+	Endpoint config.Endpoint
+}
+
+// skippedHeaders are request headers set by the HTTP client itself, or that
+// would change how the response is read, along with HTTP/2 pseudo-headers.
+var skippedHeaders = map[string]bool{
+	"host":              true,
+	"content-length":    true,
+	"connection":        true,
+	"accept-encoding":   true,
+	"transfer-encoding": true,
+}
+
+// archive is the part of a HAR file read by Load.
+type archive struct {
+	Log struct {
+		Entries []entry `json:"entries"`
+	} `json:"log"`
+}
+
+// entry is a recorded request with its response.
+type entry struct {
+	StartedDateTime time.Time `json:"startedDateTime"`
+	Request         struct {
+		Method   string      `json:"method"`
+		URL      string      `json:"url"`
+		Headers  []nameValue `json:"headers"`
+		PostData *struct {
+			MimeType string `json:"mimeType"`
+			Text     string `json:"text"`
+		} `json:"postData"`
+	} `json:"request"`
+	Response struct {
+		Status int `json:"status"`
+	} `json:"response"`
+}
+
+// nameValue is a header of a recorded request.
+type nameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Load reads the requests of the HAR file at path, ordered by their start
+// time. Entries with a URL other than http or https, e.g. data: URLs or
+// WebSockets, are skipped and counted in skipped.
+func Load(path string) (requests []Request, skipped int, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	return Parse(data)
+}
+
+// Parse reads the requests of a HAR archive like Load.
+func Parse(data []byte) (requests []Request, skipped int, err error) {
+	var har archive
+	if err := json.Unmarshal(data, &har); err != nil {
+		return nil, 0, fmt.Errorf("invalid HAR file: %w", err)
+	}
+
+	entries := make([]entry, 0, len(har.Log.Entries))
+	for _, e := range har.Log.Entries {
+		u := strings.ToLower(e.Request.URL)
+		if e.Request.Method == "" || !strings.HasPrefix(u, "http://") && !strings.HasPrefix(u, "https://") {
+			skipped++
+			continue
+		}
+		entries = append(entries, e)
+	}
+	if len(entries) == 0 {
+		return nil, skipped, fmt.Errorf("no http or https requests in the HAR file")
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].StartedDateTime.Before(entries[j].StartedDateTime)
+	})
+
+	start := entries[0].StartedDateTime
+	requests = make([]Request, len(entries))
+	for i, e := range entries {
+		requests[i] = Request{
+			Offset:   e.StartedDateTime.Sub(start),
+			Endpoint: endpoint(e),
+		}
+	}
+	return requests, skipped, nil
+}
+
+// endpoint converts a HAR entry into an endpoint expecting its recorded
+// status, or any status when the request got no response.
+func endpoint(e entry) config.Endpoint {
+	method := strings.ToUpper(e.Request.Method)
+	endpoint := config.Endpoint{
+		Name:    curl.EndpointName(method, e.Request.URL),
+		URL:     e.Request.URL,
+		Method:  method,
+		Headers: make(map[string]string),
+		Expect:  config.Expectation{Status: config.AnyStatus()},
+	}
+	if e.Response.Status > 0 {
+		endpoint.Expect.Status = config.ExactStatus(e.Response.Status)
+	}
+	for _, h := range e.Request.Headers {
+		if strings.HasPrefix(h.Name, ":") || skippedHeaders[strings.ToLower(h.Name)] {
+			continue
+		}
+		endpoint.Headers[h.Name] = h.Value
+	}
+	if e.Request.PostData != nil {
+		endpoint.Body = e.Request.PostData.Text
+		if !hasHeader(endpoint.Headers, "Content-Type") && e.Request.PostData.MimeType != "" {
+			endpoint.Headers["Content-Type"] = e.Request.PostData.MimeType
+		}
+	}
+	return endpoint
+}
+
+// hasHeader reports whether the headers contain name, ignoring case.
+func hasHeader(headers map[string]string, name string) bool {
+	for k := range headers {
+		if strings.EqualFold(k, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -2,8 +2,10 @@ package logger
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/rs/zerolog"
@@ -18,6 +20,19 @@ type Logger struct {
 	console zerolog.Logger
 }
 
+// Options configures the outputs of a Logger.
+type Options struct {
+	// Dir is the directory of the log file. Defaults to DefaultLogDir.
+	Dir string
+	// Console is where console output is written. Defaults to os.Stdout.
+	Console io.Writer
+	// NoFile disables the file logger, so no log directory or file is created.
+	NoFile bool
+	// Redact, when set, is applied to every line before it is written, to
+	// hide sensitive data.
+	Redact func(string) string
+}
+
 // NewLogger creates a new Logger instance.
 //
 // The logger has two outputs: a file logger that logs everything to a file
@@ -27,27 +42,36 @@ type Logger struct {
 //
 // The method returns an error if it cannot create the log file or directory.
 func NewLogger(logDir string) (*Logger, error) {
+	return NewLoggerWithOptions(Options{Dir: logDir})
+}
+
+// NewLoggerWithOptions creates a new Logger instance like NewLogger, with the
+// outputs configured by opts.
+func NewLoggerWithOptions(opts Options) (*Logger, error) {
+	logDir := opts.Dir
 	if logDir == "" {
 		logDir = DefaultLogDir
 	}
-	// ensure log directory exists
-	if err := os.MkdirAll(logDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	console := opts.Console
+	if console == nil {
+		console = os.Stdout
 	}
 
-	// create log file
-	logFile := filepath.Join(logDir,
-		fmt.Sprintf("api_test_%s.log", time.Now().Format("2006-01-02_15-04-05")))
-
-	file, err := os.Create(logFile)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create log file: %w", err)
+	fileLogger := zerolog.Nop()
+	if !opts.NoFile {
+		var err error
+		fileLogger, err = newFileLogger(logDir, opts.Redact)
+		if err != nil {
+			return nil, err
+		}
 	}
-	fileLogger := zerolog.New(file).With().Timestamp().Str("component", "tmago").Logger()
 
 	// Create console logger with colors
+	if opts.Redact != nil {
+		console = redactWriter{out: console, redact: opts.Redact}
+	}
 	consoleWriter := zerolog.ConsoleWriter{
-		Out:        os.Stdout,
+		Out:        console,
 		TimeFormat: "15:04:05",
 		NoColor:    false,
 	}
@@ -63,6 +87,42 @@ func NewLogger(logDir string) (*Logger, error) {
 	}, nil
 }
 
+// newFileLogger creates a logger writing to a new timestamped file in logDir,
+// applying redact to every line when it is set.
+func newFileLogger(logDir string, redact func(string) string) (zerolog.Logger, error) {
+	// ensure log directory exists
+	if err := os.MkdirAll(logDir, 0755); err != nil {
+		return zerolog.Logger{}, fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	// create log file
+	logFile := filepath.Join(logDir,
+		fmt.Sprintf("api_test_%s.log", time.Now().Format("2006-01-02_15-04-05")))
+
+	file, err := os.Create(logFile)
+	if err != nil {
+		return zerolog.Logger{}, fmt.Errorf("failed to create log file: %w", err)
+	}
+	var out io.Writer = file
+	if redact != nil {
+		out = redactWriter{out: file, redact: redact}
+	}
+	return zerolog.New(out).With().Timestamp().Str("component", "tmago").Logger(), nil
+}
+
+// redactWriter applies redact to every line written to out.
+type redactWriter struct {
+	out    io.Writer
+	redact func(string) string
+}
+
+func (w redactWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(w.out, w.redact(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
 // TestStarted logs a message when a test is started, including the name of the
 // endpoint being tested, the HTTP method, and the URL.
 func (l *Logger) TestStarted(endpoint string, method string, url string) {
@@ -147,3 +207,36 @@ func (logger *Logger) Warn(message string) {
 	logger.log.Warn().Msg(message)
 	logger.console.Warn().Msg(message)
 }
+
+// ANSI colors of the lines of a JSON diff on the console
+const (
+	colorRed    = "\x1b[31m"
+	colorGreen  = "\x1b[32m"
+	colorYellow = "\x1b[33m"
+	colorReset  = "\x1b[0m"
+)
+
+// Diff logs the lines of a JSON diff at the WARN level, each starting with
+// "+" for an added value, "-" for a removed value or "~" for a changed one.
+// The console shows them colored: added in green, removed in red and changed
+// in yellow; the main logger gets them as a field.
+func (logger *Logger) Diff(lines []string) {
+	if len(lines) == 0 {
+		return
+	}
+	logger.log.Warn().Strs("diff", lines).Msg("JSON diff")
+
+	var b strings.Builder
+	b.WriteString("JSON diff")
+	for _, line := range lines {
+		color := colorYellow
+		switch {
+		case strings.HasPrefix(line, "+"):
+			color = colorGreen
+		case strings.HasPrefix(line, "-"):
+			color = colorRed
+		}
+		b.WriteString("\n    " + color + line + colorReset)
+	}
+	logger.console.Warn().Msg(b.String())
+}
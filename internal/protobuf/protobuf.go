@@ -0,0 +1,91 @@
+// Package protobuf converts protobuf messages between the JSON form used in
+// configs and checks and their binary encoding, with the message types of a
+// descriptor set compiled by protoc, so that no generated code is needed.
+package protobuf
+
+import (
+	"fmt"
+	"mime"
+	"os"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// ContentType is the media type of binary protobuf bodies.
+const ContentType = "application/x-protobuf"
+
+// IsContentType reports whether a Content-Type header announces a binary
+// protobuf body, as application/x-protobuf or application/protobuf.
+func IsContentType(header string) bool {
+	mediaType, _, err := mime.ParseMediaType(header)
+	if err != nil {
+		return false
+	}
+	return mediaType == ContentType || mediaType == "application/protobuf"
+}
+
+// Descriptors are the message types of a descriptor set.
+type Descriptors struct {
+	files *protoregistry.Files
+}
+
+// Load reads the descriptor set at path, as written by protoc with
+// --include_imports --descriptor_set_out.
+func Load(path string) (*Descriptors, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid descriptor set %s: %w", path, err)
+	}
+	files, err := protodesc.NewFiles(&set)
+	if err != nil {
+		return nil, fmt.Errorf("invalid descriptor set %s: %w", path, err)
+	}
+	return &Descriptors{files: files}, nil
+}
+
+// Message returns the message type with the given full name, e.g.
+// acme.users.v1.User.
+func (d *Descriptors) Message(name string) (protoreflect.MessageDescriptor, error) {
+	desc, err := d.files.FindDescriptorByName(protoreflect.FullName(name))
+	if err != nil {
+		return nil, fmt.Errorf("unknown protobuf message %s", name)
+	}
+	message, ok := desc.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a protobuf message", name)
+	}
+	return message, nil
+}
+
+// Encode marshals the JSON form of a message of the given type to its binary
+// encoding. An empty body encodes the empty message.
+func Encode(desc protoreflect.MessageDescriptor, body []byte) ([]byte, error) {
+	message := dynamicpb.NewMessage(desc)
+	if len(body) > 0 {
+		if err := protojson.Unmarshal(body, message); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", desc.FullName(), err)
+		}
+	}
+	return proto.Marshal(message)
+}
+
+// Decode unmarshals the binary encoding of a message of the given type to its
+// JSON form. Fields set to their default value are included, so that checks
+// can expect zeros and empty strings, which the binary encoding omits.
+func Decode(desc protoreflect.MessageDescriptor, data []byte) ([]byte, error) {
+	message := dynamicpb.NewMessage(desc)
+	if err := proto.Unmarshal(data, message); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", desc.FullName(), err)
+	}
+	return protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(message)
+}
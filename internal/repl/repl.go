@@ -0,0 +1,238 @@
+// Package repl implements an interactive prompt for ad-hoc requests, sent
+// with the runner like the requests of a run, which can be saved as
+// endpoints of a config file.
+package repl
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/JakubPluta/tmago/internal/config"
+	"github.com/JakubPluta/tmago/internal/curl"
+	"github.com/JakubPluta/tmago/internal/runner"
+)
+
+// Prompt is printed before every command.
+const Prompt = "tmago> "
+
+// MaxBodyShown is the number of bytes of a response body printed; the rest
+// is only counted.
+const MaxBodyShown = 4096
+
+// methods are the HTTP methods accepted as commands.
+var methods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+	http.MethodPatch, http.MethodDelete, http.MethodOptions,
+}
+
+const help = `Commands:
+  METHOD URL [BODY]     send a request, e.g. GET https://api.example.com/users;
+                        BODY is the rest of the line
+  header NAME: VALUE    send the header with later requests (an empty value removes it)
+  headers               list the headers sent with requests
+  save [NAME]           save the last request as an endpoint expecting its status
+  help                  show this help
+  exit, quit            leave the prompt (or Ctrl-D)
+URLs, headers and bodies may use {{...}} variables like the config file.
+`
+
+// Options configures a session.
+type Options struct {
+	In  io.Reader
+	Out io.Writer
+	// ConfigFile receives the endpoints saved with the save command. Without
+	// one, they are printed as YAML to paste into a config file.
+	ConfigFile string
+}
+
+// session is the state of an interactive prompt.
+type session struct {
+	runner  *runner.Runner
+	opts    Options
+	headers map[string]string
+	// last is the last request sent, with the status it was answered with
+	last *config.Endpoint
+	sent int
+}
+
+// Run reads commands from opts.In until it is exhausted, an exit command or
+// the context is done, and prints their outcome to opts.Out. Failed requests
+// and commands are reported without ending the session.
+func Run(ctx context.Context, r *runner.Runner, opts Options) error {
+	s := &session{runner: r, opts: opts, headers: make(map[string]string)}
+	scanner := bufio.NewScanner(opts.In)
+	fmt.Fprintln(opts.Out, `Type "help" for the commands.`)
+	for {
+		fmt.Fprint(opts.Out, Prompt)
+		if !scanner.Scan() {
+			fmt.Fprintln(opts.Out)
+			return scanner.Err()
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == "exit" || line == "quit" {
+			return nil
+		}
+		if err := s.execute(ctx, line); err != nil {
+			fmt.Fprintf(opts.Out, "error: %v\n", err)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+// execute runs a command line.
+func (s *session) execute(ctx context.Context, line string) error {
+	command, rest, _ := strings.Cut(line, " ")
+	rest = strings.TrimSpace(rest)
+	switch {
+	case command == "":
+		return nil
+	case command == "help":
+		fmt.Fprint(s.opts.Out, help)
+		return nil
+	case command == "header":
+		return s.setHeader(rest)
+	case command == "headers":
+		for _, name := range sortedKeys(s.headers) {
+			fmt.Fprintf(s.opts.Out, "%s: %s\n", name, s.headers[name])
+		}
+		return nil
+	case command == "save":
+		return s.save(rest)
+	case isMethod(command):
+		url, body, _ := strings.Cut(rest, " ")
+		if url == "" {
+			return fmt.Errorf("usage: %s URL [BODY]", strings.ToUpper(command))
+		}
+		return s.send(ctx, strings.ToUpper(command), url, strings.TrimSpace(body))
+	default:
+		return fmt.Errorf("unknown command %q, type help for the commands", command)
+	}
+}
+
+// setHeader sets or, with an empty value, removes a header sent with later
+// requests.
+func (s *session) setHeader(arg string) error {
+	name, value, ok := strings.Cut(arg, ":")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return fmt.Errorf("usage: header NAME: VALUE")
+	}
+	if value = strings.TrimSpace(value); value == "" {
+		delete(s.headers, name)
+		return nil
+	}
+	s.headers[name] = value
+	return nil
+}
+
+// send sends a request with the session headers and prints the response.
+func (s *session) send(ctx context.Context, method, url, body string) error {
+	endpoint := config.Endpoint{
+		Name:   curl.EndpointName(method, url),
+		URL:    url,
+		Method: method,
+		Body:   body,
+		Expect: config.Expectation{Status: config.AnyStatus()},
+	}
+	if len(s.headers) > 0 {
+		endpoint.Headers = make(map[string]string, len(s.headers))
+		for k, v := range s.headers {
+			endpoint.Headers[k] = v
+		}
+	}
+
+	s.sent++
+	detail, respBody, err := s.runner.Send(ctx, endpoint, s.sent)
+	if err != nil {
+		return err
+	}
+	if detail.ErrorMessage != "" {
+		return fmt.Errorf("%s", detail.ErrorMessage)
+	}
+
+	out := s.opts.Out
+	fmt.Fprintf(out, "%d %s in %s, %d bytes\n", detail.StatusCode, http.StatusText(detail.StatusCode),
+		detail.Duration.Round(time.Millisecond), detail.ResponseSize)
+	for _, name := range sortedKeys(detail.Headers) {
+		fmt.Fprintf(out, "%s: %s\n", name, detail.Headers[name])
+	}
+	if len(respBody) > 0 {
+		fmt.Fprintf(out, "\n%s\n", formatBody(respBody))
+	}
+	for _, msg := range detail.ValidationErrors {
+		fmt.Fprintf(out, "warning: %s\n", msg)
+	}
+
+	endpoint.Expect.Status = config.ExactStatus(detail.StatusCode)
+	s.last = &endpoint
+	return nil
+}
+
+// save appends the last request to the config file as an endpoint named
+// name, or its method and path, or prints it without a config file.
+func (s *session) save(name string) error {
+	if s.last == nil {
+		return fmt.Errorf("no request to save yet")
+	}
+	endpoint := *s.last
+	if name != "" {
+		endpoint.Name = name
+	}
+
+	if s.opts.ConfigFile == "" {
+		out, err := curl.Marshal(endpoint)
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(s.opts.Out, string(out))
+		return nil
+	}
+	if err := curl.Append(s.opts.ConfigFile, endpoint); err != nil {
+		return fmt.Errorf("appending endpoint: %w", err)
+	}
+	fmt.Fprintf(s.opts.Out, "Added endpoint %q to %s\n", endpoint.Name, s.opts.ConfigFile)
+	return nil
+}
+
+// formatBody indents a JSON body and truncates it to MaxBodyShown bytes.
+func formatBody(body []byte) string {
+	var indented bytes.Buffer
+	if json.Indent(&indented, body, "", "  ") == nil {
+		body = indented.Bytes()
+	}
+	body = bytes.TrimRight(body, "\n")
+	if len(body) > MaxBodyShown {
+		return fmt.Sprintf("%s\n... (%d more bytes)", body[:MaxBodyShown], len(body)-MaxBodyShown)
+	}
+	return string(body)
+}
+
+// isMethod reports whether the command is an HTTP method, in any case.
+func isMethod(command string) bool {
+	for _, m := range methods {
+		if strings.EqualFold(command, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedKeys returns the keys of a map in order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
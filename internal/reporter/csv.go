@@ -0,0 +1,64 @@
+package reporter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// csvHeader names the columns of the CSV report.
+var csvHeader = []string{
+	"endpoint", "method", "url", "total_requests", "successes", "failures", "error_rate",
+	"avg_ms", "min_ms", "max_ms", "p50_ms", "p90_ms", "p95_ms", "p99_ms", "rps", "bytes", "bytes_per_second", "errors",
+}
+
+// CSVWriter writes a row per endpoint with its request counts, latencies in
+// milliseconds and throughput to Filename, e.g. for spreadsheets.
+type CSVWriter struct {
+	Filename string
+}
+
+func (w CSVWriter) Write(report *Report) error {
+	file, err := os.Create(w.Filename)
+	if err != nil {
+		return fmt.Errorf("failed to create report file: %w", err)
+	}
+	defer file.Close()
+
+	out := csv.NewWriter(file)
+	out.Write(csvHeader)
+	for _, result := range report.TestResults {
+		out.Write([]string{
+			result.EndpointName,
+			result.Method,
+			result.URL,
+			strconv.Itoa(result.TotalRequests),
+			strconv.Itoa(result.SuccessCount),
+			strconv.Itoa(result.FailureCount),
+			strconv.FormatFloat(result.ErrorRate, 'f', 2, 64),
+			csvMillis(result.AverageLatency),
+			csvMillis(result.MinLatency),
+			csvMillis(result.MaxLatency),
+			csvMillis(result.Percentiles.P50),
+			csvMillis(result.Percentiles.P90),
+			csvMillis(result.Percentiles.P95),
+			csvMillis(result.Percentiles.P99),
+			strconv.FormatFloat(result.RequestsPerSecond, 'f', 2, 64),
+			strconv.FormatInt(result.BytesTransferred, 10),
+			strconv.FormatFloat(result.BytesPerSecond, 'f', 2, 64),
+			strconv.Itoa(len(result.Errors)),
+		})
+	}
+	out.Flush()
+	if err := out.Error(); err != nil {
+		return fmt.Errorf("failed to write report file: %w", err)
+	}
+	return file.Close()
+}
+
+// csvMillis formats a duration as milliseconds.
+func csvMillis(d time.Duration) string {
+	return strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 3, 64)
+}
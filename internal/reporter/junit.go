@@ -0,0 +1,103 @@
+package reporter
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// junitSuites is the root element of a JUnit XML report.
+type junitSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// JUnitWriter writes the report as JUnit XML to Filename, with a test case
+// per endpoint failing when any of its requests failed, for CI systems.
+type JUnitWriter struct {
+	Filename string
+}
+
+func (w JUnitWriter) Write(report *Report) error {
+	suite := junitSuite{
+		Name:      "tmago",
+		Tests:     len(report.TestResults),
+		Time:      junitSeconds(report.EndTime.Sub(report.StartTime).Seconds()),
+		Timestamp: report.StartTime.Format("2006-01-02T15:04:05"),
+	}
+	for _, result := range report.TestResults {
+		testCase := junitTestCase{
+			Name:      result.EndpointName,
+			ClassName: result.Method + " " + result.URL,
+			Time:      junitSeconds(result.EndTime.Sub(result.StartTime).Seconds()),
+		}
+		if result.FailureCount > 0 || len(result.Errors) > 0 {
+			suite.Failures++
+			testCase.Failure = junitFailureOf(result)
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	data, err := xml.MarshalIndent(junitSuites{Suites: []junitSuite{suite}}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+	data = append([]byte(xml.Header), data...)
+	if err := os.WriteFile(w.Filename, data, 0644); err != nil {
+		return fmt.Errorf("failed to write report file: %w", err)
+	}
+	return nil
+}
+
+// junitFailureOf describes the failed requests and errors of a result, with
+// its validation failures from the most frequent.
+func junitFailureOf(result TestResult) *junitFailure {
+	var text strings.Builder
+	for _, msg := range result.Errors {
+		fmt.Fprintln(&text, msg)
+	}
+	reasons := make([]string, 0, len(result.ValidationFailures))
+	for reason := range result.ValidationFailures {
+		reasons = append(reasons, reason)
+	}
+	sort.Slice(reasons, func(i, j int) bool {
+		a, b := result.ValidationFailures[reasons[i]], result.ValidationFailures[reasons[j]]
+		return a > b || (a == b && reasons[i] < reasons[j])
+	})
+	for _, reason := range reasons {
+		fmt.Fprintf(&text, "%dx %s\n", result.ValidationFailures[reason], reason)
+	}
+	message := fmt.Sprintf("%d of %d requests failed", result.FailureCount, result.TotalRequests)
+	if result.FailureCount == 0 {
+		message = result.Errors[0]
+	}
+	return &junitFailure{Message: message, Text: text.String()}
+}
+
+// junitSeconds formats a duration in seconds as JUnit expects.
+func junitSeconds(seconds float64) string {
+	return fmt.Sprintf("%.3f", seconds)
+}
@@ -0,0 +1,185 @@
+package reporter
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DetailPage is a file holding part of the request details of a report that
+// exceeded its maximum size.
+type DetailPage struct {
+	File     string
+	Requests int
+	// Endpoints are the endpoints with requests on the page
+	Endpoints []string
+}
+
+// MinReportSize is the smallest maximum report size that leaves room for the
+// markup of a detail page and its rows.
+const MinReportSize = 64 << 10
+
+// SetMaxReportSize caps the size of the HTML report in bytes. A report that
+// would be larger is written as a summary without request details, linking
+// the details split into pages of at most the same size. Zero disables the
+// cap; a smaller cap than MinReportSize is raised to it.
+func (r *Reporter) SetMaxReportSize(size int64) {
+	if size > 0 && size < MinReportSize {
+		size = MinReportSize
+	}
+	r.maxSize = size
+}
+
+// detailRow is a request detail rendered on a detail page.
+type detailRow struct {
+	Endpoint string
+	RequestDetail
+}
+
+// pageData is rendered by the detail page template.
+type pageData struct {
+	Page, Pages int
+	Summary     string
+	Rows        template.HTML
+}
+
+// writePaginatedHTML writes report as a summary to filename, with the request
+// details split into pages of at most maxSize bytes named after it, e.g.
+// report-details-1.html.
+func writePaginatedHTML(filename string, report Report, maxSize int64) error {
+	base := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	dir := filepath.Dir(filename)
+
+	// pages of a previous, larger run would be linked to nothing
+	stale, err := filepath.Glob(filepath.Join(dir, base+"-details-*.html"))
+	if err != nil {
+		return fmt.Errorf("failed to find stale detail pages: %w", err)
+	}
+	for _, file := range stale {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove stale detail page: %w", err)
+		}
+	}
+
+	rowTmpl, err := template.New("row").Parse(detailRowTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+	pageTmpl, err := template.New("page").Parse(detailPageTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	// split the rows into pages, leaving room for the page markup
+	var pages []DetailPage
+	var contents []string
+	var current bytes.Buffer
+	var page DetailPage
+	budget := maxSize - int64(len(detailPageTemplate)) - 1024
+	flush := func() {
+		if page.Requests == 0 {
+			return
+		}
+		page.File = fmt.Sprintf("%s-details-%d.html", base, len(pages)+1)
+		pages = append(pages, page)
+		contents = append(contents, current.String())
+		current.Reset()
+		page = DetailPage{}
+	}
+	for _, result := range report.TestResults {
+		for _, detail := range result.RequestDetails {
+			var row bytes.Buffer
+			if err := rowTmpl.Execute(&row, detailRow{Endpoint: result.EndpointName, RequestDetail: detail}); err != nil {
+				return fmt.Errorf("failed to execute template: %w", err)
+			}
+			if page.Requests > 0 && int64(current.Len()+row.Len()) > budget {
+				flush()
+			}
+			current.Write(row.Bytes())
+			page.Requests++
+			if n := len(page.Endpoints); n == 0 || page.Endpoints[n-1] != result.EndpointName {
+				page.Endpoints = append(page.Endpoints, result.EndpointName)
+			}
+		}
+	}
+	flush()
+
+	for i, p := range pages {
+		var out bytes.Buffer
+		data := pageData{Page: i + 1, Pages: len(pages), Summary: filepath.Base(filename), Rows: template.HTML(contents[i])}
+		if err := pageTmpl.Execute(&out, data); err != nil {
+			return fmt.Errorf("failed to execute template: %w", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, p.File), out.Bytes(), 0644); err != nil {
+			return fmt.Errorf("failed to write report file: %w", err)
+		}
+	}
+
+	// the summary keeps the statistics and charts computed from the details
+	results := make([]TestResult, len(report.TestResults))
+	for i, result := range report.TestResults {
+		result.RequestDetails = nil
+		results[i] = result
+	}
+	report.TestResults = results
+	report.DetailPages = pages
+
+	var out bytes.Buffer
+	if err := executeReport(&out, report); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filename, out.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write report file: %w", err)
+	}
+	return nil
+}
+
+const detailRowTemplate = `
+<tr class="{{if .Success}}bg-green-50{{else}}bg-red-50{{end}}">
+    <td class="px-4 py-2">{{.Endpoint}}</td>
+    <td class="px-4 py-2">{{.ID}}</td>
+    <td class="px-4 py-2">{{.Timestamp.Format "15:04:05.000"}}</td>
+    <td class="px-4 py-2">{{.Duration}}</td>
+    <td class="px-4 py-2">{{.StatusCode}}</td>
+    <td class="px-4 py-2">{{.ResponseSize}} bytes</td>
+    <td class="px-4 py-2">{{.TransportRetries}} / {{.StatusRetries}}</td>
+    <td class="px-4 py-2 text-sm">{{if .ErrorMessage}}{{.ErrorMessage}}{{end}}{{range .ValidationErrors}}<div>{{.}}</div>{{end}}</td>
+</tr>`
+
+const detailPageTemplate = `
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <title>Request Details {{.Page}} of {{.Pages}}</title>
+    <link href="https://cdn.jsdelivr.net/npm/tailwindcss@2.2.19/dist/tailwind.min.css" rel="stylesheet">
+</head>
+<body class="bg-gray-100 p-8">
+    <div class="max-w-7xl mx-auto bg-white rounded-lg shadow-lg p-6">
+        <h1 class="text-3xl font-bold mb-4">Request Details {{.Page}} of {{.Pages}}</h1>
+        <p class="mb-4"><a class="text-blue-600 underline" href="{{.Summary}}">Back to the report</a></p>
+        <div class="overflow-x-auto">
+            <table class="min-w-full">
+                <thead>
+                    <tr>
+                        <th class="px-4 py-2">Endpoint</th>
+                        <th class="px-4 py-2">ID</th>
+                        <th class="px-4 py-2">Time</th>
+                        <th class="px-4 py-2">Duration</th>
+                        <th class="px-4 py-2">Status</th>
+                        <th class="px-4 py-2">Size</th>
+                        <th class="px-4 py-2" title="transport / status retries">Retries</th>
+                        <th class="px-4 py-2">Errors</th>
+                    </tr>
+                </thead>
+                <tbody>{{.Rows}}
+                </tbody>
+            </table>
+        </div>
+    </div>
+</body>
+</html>
+`
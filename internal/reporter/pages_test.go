@@ -0,0 +1,59 @@
+package reporter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWritePaginatedHTML(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "report.html")
+	// a page of a previous, larger run
+	stale := filepath.Join(dir, "report-details-99.html")
+	if err := os.WriteFile(stale, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	details := make([]RequestDetail, 2000)
+	for i := range details {
+		details[i] = RequestDetail{ID: i + 1, Timestamp: time.Now(), StatusCode: 200, Success: true}
+	}
+	report := Report{TestResults: []TestResult{{EndpointName: "list", RequestDetails: details}}}
+	if err := writePaginatedHTML(filename, report, MinReportSize); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("stale page was not removed: %v", err)
+	}
+	pages, err := filepath.Glob(filepath.Join(dir, "report-details-*.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) < 2 {
+		t.Fatalf("got %d pages, want the details split", len(pages))
+	}
+	for _, page := range pages {
+		info, err := os.Stat(page)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Size() > MinReportSize {
+			t.Errorf("%s is %d bytes, over the maximum of %d", filepath.Base(page), info.Size(), MinReportSize)
+		}
+	}
+}
+
+func TestSetMaxReportSizeRaisesSmallSizes(t *testing.T) {
+	r := NewReporter()
+	r.SetMaxReportSize(100)
+	if r.maxSize != MinReportSize {
+		t.Errorf("maxSize = %d, want %d", r.maxSize, MinReportSize)
+	}
+	r.SetMaxReportSize(0)
+	if r.maxSize != 0 {
+		t.Errorf("maxSize = %d, want the cap disabled", r.maxSize)
+	}
+}
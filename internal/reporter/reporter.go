@@ -2,16 +2,38 @@
 package reporter
 
 import (
-	"fmt"
 	"html/template"
+	"math"
 	"os"
+	"runtime"
+	"slices"
 	"sort"
+	"sync"
 	"time"
 )
 
 type Reporter struct {
-	results []TestResult
-	start   time.Time
+	results     []TestResult
+	start       time.Time
+	maxDuration time.Duration
+	smoke       bool
+	bucket      time.Duration
+	labels      map[string]string
+	maxSize     int64
+	// sortRequests orders the request details by ID, see SetSortRequests
+	sortRequests bool
+	// parallelism bounds the endpoints summarized concurrently, see
+	// SetParallelism
+	parallelism int
+	// end is the end of a finished or loaded run, zero while running
+	end time.Time
+	// stream receives every result as it is added, see StreamResults
+	stream    *os.File
+	streamErr error
+	// mu guards results and summarized, the number of results whose
+	// statistics have been computed
+	mu         sync.Mutex
+	summarized int
 }
 
 func NewReporter() *Reporter {
@@ -24,36 +46,173 @@ func (r *Reporter) StartTest() {
 	r.start = time.Now()
 }
 
+// SetMaxDuration sets the wall-clock budget of the whole run. A run taking
+// longer is marked as exceeding it in the report.
+func (r *Reporter) SetMaxDuration(d time.Duration) {
+	r.maxDuration = d
+}
+
+// SetBucketWidth sets the width of the time windows of the status code
+// timeline. A zero width picks one for the length of the run.
+func (r *Reporter) SetBucketWidth(d time.Duration) {
+	r.bucket = d
+}
+
+// SetLabels sets the labels of the run, e.g. its environment and git SHA,
+// shown in the report header.
+func (r *Reporter) SetLabels(labels map[string]string) {
+	r.labels = labels
+}
+
+// SetSmoke marks the run as a smoke test, which sent a single request per
+// endpoint regardless of its concurrency and retry settings.
+func (r *Reporter) SetSmoke(smoke bool) {
+	r.smoke = smoke
+}
+
+// SetSortRequests lists the request details of every endpoint by ID instead
+// of in the order they completed, so that the reports of two runs can be
+// compared.
+func (r *Reporter) SetSortRequests(enabled bool) {
+	r.sortRequests = enabled
+}
+
+// SetParallelism sets the number of endpoints whose statistics are computed
+// concurrently when the report is prepared. Zero or less uses one goroutine
+// per available CPU.
+func (r *Reporter) SetParallelism(n int) {
+	r.parallelism = n
+}
+
+// AddResult adds the result of an endpoint to the report and the results
+// stream. Its statistics, such as percentiles and SLO compliance, are
+// computed from its request details when the report is prepared. It is safe
+// for concurrent use.
 func (r *Reporter) AddResult(result TestResult) {
-	// Calculate additional metrics before adding the result
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.results = append(r.results, result)
+	r.writeStream(streamLine{Result: &result})
+}
+
+// summarize computes the statistics of the results added since the last
+// report, each endpoint in its own goroutine, at most parallelism at a time.
+// The caller must hold mu.
+func (r *Reporter) summarize() {
+	pending := r.results[r.summarized:]
+	if len(pending) == 0 {
+		return
+	}
+	workers := r.parallelism
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, workers)
+	for i := range pending {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(result *TestResult) {
+			defer wg.Done()
+			if r.sortRequests {
+				sort.SliceStable(result.RequestDetails, func(i, j int) bool {
+					return result.RequestDetails[i].ID < result.RequestDetails[j].ID
+				})
+			}
+			summarizeResult(result)
+			<-sem
+		}(&pending[i])
+	}
+	wg.Wait()
+	r.summarized = len(r.results)
+}
+
+// summarizeResult computes the latency statistics, slowest requests,
+// fallback, TLS handshake and queued request counts, stage and SLO statistics
+// and response sizes of a result from its request details.
+func summarizeResult(result *TestResult) {
 	durations := make([]time.Duration, 0, len(result.RequestDetails))
-	for _, detail := range result.RequestDetails {
-		durations = append(durations, detail.Duration)
+	result.FallbackCount = 0
+	result.TLSHandshakes = 0
+	result.MaxTLSHandshake = 0
+	result.QueuedRequests = 0
+	result.MaxQueueWait = 0
+	result.TransportErrors = nil
+	var totalHandshake, totalQueueWait, totalLatency time.Duration
+	var minSize, maxSize, totalSize int64
+	for i, detail := range result.RequestDetails {
+		if detail.Success || result.LatencyIncludesFailures {
+			durations = append(durations, detail.Duration)
+			totalLatency += detail.Duration
+		}
+		if detail.ServedBy == ServedByFallback {
+			result.FallbackCount++
+		}
+		if category := transportErrorCategory(detail); category != "" {
+			if result.TransportErrors == nil {
+				result.TransportErrors = make(map[string]int)
+			}
+			result.TransportErrors[category]++
+		}
+		if detail.TLSHandshake > 0 {
+			result.TLSHandshakes++
+			totalHandshake += detail.TLSHandshake
+			if detail.TLSHandshake > result.MaxTLSHandshake {
+				result.MaxTLSHandshake = detail.TLSHandshake
+			}
+		}
+		if detail.QueueWait > 0 {
+			result.QueuedRequests++
+			totalQueueWait += detail.QueueWait
+			if detail.QueueWait > result.MaxQueueWait {
+				result.MaxQueueWait = detail.QueueWait
+			}
+		}
+		size := detail.ResponseSize
+		if i == 0 || size < minSize {
+			minSize = size
+		}
+		if size > maxSize {
+			maxSize = size
+		}
+		totalSize += size
+	}
+	if result.TLSHandshakes > 0 {
+		result.AvgTLSHandshake = totalHandshake / time.Duration(result.TLSHandshakes)
+	}
+	if result.QueuedRequests > 0 {
+		result.AvgQueueWait = totalQueueWait / time.Duration(result.QueuedRequests)
 	}
 
-	// Calculate percentiles
+	// calculatePercentiles sorts the durations
 	result.Percentiles = calculatePercentiles(durations)
+	result.MinLatency, result.MaxLatency, result.AverageLatency = 0, 0, 0
+	if len(durations) > 0 {
+		result.MinLatency = durations[0]
+		result.MaxLatency = durations[len(durations)-1]
+		result.AverageLatency = totalLatency / time.Duration(len(durations))
+	}
+	result.SlowestRequests = slowestRequests(result.RequestDetails, SlowestRequestsCount)
+
+	if len(result.Stages) > 0 {
+		calculateStageStats(result.Stages, result.RequestDetails)
+	}
+	if result.Benchmark != nil {
+		calculateBenchmark(result.Benchmark, result.Stages)
+	}
+
+	// Calculate SLO compliance
+	if result.SLO != nil {
+		calculateSLO(result.SLO, result.RequestDetails)
+	}
 
 	// Calculate response size statistics
 	if len(result.RequestDetails) > 0 {
-		var minSize, maxSize, totalSize int64
-		minSize = result.RequestDetails[0].ResponseSize
-		for _, detail := range result.RequestDetails {
-			size := detail.ResponseSize
-			if size < minSize {
-				minSize = size
-			}
-			if size > maxSize {
-				maxSize = size
-			}
-			totalSize += size
-		}
 		result.ResponseSizes.Min = minSize
 		result.ResponseSizes.Max = maxSize
 		result.ResponseSizes.Avg = totalSize / int64(len(result.RequestDetails))
 	}
-
-	r.results = append(r.results, result)
 }
 
 type RequestDetail struct {
@@ -64,8 +223,82 @@ type RequestDetail struct {
 	Success          bool
 	ErrorMessage     string
 	ResponseSize     int64
+	ContentLength    int64 // Content-Length header, -1 when unknown
+	DecompressedSize int64 // size of a compressed body once decompressed, 0 otherwise
 	Headers          map[string]string
+	Trailers         map[string]string // HTTP trailers sent after the body
 	ValidationErrors []string
+	TransportRetries int    // reconnects after transport errors
+	StatusRetries    int    // resends after retryable statuses
+	Stage            int    // load profile stage, starting at 1; 0 without a profile
+	MatchedVariant   string // anyOf variant the response body matched
+	Request          *SentRequest
+	Stack            string // stack trace of a request that panicked
+	// EventCount and EventLatencies, the arrival of every event since the
+	// request started, describe server-sent event streams
+	EventCount     int
+	EventLatencies []time.Duration
+	// ServedBy is the target that served the request of an endpoint with a
+	// fallback, ServedByPrimary or ServedByFallback, and PrimaryError why the
+	// primary target failed when the fallback was tried
+	ServedBy     string
+	PrimaryError string
+	// TLSHandshake is the duration of the TLS handshake of the request, zero
+	// when it reused a connection, and TLSResumed whether the handshake
+	// resumed a previous session
+	TLSHandshake time.Duration
+	TLSResumed   bool
+	// SavedTo is the file the response body was saved to, see saveResponseTo
+	SavedTo string `json:",omitempty"`
+	// Diff holds the differences between the expected and the actual body
+	// found by failed jsonEquals and match checks
+	Diff []DiffEntry `json:",omitempty"`
+	// QueueWait is the time the request waited for an in-flight slot of an
+	// endpoint with maxInFlight, included in Duration. For a retried request
+	// it is the wait of the last attempt, like Duration.
+	QueueWait time.Duration `json:",omitempty"`
+	// ErrorCategory classifies the transport error of a request that got no
+	// response, e.g. "connection refused" or "timeout", across endpoints
+	ErrorCategory string `json:",omitempty"`
+	// Failures are the failed checks of ValidationErrors with their kind,
+	// e.g. "status" or "header", to group them across requests
+	Failures []FailureEntry `json:",omitempty"`
+}
+
+// DiffEntry is a difference between an expected and an actual JSON value at
+// a path. Kind is "added", "removed" or "changed"; Expected and Actual are
+// JSON, empty for added and removed values respectively.
+type DiffEntry struct {
+	Path     string
+	Kind     string
+	Expected string `json:",omitempty"`
+	Actual   string `json:",omitempty"`
+}
+
+// FailureEntry is a failed check of a response. Path is what the check
+// applies to, a JSON path or a header name, and Expected and Actual the
+// compared values when the check has them. Message is the validation error.
+type FailureEntry struct {
+	Kind     string
+	Path     string `json:",omitempty"`
+	Expected string `json:",omitempty"`
+	Actual   string `json:",omitempty"`
+	Message  string
+}
+
+// Targets serving the requests of an endpoint with a fallback.
+const (
+	ServedByPrimary  = "primary"
+	ServedByFallback = "fallback"
+)
+
+// SentRequest is the request as it was finally sent, after interpolation and
+// redirects, with sensitive header values redacted.
+type SentRequest struct {
+	Method  string
+	URL     string
+	Headers map[string]string
+	Body    string
 }
 
 type LatencyPercentiles struct {
@@ -76,6 +309,26 @@ type LatencyPercentiles struct {
 	P99 time.Duration
 }
 
+// SLO status indicators, based on the fraction of the error budget consumed.
+const (
+	SLOStatusGreen = "green" // at most half of the budget consumed
+	SLOStatusAmber = "amber" // budget nearly or exactly consumed
+	SLOStatusRed   = "red"   // budget exceeded
+)
+
+// SLOResult describes how a run performed against a response-time SLO.
+// Target and Threshold are set by the caller, the rest is computed from the
+// request details when the report is prepared.
+type SLOResult struct {
+	Target     float64 // required fraction of good requests, e.g. 0.99
+	Threshold  time.Duration
+	Good       int     // successful requests faster than the threshold
+	Total      int     // requests counted towards the SLO, every request detail
+	Compliance float64 // fraction of good requests
+	BudgetBurn float64 // fraction of the error budget consumed, > 1 when exceeded
+	Status     string
+}
+
 type TestResult struct {
 	EndpointName     string
 	Method           string
@@ -100,10 +353,91 @@ type TestResult struct {
 		Max int64
 		Avg int64
 	}
-	RequestsPerSecond  float64
+	RequestsPerSecond float64
+	// BytesPerSecond is BytesTransferred over the duration of the endpoint
+	BytesPerSecond     float64
 	ErrorRate          float64
 	TimeoutCount       int
 	ValidationFailures map[string]int
+	SLO                *SLOResult
+	SlowestRequests    []RequestDetail
+	Stages             []StageStats
+	Autotune           *AutotuneResult
+	Benchmark          *BenchmarkResult
+	// TransportErrors counts the requests that got no response by the
+	// category of their error, next to ValidationFailures
+	TransportErrors map[string]int `json:",omitempty"`
+	// FallbackCount is the number of requests sent to the fallback target
+	FallbackCount int
+	// TLSHandshakes is the number of requests that made a TLS handshake, and
+	// AvgTLSHandshake and MaxTLSHandshake their duration
+	TLSHandshakes   int
+	AvgTLSHandshake time.Duration
+	MaxTLSHandshake time.Duration
+	// MinRPS is the throughput the endpoint was expected to sustain, zero
+	// when not checked
+	MinRPS float64
+	// TargetRPS is the throughput the pause of the users was adjusted to
+	// hold, zero without one
+	TargetRPS float64
+	// LatencyIncludesFailures makes the latency statistics (average, minimum,
+	// maximum and percentiles) cover every request; by default they only
+	// cover successful ones, so slow failures retried until they succeed do
+	// not skew them
+	LatencyIncludesFailures bool
+	// MaxInFlight is the cap on the requests in flight at once, zero without
+	// one, and QueuedRequests the number of requests that waited for a slot,
+	// AvgQueueWait and MaxQueueWait how long
+	MaxInFlight    int
+	QueuedRequests int
+	AvgQueueWait   time.Duration
+	MaxQueueWait   time.Duration
+}
+
+// StageStats summarises the requests of a load profile stage. Stage, Users and
+// Duration are set by the caller, the rest is computed from the request
+// details when the report is prepared.
+type StageStats struct {
+	Stage             int
+	Users             int
+	Duration          time.Duration
+	TotalRequests     int
+	SuccessCount      int
+	FailureCount      int
+	AverageLatency    time.Duration
+	P95Latency        time.Duration
+	RequestsPerSecond float64
+	ErrorRate         float64
+}
+
+// AutotuneResult is the outcome of an autotune run, whose steps are recorded
+// as Stages. It is set by the caller.
+type AutotuneResult struct {
+	BestUsers  int // highest stable concurrency, 0 when no step was stable
+	BestRPS    float64
+	StopReason string
+}
+
+// BenchmarkResult aggregates the cycles of a benchmark, recorded as Stages.
+// Cycles and Warmup are set by the caller, the rest is computed from the
+// statistics of the cycles when the report is prepared.
+type BenchmarkResult struct {
+	Cycles int
+	Warmup int // unmeasured requests sent before every cycle
+	// MedianP95 and MeanP95 are the central tendency of the P95 latency of
+	// the cycles, StdDevP95 its sample standard deviation, P95Variation the
+	// standard deviation relative to the mean in percent, and MinP95 and
+	// MaxP95 its range
+	MedianP95    time.Duration
+	MeanP95      time.Duration
+	StdDevP95    time.Duration
+	P95Variation float64
+	MinP95       time.Duration
+	MaxP95       time.Duration
+	// MedianRPS and StdDevRPS are the median and sample standard deviation
+	// of the requests per second of the cycles
+	MedianRPS float64
+	StdDevRPS float64
 }
 
 type Report struct {
@@ -121,8 +455,52 @@ type Report struct {
 		TotalTimeouts     int
 		TotalBytes        int64
 		RequestsPerSecond float64
+		// BytesPerSecond is the throughput of the response bodies over the
+		// whole run
+		BytesPerSecond float64
+		// StatusCodes and StatusClasses (2xx, 3xx, ...) count the responses
+		// of all endpoints
+		StatusCodes   map[int]int
+		StatusClasses map[string]int
 	}
-	ChartData ChartData
+	ChartData       ChartData
+	SlowestRequests []SlowRequest
+	FailureReasons  []FailureReason
+	// MaxDuration is the wall-clock budget of the run, 0 without one
+	MaxDuration      time.Duration
+	DurationExceeded bool
+	// Smoke is set when the run sent a single request per endpoint
+	Smoke bool
+	// Labels tie the run to what it tested, e.g. env=staging
+	Labels map[string]string `json:",omitempty"`
+	// DetailPages hold the request details of a report exceeding its
+	// maximum size, see SetMaxReportSize
+	DetailPages []DetailPage `json:",omitempty"`
+	// maxSize is the maximum size of the HTML report, see SetMaxReportSize
+	maxSize int64
+}
+
+// FailureReasonsCount is the number of most frequent failure reasons,
+// validation failures and transport error categories, listed for the whole
+// run.
+const FailureReasonsCount = 10
+
+// FailureReason is a validation failure reason or transport error category
+// with the number of requests it failed across all endpoints.
+type FailureReason struct {
+	Reason    string
+	Count     int
+	Endpoints []string
+}
+
+// SlowestRequestsCount is the number of slowest requests listed per endpoint
+// and across the whole run.
+const SlowestRequestsCount = 10
+
+// SlowRequest is a request listed among the slowest of the run.
+type SlowRequest struct {
+	EndpointName string
+	RequestDetail
 }
 
 type ChartData struct {
@@ -131,6 +509,8 @@ type ChartData struct {
 	SuccessRates  []float64
 	ErrorRates    []float64
 	RPSValues     []float64
+	// StatusTimeline is the status classes of all requests over time
+	StatusTimeline StatusTimeline
 }
 
 func calculatePercentiles(durations []time.Duration) LatencyPercentiles {
@@ -138,9 +518,7 @@ func calculatePercentiles(durations []time.Duration) LatencyPercentiles {
 		return LatencyPercentiles{}
 	}
 
-	sort.Slice(durations, func(i, j int) bool {
-		return durations[i] < durations[j]
-	})
+	slices.Sort(durations)
 
 	return LatencyPercentiles{
 		P50: durations[int(float64(len(durations))*0.50)],
@@ -151,6 +529,242 @@ func calculatePercentiles(durations []time.Duration) LatencyPercentiles {
 	}
 }
 
+// calculateStageStats computes the request counts, latency and throughput of
+// every load profile stage.
+func calculateStageStats(stages []StageStats, details []RequestDetail) {
+	byStage := make([][]RequestDetail, len(stages))
+	for _, detail := range details {
+		i := detail.Stage - 1
+		if i < 0 || i >= len(stages) {
+			continue
+		}
+		byStage[i] = append(byStage[i], detail)
+	}
+	for i := range stages {
+		SummarizeStage(&stages[i], byStage[i])
+	}
+}
+
+// SummarizeStage computes the request counts, average and P95 latency and
+// requests per second of a stage from the details of its requests. The stage
+// Duration must be set.
+func SummarizeStage(stage *StageStats, details []RequestDetail) {
+	var totalLatency time.Duration
+	durations := make([]time.Duration, 0, len(details))
+	stage.TotalRequests, stage.SuccessCount, stage.FailureCount = len(details), 0, 0
+	for _, detail := range details {
+		if detail.Success {
+			stage.SuccessCount++
+		} else {
+			stage.FailureCount++
+		}
+		totalLatency += detail.Duration
+		durations = append(durations, detail.Duration)
+	}
+	if stage.TotalRequests > 0 {
+		stage.AverageLatency = totalLatency / time.Duration(stage.TotalRequests)
+		stage.P95Latency = calculatePercentiles(durations).P95
+		stage.ErrorRate = percent(stage.FailureCount, stage.TotalRequests)
+	}
+	if stage.Duration > 0 {
+		stage.RequestsPerSecond = float64(stage.TotalRequests) / stage.Duration.Seconds()
+	}
+}
+
+// calculateBenchmark aggregates the P95 latency and requests per second of
+// the cycles of a benchmark.
+func calculateBenchmark(benchmark *BenchmarkResult, cycles []StageStats) {
+	if len(cycles) == 0 {
+		return
+	}
+	p95s := make([]float64, len(cycles))
+	rps := make([]float64, len(cycles))
+	for i, cycle := range cycles {
+		p95s[i] = float64(cycle.P95Latency)
+		rps[i] = cycle.RequestsPerSecond
+	}
+
+	mean, stdDev := meanStdDev(p95s)
+	benchmark.MedianP95 = time.Duration(median(p95s))
+	benchmark.MeanP95 = time.Duration(mean)
+	benchmark.StdDevP95 = time.Duration(stdDev)
+	benchmark.P95Variation = 0
+	if mean > 0 {
+		benchmark.P95Variation = stdDev / mean * 100
+	}
+	benchmark.MinP95 = time.Duration(slices.Min(p95s))
+	benchmark.MaxP95 = time.Duration(slices.Max(p95s))
+	benchmark.MedianRPS = median(rps)
+	_, benchmark.StdDevRPS = meanStdDev(rps)
+}
+
+// median sorts the values and returns their median, the mean of the two
+// middle values for an even number of values.
+func median(values []float64) float64 {
+	sort.Float64s(values)
+	n := len(values)
+	if n%2 == 1 {
+		return values[n/2]
+	}
+	return (values[n/2-1] + values[n/2]) / 2
+}
+
+// meanStdDev returns the mean and the sample standard deviation of the
+// values, zero for a single value.
+func meanStdDev(values []float64) (mean, stdDev float64) {
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	if len(values) < 2 {
+		return mean, 0
+	}
+	var squares float64
+	for _, v := range values {
+		squares += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(squares / float64(len(values)-1))
+}
+
+// calculateSLO computes the compliance and error budget burn of the request
+// details against the SLO target and threshold. A request is good when it
+// succeeded in no more than the threshold. Every request detail counts,
+// including attempts retried by retry.count and requests that failed without
+// a response, which are bad however fast they failed: the SLO measures the
+// requests the service was sent, not only those it eventually answered.
+//
+// The error budget is the allowed fraction of bad requests (1 - target); the
+// burn is the observed bad fraction divided by the budget.
+func calculateSLO(slo *SLOResult, details []RequestDetail) {
+	slo.Good, slo.Total = 0, len(details)
+	for _, detail := range details {
+		if detail.Success && detail.Duration <= slo.Threshold {
+			slo.Good++
+		}
+	}
+	if slo.Total == 0 {
+		return
+	}
+
+	slo.Compliance = float64(slo.Good) / float64(slo.Total)
+	if budget := 1 - slo.Target; budget > 0 {
+		slo.BudgetBurn = (1 - slo.Compliance) / budget
+	}
+
+	switch {
+	case slo.BudgetBurn <= 0.5:
+		slo.Status = SLOStatusGreen
+	case slo.BudgetBurn <= 1:
+		slo.Status = SLOStatusAmber
+	default:
+		slo.Status = SLOStatusRed
+	}
+}
+
+// slowestRequests returns up to n request details ordered by descending
+// duration, requests of the same duration in their order. Only the n slowest
+// are kept while scanning, so the details are not copied or sorted.
+func slowestRequests(details []RequestDetail, n int) []RequestDetail {
+	top := make([]int, 0, n+1)
+	for i := range details {
+		if n <= 0 || len(top) == n && details[i].Duration <= details[top[n-1]].Duration {
+			continue
+		}
+		j := sort.Search(len(top), func(k int) bool {
+			return details[top[k]].Duration < details[i].Duration
+		})
+		top = slices.Insert(top, j, i)
+		if len(top) > n {
+			top = top[:n]
+		}
+	}
+
+	slowest := make([]RequestDetail, len(top))
+	for k, i := range top {
+		slowest[k] = details[i]
+	}
+	return slowest
+}
+
+// globalSlowestRequests returns up to n of the slowest requests across all results.
+func globalSlowestRequests(results []TestResult, n int) []SlowRequest {
+	slowest := make([]SlowRequest, 0)
+	for _, result := range results {
+		for _, detail := range result.SlowestRequests {
+			slowest = append(slowest, SlowRequest{EndpointName: result.EndpointName, RequestDetail: detail})
+		}
+	}
+	sort.SliceStable(slowest, func(i, j int) bool {
+		return slowest[i].Duration > slowest[j].Duration
+	})
+	if len(slowest) > n {
+		slowest = slowest[:n]
+	}
+	return slowest
+}
+
+// transportErrorCategory returns the category of the transport error of a
+// failed request without a response, "" for other requests. Requests
+// recorded before categories were, e.g. in older results streams, are
+// "transport error".
+func transportErrorCategory(detail RequestDetail) string {
+	if detail.Success || detail.ErrorMessage == "" || len(detail.ValidationErrors) > 0 {
+		return ""
+	}
+	if detail.ErrorCategory == "" {
+		return "transport error"
+	}
+	return detail.ErrorCategory
+}
+
+// globalFailureReasons merges the validation failures and transport error
+// categories of all results, so that a systemic issue, e.g. expired
+// credentials or a refused connection, shows as a single reason failing many
+// endpoints, and returns up to n reasons ordered by descending count.
+func globalFailureReasons(results []TestResult, n int) []FailureReason {
+	byReason := make(map[string]*FailureReason)
+	reasons := make([]FailureReason, 0)
+	add := func(reason string, count int, endpoint string) {
+		fr, ok := byReason[reason]
+		if !ok {
+			fr = &FailureReason{Reason: reason}
+			byReason[reason] = fr
+		}
+		fr.Count += count
+		fr.Endpoints = append(fr.Endpoints, endpoint)
+	}
+	for _, result := range results {
+		for reason, count := range result.ValidationFailures {
+			add(reason, count, result.EndpointName)
+		}
+		for category, count := range result.TransportErrors {
+			add(category, count, result.EndpointName)
+		}
+	}
+	for _, fr := range byReason {
+		reasons = append(reasons, *fr)
+	}
+	sort.Slice(reasons, func(i, j int) bool {
+		if reasons[i].Count != reasons[j].Count {
+			return reasons[i].Count > reasons[j].Count
+		}
+		return reasons[i].Reason < reasons[j].Reason
+	})
+	if len(reasons) > n {
+		reasons = reasons[:n]
+	}
+	return reasons
+}
+
+// percent returns part as a percentage of total, or 0 without a total, so
+// endpoints without counted requests do not produce NaN.
+func percent(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total) * 100
+}
+
 func (r *Reporter) prepareChartData() ChartData {
 	data := ChartData{
 		Labels:        make([]string, len(r.results)),
@@ -163,21 +777,29 @@ func (r *Reporter) prepareChartData() ChartData {
 	for i, result := range r.results {
 		data.Labels[i] = result.EndpointName
 		data.LatencyValues[i] = float64(result.AverageLatency.Milliseconds())
-		data.SuccessRates[i] = float64(result.SuccessCount) / float64(result.TotalRequests) * 100
-		data.ErrorRates[i] = float64(result.FailureCount) / float64(result.TotalRequests) * 100
+		data.SuccessRates[i] = percent(result.SuccessCount, result.TotalRequests)
+		data.ErrorRates[i] = percent(result.FailureCount, result.TotalRequests)
 		data.RPSValues[i] = result.RequestsPerSecond
 	}
+	data.StatusTimeline = bucketStatusCodes(r.results, r.start, r.bucket)
 
 	return data
 }
 
 func (r *Reporter) prepareReport() Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.summarize()
+
 	report := Report{
 		TestResults:    r.results,
 		StartTime:      r.start,
-		EndTime:        time.Now(),
+		EndTime:        r.end,
 		TotalEndpoints: len(r.results),
 	}
+	if report.EndTime.IsZero() {
+		report.EndTime = time.Now()
+	}
 
 	var totalSuccessful, totalRequests int
 	var totalLatency time.Duration
@@ -186,8 +808,14 @@ func (r *Reporter) prepareReport() Report {
 	var totalBytes int64
 	var totalErrors int
 	var totalTimeouts int
+	statusCodes := make(map[int]int)
+	statusClasses := make(map[string]int)
 
 	for _, result := range r.results {
+		for code, count := range result.StatusCodes {
+			statusCodes[code] += count
+			statusClasses[statusClass(code)] += count
+		}
 		totalSuccessful += result.SuccessCount
 		totalRequests += result.TotalRequests
 		totalLatency += result.AverageLatency * time.Duration(result.TotalRequests)
@@ -204,7 +832,18 @@ func (r *Reporter) prepareReport() Report {
 	}
 
 	report.TotalRequests = totalRequests
-	report.SuccessRate = float64(totalSuccessful) / float64(totalRequests) * 100
+	report.SuccessRate = percent(totalSuccessful, totalRequests)
+
+	var averageLatency time.Duration
+	if totalRequests > 0 {
+		averageLatency = totalLatency / time.Duration(totalRequests)
+	}
+
+	var requestsPerSecond, bytesPerSecond float64
+	if elapsed := report.EndTime.Sub(report.StartTime); elapsed > 0 {
+		requestsPerSecond = float64(totalRequests) / elapsed.Seconds()
+		bytesPerSecond = float64(totalBytes) / elapsed.Seconds()
+	}
 
 	report.GlobalStats = struct {
 		AverageLatency    time.Duration
@@ -214,39 +853,56 @@ func (r *Reporter) prepareReport() Report {
 		TotalTimeouts     int
 		TotalBytes        int64
 		RequestsPerSecond float64
+		// BytesPerSecond is the throughput of the response bodies over the
+		// whole run
+		BytesPerSecond float64
+		StatusCodes    map[int]int
+		StatusClasses  map[string]int
 	}{
-		AverageLatency:    totalLatency / time.Duration(totalRequests),
+		AverageLatency:    averageLatency,
 		MaxLatency:        maxLatency,
 		MinLatency:        minLatency,
 		TotalErrors:       totalErrors,
 		TotalTimeouts:     totalTimeouts,
 		TotalBytes:        totalBytes,
-		RequestsPerSecond: float64(totalRequests) / report.EndTime.Sub(report.StartTime).Seconds(),
+		RequestsPerSecond: requestsPerSecond,
+		BytesPerSecond:    bytesPerSecond,
+		StatusCodes:       statusCodes,
+		StatusClasses:     statusClasses,
 	}
 
 	report.ChartData = r.prepareChartData()
+	report.SlowestRequests = globalSlowestRequests(r.results, SlowestRequestsCount)
+	report.FailureReasons = globalFailureReasons(r.results, FailureReasonsCount)
+	report.MaxDuration = r.maxDuration
+	report.DurationExceeded = r.maxDuration > 0 && report.EndTime.Sub(report.StartTime) > r.maxDuration
+	report.Smoke = r.smoke
+	report.Labels = r.labels
+	report.maxSize = r.maxSize
 	return report
 }
 
-func (r *Reporter) GenerateHTML(filename string) error {
-	report := r.prepareReport()
-
-	tmpl, err := template.New("report").Parse(reportTemplate)
-	if err != nil {
-		return fmt.Errorf("failed to parse template: %w", err)
-	}
+// Report returns the summary of all results added so far.
+func (r *Reporter) Report() Report {
+	return r.prepareReport()
+}
 
-	file, err := os.Create(filename)
-	if err != nil {
-		return fmt.Errorf("failed to create report file: %w", err)
-	}
-	defer file.Close()
+// GenerateHTML writes the HTML report to filename, see HTMLWriter.
+func (r *Reporter) GenerateHTML(filename string) error {
+	return r.Write(HTMLWriter{Filename: filename})
+}
 
-	if err := tmpl.Execute(file, report); err != nil {
-		return fmt.Errorf("failed to execute template: %w", err)
-	}
+// GenerateJSON writes the report as indented JSON to filename.
+func (r *Reporter) GenerateJSON(filename string) error {
+	return r.Write(JSONWriter{Filename: filename})
+}
 
-	return nil
+// templateFuncs are the helper functions available in the report template.
+var templateFuncs = template.FuncMap{
+	"mul100":  func(f float64) float64 { return f * 100 },
+	"percent": percent,
+	"inc":     func(i int) int { return i + 1 },
+	"mb":      func(f float64) float64 { return f / 1e6 },
 }
 
 const reportTemplate = `
@@ -263,9 +919,26 @@ const reportTemplate = `
     <div class="max-w-7xl mx-auto">
         <div class="bg-white rounded-lg shadow-lg p-6 mb-8">
             <h1 class="text-3xl font-bold mb-4">API Test Report</h1>
+            {{if .Labels}}
+            <div class="flex flex-wrap gap-2 mb-4">
+                {{range $key, $value := .Labels}}
+                <span class="px-3 py-1 rounded-full bg-gray-200 text-gray-800 text-sm font-mono">{{$key}}={{$value}}</span>
+                {{end}}
+            </div>
+            {{end}}
+            {{if .Smoke}}
+            <div class="bg-yellow-100 text-yellow-800 p-4 rounded-lg mb-4">
+                Smoke mode: a single request was sent per endpoint, ignoring the concurrency and retry settings.
+            </div>
+            {{end}}
+            {{if .DurationExceeded}}
+            <div class="bg-red-100 text-red-800 p-4 rounded-lg mb-4">
+                The run took {{.EndTime.Sub .StartTime}}, exceeding its budget of {{.MaxDuration}}.
+            </div>
+            {{end}}
             
             <!-- Global Summary -->
-            <div class="grid grid-cols-5 gap-4 mb-8">
+            <div class="grid grid-cols-6 gap-4 mb-8">
                 <div class="bg-blue-50 p-4 rounded-lg">
                     <h3 class="text-lg font-semibold text-blue-700">Total Requests</h3>
                     <p class="text-2xl">{{.TotalRequests}}</p>
@@ -282,6 +955,10 @@ const reportTemplate = `
                     <h3 class="text-lg font-semibold text-yellow-700">RPS</h3>
                     <p class="text-2xl">{{printf "%.2f" .GlobalStats.RequestsPerSecond}}</p>
                 </div>
+                <div class="bg-pink-50 p-4 rounded-lg">
+                    <h3 class="text-lg font-semibold text-pink-700">Throughput</h3>
+                    <p class="text-2xl">{{printf "%.2f" (mb .GlobalStats.BytesPerSecond)}} MB/s</p>
+                </div>
                 <div class="bg-indigo-50 p-4 rounded-lg">
                     <h3 class="text-lg font-semibold text-indigo-700">Concurrency</h3>
                     {{range .TestResults}}
@@ -300,7 +977,107 @@ const reportTemplate = `
                 <div>
                     <canvas id="successRateChart"></canvas>
                 </div>
+                {{if .ChartData.StatusTimeline.Series}}
+                <div class="col-span-2">
+                    <canvas id="statusTimelineChart" height="80"></canvas>
+                </div>
+                {{end}}
+            </div>
+
+            <!-- Global Status Code Distribution -->
+            {{if .GlobalStats.StatusCodes}}
+            <div class="mb-8">
+                <h2 class="text-2xl font-bold mb-4">Status Codes</h2>
+                <div class="grid grid-cols-5 gap-4 mb-4">
+                    {{range $class, $count := .GlobalStats.StatusClasses}}
+                    <div class="p-4 rounded-lg {{if eq $class "2xx"}}bg-green-50{{else if eq $class "3xx"}}bg-blue-50{{else if eq $class "4xx"}}bg-yellow-50{{else}}bg-red-50{{end}}">
+                        <h3 class="text-lg font-semibold">{{$class}}</h3>
+                        <p class="text-2xl">{{$count}}</p>
+                        <p class="text-sm text-gray-600">{{printf "%.2f" (percent $count $.TotalRequests)}}% of requests</p>
+                    </div>
+                    {{end}}
+                </div>
+                <div class="grid grid-cols-8 gap-2">
+                    {{range $code, $count := .GlobalStats.StatusCodes}}
+                    <div class="bg-white p-2 rounded shadow text-center">
+                        <span class="font-mono">{{$code}}</span>
+                        <span class="block text-sm text-gray-600">{{$count}} requests</span>
+                    </div>
+                    {{end}}
+                </div>
             </div>
+            {{end}}
+
+            <!-- Slowest Requests -->
+            {{if .SlowestRequests}}
+            <div class="mb-8">
+                <h2 class="text-2xl font-bold mb-4">Slowest Requests</h2>
+                <div class="bg-white p-4 rounded shadow overflow-x-auto">
+                    <table class="min-w-full">
+                        <thead>
+                            <tr>
+                                <th class="px-4 py-2">Endpoint</th>
+                                <th class="px-4 py-2">ID</th>
+                                <th class="px-4 py-2">Duration</th>
+                                <th class="px-4 py-2">Status</th>
+                                <th class="px-4 py-2">Size</th>
+                            </tr>
+                        </thead>
+                        <tbody>
+                            {{range .SlowestRequests}}
+                            <tr class="{{if .Success}}bg-green-50{{else}}bg-red-50{{end}}">
+                                <td class="px-4 py-2">{{.EndpointName}}</td>
+                                <td class="px-4 py-2">{{.ID}}</td>
+                                <td class="px-4 py-2">{{.Duration}}</td>
+                                <td class="px-4 py-2">{{.StatusCode}}</td>
+                                <td class="px-4 py-2">{{.ResponseSize}} bytes</td>
+                            </tr>
+                            {{end}}
+                        </tbody>
+                    </table>
+                </div>
+            </div>
+            {{end}}
+
+            <!-- Failure Reasons -->
+            {{if .FailureReasons}}
+            <div class="mb-8">
+                <h2 class="text-2xl font-bold mb-4">Top Failure Reasons</h2>
+                <div class="bg-white p-4 rounded shadow overflow-x-auto">
+                    <table class="min-w-full">
+                        <thead>
+                            <tr>
+                                <th class="px-4 py-2">Reason</th>
+                                <th class="px-4 py-2">Count</th>
+                                <th class="px-4 py-2">Endpoints</th>
+                            </tr>
+                        </thead>
+                        <tbody>
+                            {{range .FailureReasons}}
+                            <tr class="bg-red-50">
+                                <td class="px-4 py-2">{{.Reason}}</td>
+                                <td class="px-4 py-2">{{.Count}}</td>
+                                <td class="px-4 py-2">{{range $i, $e := .Endpoints}}{{if $i}}, {{end}}{{$e}}{{end}}</td>
+                            </tr>
+                            {{end}}
+                        </tbody>
+                    </table>
+                </div>
+            </div>
+            {{end}}
+
+            <!-- Request Detail Pages -->
+            {{if .DetailPages}}
+            <div class="mb-8">
+                <h2 class="text-2xl font-bold mb-4">Request Details</h2>
+                <p class="mb-2 text-gray-600">The request details exceeded the maximum report size and were split into pages.</p>
+                <ul class="list-disc pl-6">
+                    {{range $i, $page := .DetailPages}}
+                    <li><a class="text-blue-600 underline" href="{{$page.File}}">Page {{inc $i}}</a>: {{$page.Requests}} requests of {{range $j, $e := $page.Endpoints}}{{if $j}}, {{end}}{{$e}}{{end}}</li>
+                    {{end}}
+                </ul>
+            </div>
+            {{end}}
 
             <!-- Detailed Results -->
             {{range .TestResults}}
@@ -312,7 +1089,7 @@ const reportTemplate = `
                             {{.SuccessCount}}/{{.TotalRequests}} Success
                         </span>
                         <span class="px-3 py-1 rounded-full bg-blue-100 text-blue-800">
-                            {{printf "%.2f" .RequestsPerSecond}} RPS
+                            {{printf "%.2f" .RequestsPerSecond}} RPS{{if .MinRPS}} <span class="{{if lt .RequestsPerSecond .MinRPS}}text-red-600{{else}}text-green-600{{end}}">(min {{printf "%.2f" .MinRPS}})</span>{{end}}{{if .TargetRPS}} <span class="text-gray-600">(target {{printf "%.2f" .TargetRPS}})</span>{{end}}
                         </span>
                         {{if .IsConcurrent}}
                         <span class="px-3 py-1 rounded-full bg-indigo-100 text-indigo-800">
@@ -341,6 +1118,7 @@ const reportTemplate = `
                             <p>Max: {{.ResponseSizes.Max}} bytes</p>
                             <p>Avg: {{.ResponseSizes.Avg}} bytes</p>
                             <p>Total: {{.BytesTransferred}} bytes</p>
+                            <p>Throughput: {{printf "%.2f" (mb .BytesPerSecond)}} MB/s</p>
                         </div>
                     </div>
                     <div class="bg-white p-4 rounded shadow">
@@ -349,10 +1127,87 @@ const reportTemplate = `
                             <p>Error Rate: {{printf "%.2f" .ErrorRate}}%</p>
                             <p>Timeouts: {{.TimeoutCount}}</p>
                             <p>Validation Failures: {{len .ValidationFailures}}</p>
+                            {{range $category, $count := .TransportErrors}}
+                            <p>{{$category}}: {{$count}}</p>
+                            {{end}}
+                            {{if .FallbackCount}}<p>Served by Fallback: {{.FallbackCount}}</p>{{end}}
+                            {{if .TLSHandshakes}}<p>TLS Handshakes: {{.TLSHandshakes}} (avg {{.AvgTLSHandshake}}, max {{.MaxTLSHandshake}})</p>{{end}}
+                            {{if .MaxInFlight}}<p>Max In Flight: {{.MaxInFlight}} ({{.QueuedRequests}} queued{{if .QueuedRequests}}, avg wait {{.AvgQueueWait}}, max {{.MaxQueueWait}}{{end}})</p>{{end}}
                         </div>
                     </div>
                 </div>
 
+                {{if .Stages}}
+                <!-- Load Profile Stages -->
+                <div class="mb-4">
+                    <h4 class="font-semibold mb-2">{{if .Autotune}}Autotune{{else if .Benchmark}}Benchmark{{else}}Load Profile{{end}}</h4>
+                    {{with .Autotune}}
+                    <div class="bg-white p-4 rounded shadow mb-2">
+                        {{if .BestUsers}}
+                        <p>Best stable concurrency: <strong>{{.BestUsers}} users</strong> at {{printf "%.2f" .BestRPS}} RPS</p>
+                        {{else}}
+                        <p>No stable concurrency found</p>
+                        {{end}}
+                        <p>Stopped: {{.StopReason}}</p>
+                    </div>
+                    {{end}}
+                    {{with .Benchmark}}
+                    <div class="bg-white p-4 rounded shadow mb-2">
+                        <p>{{.Cycles}} cycles{{if .Warmup}}, each after {{.Warmup}} warm-up requests{{end}}</p>
+                        <p>P95 latency: median <strong>{{.MedianP95}}</strong>, mean {{.MeanP95}}, std dev {{.StdDevP95}} ({{printf "%.1f" .P95Variation}}%), range {{.MinP95}} - {{.MaxP95}}</p>
+                        <p>RPS: median {{printf "%.2f" .MedianRPS}}, std dev {{printf "%.2f" .StdDevRPS}}</p>
+                    </div>
+                    {{end}}
+                    <div class="bg-white p-4 rounded shadow overflow-x-auto">
+                        <table class="min-w-full">
+                            <thead>
+                                <tr>
+                                    <th class="px-4 py-2">Stage</th>
+                                    <th class="px-4 py-2">Users</th>
+                                    <th class="px-4 py-2">Duration</th>
+                                    <th class="px-4 py-2">Requests</th>
+                                    <th class="px-4 py-2">Success</th>
+                                    <th class="px-4 py-2">Failures</th>
+                                    <th class="px-4 py-2">Avg Latency</th>
+                                    <th class="px-4 py-2">P95 Latency</th>
+                                    <th class="px-4 py-2">RPS</th>
+                                </tr>
+                            </thead>
+                            <tbody>
+                                {{range .Stages}}
+                                <tr>
+                                    <td class="px-4 py-2">{{.Stage}}</td>
+                                    <td class="px-4 py-2">{{.Users}}</td>
+                                    <td class="px-4 py-2">{{.Duration}}</td>
+                                    <td class="px-4 py-2">{{.TotalRequests}}</td>
+                                    <td class="px-4 py-2">{{.SuccessCount}}</td>
+                                    <td class="px-4 py-2">{{.FailureCount}}</td>
+                                    <td class="px-4 py-2">{{.AverageLatency}}</td>
+                                    <td class="px-4 py-2">{{.P95Latency}}</td>
+                                    <td class="px-4 py-2">{{printf "%.2f" .RequestsPerSecond}}</td>
+                                </tr>
+                                {{end}}
+                            </tbody>
+                        </table>
+                    </div>
+                </div>
+                {{end}}
+
+                {{if .SLO}}
+                <!-- SLO -->
+                <div class="mb-4">
+                    <h4 class="font-semibold mb-2">SLO</h4>
+                    <div class="bg-white p-4 rounded shadow flex items-center space-x-4">
+                        <span class="px-3 py-1 rounded-full {{if eq .SLO.Status "green"}}bg-green-100 text-green-800{{else if eq .SLO.Status "amber"}}bg-yellow-100 text-yellow-800{{else}}bg-red-100 text-red-800{{end}}">
+                            {{.SLO.Status}}
+                        </span>
+                        <p>Target: {{printf "%.2f" (mul100 .SLO.Target)}}% under {{.SLO.Threshold}}</p>
+                        <p>Compliance: {{printf "%.2f" (mul100 .SLO.Compliance)}}% ({{.SLO.Good}}/{{.SLO.Total}})</p>
+                        <p>Budget burn: {{printf "%.2f" (mul100 .SLO.BudgetBurn)}}%</p>
+                    </div>
+                </div>
+                {{end}}
+
                 <!-- Status Code Distribution -->
                 <div class="mb-4">
                     <h4 class="font-semibold mb-2">Status Codes</h4>
@@ -380,6 +1235,37 @@ const reportTemplate = `
                 </div>
                 {{end}}
 
+                <!-- Slowest Requests -->
+                {{if .SlowestRequests}}
+                <div class="mb-4">
+                    <h4 class="font-semibold mb-2">Top {{len .SlowestRequests}} Slowest Requests</h4>
+                    <div class="bg-white p-4 rounded shadow overflow-x-auto">
+                        <table class="min-w-full">
+                            <thead>
+                                <tr>
+                                    <th class="px-4 py-2">ID</th>
+                                    <th class="px-4 py-2">Time</th>
+                                    <th class="px-4 py-2">Duration</th>
+                                    <th class="px-4 py-2">Status</th>
+                                    <th class="px-4 py-2">Size</th>
+                                </tr>
+                            </thead>
+                            <tbody>
+                                {{range .SlowestRequests}}
+                                <tr class="{{if .Success}}bg-green-50{{else}}bg-red-50{{end}}">
+                                    <td class="px-4 py-2">{{.ID}}</td>
+                                    <td class="px-4 py-2">{{.Timestamp.Format "15:04:05.000"}}</td>
+                                    <td class="px-4 py-2">{{.Duration}}</td>
+                                    <td class="px-4 py-2">{{.StatusCode}}</td>
+                                    <td class="px-4 py-2">{{.ResponseSize}} bytes</td>
+                                </tr>
+                                {{end}}
+                            </tbody>
+                        </table>
+                    </div>
+                </div>
+                {{end}}
+
                 <!-- Request Timeline -->
 {{if .RequestDetails}}
 <div>
@@ -393,6 +1279,8 @@ const reportTemplate = `
                     <th class="px-4 py-2 cursor-pointer" onclick="sortTable('requestTable-{{.EndpointName}}', 2)">Duration ↕</th>
                     <th class="px-4 py-2 cursor-pointer" onclick="sortTable('requestTable-{{.EndpointName}}', 3)">Status ↕</th>
                     <th class="px-4 py-2 cursor-pointer" onclick="sortTable('requestTable-{{.EndpointName}}', 4)">Size ↕</th>
+                    <th class="px-4 py-2 cursor-pointer" onclick="sortTable('requestTable-{{.EndpointName}}', 5)">Retries ↕</th>
+                    <th class="px-4 py-2">Request</th>
                 </tr>
             </thead>
             <tbody>
@@ -402,7 +1290,42 @@ const reportTemplate = `
                     <td class="px-4 py-2" data-value="{{.Timestamp.Unix}}">{{.Timestamp.Format "15:04:05.000"}}</td>
                     <td class="px-4 py-2" data-value="{{.Duration.Nanoseconds}}">{{.Duration}}</td>
                     <td class="px-4 py-2" data-value="{{.StatusCode}}">{{.StatusCode}}</td>
-                    <td class="px-4 py-2" data-value="{{.ResponseSize}}">{{.ResponseSize}} bytes</td>
+                    <td class="px-4 py-2" data-value="{{.ResponseSize}}"{{if ge .ContentLength 0}} title="Content-Length: {{.ContentLength}}"{{end}}>{{.ResponseSize}} bytes{{if .DecompressedSize}}<div class="text-xs text-gray-500">{{.DecompressedSize}} decompressed</div>{{end}}</td>
+                    <td class="px-4 py-2" data-value="{{.TransportRetries}}.{{.StatusRetries}}" title="transport / status retries">{{.TransportRetries}} / {{.StatusRetries}}</td>
+                    <td class="px-4 py-2">
+                        {{if eq .ServedBy "fallback"}}
+                        <span class="text-xs bg-yellow-100 text-yellow-800 rounded px-1" title="primary failed: {{.PrimaryError}}">fallback</span>
+                        {{end}}
+                        {{with .Request}}
+                        <details>
+                            <summary class="cursor-pointer">{{.Method}}</summary>
+                            <pre class="text-xs whitespace-pre-wrap">{{.Method}} {{.URL}}
+{{range $k, $v := .Headers}}{{$k}}: {{$v}}
+{{end}}{{if .Body}}
+{{.Body}}{{end}}</pre>
+                        </details>
+                        {{end}}
+                        {{if .Trailers}}
+                        <details>
+                            <summary class="cursor-pointer">trailers</summary>
+                            <pre class="text-xs whitespace-pre-wrap">{{range $k, $v := .Trailers}}{{$k}}: {{$v}}
+{{end}}</pre>
+                        </details>
+                        {{end}}
+                        {{if .Diff}}
+                        <details open>
+                            <summary class="cursor-pointer text-red-700">JSON diff</summary>
+                            <pre class="text-xs whitespace-pre-wrap bg-gray-900 text-gray-100 rounded p-2">{{range .Diff}}{{if eq .Kind "added"}}<span class="text-green-400">+ {{.Path}}: {{.Actual}}</span>{{else if eq .Kind "removed"}}<span class="text-red-400">- {{.Path}}: {{.Expected}}</span>{{else}}<span class="text-yellow-300">~ {{.Path}}: {{.Expected}} -&gt; {{.Actual}}</span>{{end}}
+{{end}}</pre>
+                        </details>
+                        {{end}}
+                        {{if .Stack}}
+                        <details>
+                            <summary class="cursor-pointer text-red-700">{{.ErrorMessage}}</summary>
+                            <pre class="text-xs whitespace-pre-wrap">{{.Stack}}</pre>
+                        </details>
+                        {{end}}
+                    </td>
                 </tr>
                 {{end}}
             </tbody>
@@ -566,6 +1489,50 @@ const reportTemplate = `
             }
         }
     });
+
+    // Status codes over time, stacked by class
+    {{with .ChartData.StatusTimeline}}{{if .Series}}
+    const statusColors = {'1xx': 'rgb(201, 203, 207)', '2xx': 'rgb(75, 192, 192)', '3xx': 'rgb(54, 162, 235)', '4xx': 'rgb(255, 205, 86)', '5xx': 'rgb(255, 99, 132)', 'error': 'rgb(153, 102, 255)'};
+    new Chart(document.getElementById('statusTimelineChart').getContext('2d'), {
+        type: 'line',
+        data: {
+            labels: {{.Labels}},
+            datasets: [{{range .Series}}{
+                label: {{.Class}},
+                data: {{.Counts}},
+                borderColor: statusColors[{{.Class}}],
+                backgroundColor: statusColors[{{.Class}}],
+                fill: true,
+                pointRadius: 0
+            },{{end}}]
+        },
+        options: {
+            responsive: true,
+            plugins: {
+                title: {
+                    display: true,
+                    text: 'Status Codes over Time ({{.Width}} windows)'
+                }
+            },
+            scales: {
+                y: {
+                    stacked: true,
+                    beginAtZero: true,
+                    title: {
+                        display: true,
+                        text: 'Requests'
+                    }
+                },
+                x: {
+                    title: {
+                        display: true,
+                        text: 'Time since start'
+                    }
+                }
+            }
+        }
+    });
+    {{end}}{{end}}
     </script>
 </body>
 </html>
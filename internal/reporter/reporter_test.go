@@ -0,0 +1,150 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// syntheticResults creates the results of endpoints with requests each,
+// with random latencies and one request in a hundred failing.
+func syntheticResults(endpoints, requests int) []TestResult {
+	random := rand.New(rand.NewSource(1))
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	results := make([]TestResult, endpoints)
+	for i := range results {
+		details := make([]RequestDetail, requests)
+		for j := range details {
+			details[j] = RequestDetail{
+				ID:           j + 1,
+				Timestamp:    start.Add(time.Duration(j) * time.Millisecond),
+				Duration:     time.Duration(random.Intn(500)) * time.Millisecond,
+				StatusCode:   200,
+				Success:      j%100 != 0,
+				ResponseSize: 512,
+			}
+		}
+		results[i] = TestResult{EndpointName: fmt.Sprintf("endpoint %d", i+1), TotalRequests: requests, RequestDetails: details}
+	}
+	return results
+}
+
+// BenchmarkReport prepares the report of 16 endpoints with 50,000 requests
+// each, computing the endpoint statistics in one goroutine and in one per CPU.
+func BenchmarkReport(b *testing.B) {
+	results := syntheticResults(16, 50_000)
+	for _, parallelism := range []int{1, 0} {
+		name := fmt.Sprintf("parallelism=%d", parallelism)
+		if parallelism == 0 {
+			name = "parallelism=cpus"
+		}
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				r := NewReporter()
+				r.SetParallelism(parallelism)
+				for _, result := range results {
+					// every run computes the statistics of fresh details
+					result.RequestDetails = append([]RequestDetail(nil), result.RequestDetails...)
+					r.AddResult(result)
+				}
+				b.StartTimer()
+				r.Report()
+			}
+		})
+	}
+}
+
+func TestBenchmarkAggregatesCycles(t *testing.T) {
+	// the P95 of a cycle of 20 requests is its slowest request
+	p95s := []time.Duration{100 * time.Millisecond, 300 * time.Millisecond, 200 * time.Millisecond}
+	result := TestResult{Benchmark: &BenchmarkResult{Cycles: len(p95s)}}
+	for i, p95 := range p95s {
+		result.Stages = append(result.Stages, StageStats{Stage: i + 1, Duration: time.Second})
+		for j := 0; j < 20; j++ {
+			duration := 10 * time.Millisecond
+			if j == 7 {
+				duration = p95
+			}
+			result.RequestDetails = append(result.RequestDetails, RequestDetail{Stage: i + 1, Duration: duration, Success: true})
+		}
+	}
+
+	summarizeResult(&result)
+
+	for i, p95 := range p95s {
+		if got := result.Stages[i].P95Latency; got != p95 {
+			t.Errorf("cycle %d P95 = %s, want %s", i+1, got, p95)
+		}
+	}
+	benchmark := result.Benchmark
+	want := BenchmarkResult{
+		Cycles:       3,
+		MedianP95:    200 * time.Millisecond,
+		MeanP95:      200 * time.Millisecond,
+		StdDevP95:    100 * time.Millisecond,
+		P95Variation: 50,
+		MinP95:       100 * time.Millisecond,
+		MaxP95:       300 * time.Millisecond,
+		MedianRPS:    20,
+		StdDevRPS:    0,
+	}
+	if *benchmark != want {
+		t.Errorf("benchmark = %+v, want %+v", *benchmark, want)
+	}
+}
+
+func TestReportMergesFailureReasons(t *testing.T) {
+	r := NewReporter()
+	r.AddResult(TestResult{
+		EndpointName:       "users",
+		TotalRequests:      5,
+		FailureCount:       5,
+		ValidationFailures: map[string]int{"expected status 200, got 500": 3, "path id not found in response": 2},
+	})
+	r.AddResult(TestResult{
+		EndpointName:       "orders",
+		TotalRequests:      4,
+		FailureCount:       4,
+		ValidationFailures: map[string]int{"expected status 200, got 500": 4},
+	})
+	r.AddResult(TestResult{EndpointName: "idle"})
+
+	report := r.Report()
+	want := []FailureReason{
+		{Reason: "expected status 200, got 500", Count: 7, Endpoints: []string{"users", "orders"}},
+		{Reason: "path id not found in response", Count: 2, Endpoints: []string{"users"}},
+	}
+	if !reflect.DeepEqual(report.FailureReasons, want) {
+		t.Errorf("failure reasons = %+v, want %+v", report.FailureReasons, want)
+	}
+
+	// an endpoint without requests must not produce NaN, which JSON rejects
+	if _, err := json.Marshal(report); err != nil {
+		t.Errorf("report does not encode as JSON: %v", err)
+	}
+}
+
+func TestReportWithoutCountedRequestsHasNoNaN(t *testing.T) {
+	r := NewReporter()
+	r.AddResult(TestResult{EndpointName: "idle"})
+	r.AddResult(TestResult{EndpointName: "unreachable", RequestDetails: []RequestDetail{{ErrorMessage: "connection refused"}}})
+
+	report := r.Report()
+	if report.TotalRequests != 0 || report.SuccessRate != 0 || report.GlobalStats.AverageLatency != 0 {
+		t.Errorf("total %d, success rate %v, average latency %s, want zeros",
+			report.TotalRequests, report.SuccessRate, report.GlobalStats.AverageLatency)
+	}
+	chart := r.prepareChartData()
+	for i := range chart.Labels {
+		if chart.SuccessRates[i] != 0 || chart.ErrorRates[i] != 0 {
+			t.Errorf("%s: success rate %v, error rate %v, want 0", chart.Labels[i], chart.SuccessRates[i], chart.ErrorRates[i])
+		}
+	}
+	if _, err := json.Marshal(report); err != nil {
+		t.Errorf("report does not encode as JSON: %v", err)
+	}
+}
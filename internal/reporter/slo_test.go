@@ -0,0 +1,67 @@
+package reporter
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSLOAroundTheThreshold(t *testing.T) {
+	const threshold = 100 * time.Millisecond
+	ok := func(d time.Duration) RequestDetail { return RequestDetail{Success: true, Duration: d} }
+
+	tests := []struct {
+		name       string
+		details    []RequestDetail
+		good       int
+		total      int
+		compliance float64
+		burn       float64
+		status     string
+	}{
+		{
+			name:    "under and at the threshold are good",
+			details: []RequestDetail{ok(threshold - time.Microsecond), ok(threshold), ok(threshold), ok(threshold)},
+			good:    4, total: 4, compliance: 1, burn: 0, status: SLOStatusGreen,
+		},
+		{
+			name:    "over the threshold is bad",
+			details: []RequestDetail{ok(threshold - time.Microsecond), ok(threshold), ok(threshold + time.Microsecond), ok(threshold)},
+			good:    3, total: 4, compliance: 0.75, burn: 2.5, status: SLOStatusRed,
+		},
+		{
+			// retried attempts and requests failing without a response count
+			// as bad requests, however fast they failed
+			name: "failed attempts and transport errors count",
+			details: []RequestDetail{
+				ok(time.Millisecond), ok(time.Millisecond), ok(time.Millisecond), ok(time.Millisecond), ok(time.Millisecond),
+				ok(time.Millisecond), ok(time.Millisecond), ok(time.Millisecond), ok(time.Millisecond), ok(time.Millisecond),
+				ok(time.Millisecond), ok(time.Millisecond), ok(time.Millisecond), ok(time.Millisecond), ok(time.Millisecond),
+				ok(time.Millisecond), ok(time.Millisecond), ok(time.Millisecond),
+				{Success: false, Duration: time.Millisecond, StatusCode: 503},
+				{Success: false, Duration: time.Millisecond, ErrorMessage: "connection refused"},
+			},
+			good: 18, total: 20, compliance: 0.9, burn: 1, status: SLOStatusAmber,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewReporter()
+			r.AddResult(TestResult{
+				EndpointName:   "users",
+				RequestDetails: tt.details,
+				SLO:            &SLOResult{Target: 0.9, Threshold: threshold},
+			})
+			slo := r.Report().TestResults[0].SLO
+			if slo.Good != tt.good || slo.Total != tt.total {
+				t.Errorf("good %d of %d, want %d of %d", slo.Good, slo.Total, tt.good, tt.total)
+			}
+			if math.Abs(slo.Compliance-tt.compliance) > 1e-9 || math.Abs(slo.BudgetBurn-tt.burn) > 1e-9 {
+				t.Errorf("compliance %v, budget burn %v, want %v and %v", slo.Compliance, slo.BudgetBurn, tt.compliance, tt.burn)
+			}
+			if slo.Status != tt.status {
+				t.Errorf("status %s, want %s", slo.Status, tt.status)
+			}
+		})
+	}
+}
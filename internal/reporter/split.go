@@ -0,0 +1,115 @@
+package reporter
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// unsafeFilenameChars matches runs of characters not allowed in report file names.
+var unsafeFilenameChars = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// reportFilename turns an endpoint name into a safe file name without
+// extension, e.g. "Get user #1" becomes "get-user-1".
+func reportFilename(name string) string {
+	base := strings.Trim(unsafeFilenameChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if base == "" {
+		base = "endpoint"
+	}
+	return base
+}
+
+// splitEntry is an endpoint listed in the index of split reports.
+type splitEntry struct {
+	TestResult
+	File string
+}
+
+// GenerateSplit writes an HTML and a JSON report for every endpoint into dir,
+// named after the endpoint, plus an index.html linking them.
+func (r *Reporter) GenerateSplit(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create reports directory: %w", err)
+	}
+
+	r.mu.Lock()
+	r.summarize()
+	results := r.results
+	r.mu.Unlock()
+
+	used := make(map[string]bool)
+	entries := make([]splitEntry, 0, len(results))
+	for _, result := range results {
+		base := reportFilename(result.EndpointName)
+		for n := 2; used[base]; n++ {
+			base = fmt.Sprintf("%s-%d", reportFilename(result.EndpointName), n)
+		}
+		used[base] = true
+
+		endpoint := &Reporter{results: []TestResult{result}, summarized: 1, start: result.StartTime, bucket: r.bucket, labels: r.labels, maxSize: r.maxSize}
+		if err := endpoint.GenerateHTML(filepath.Join(dir, base+".html")); err != nil {
+			return err
+		}
+		if err := endpoint.GenerateJSON(filepath.Join(dir, base+".json")); err != nil {
+			return err
+		}
+		entries = append(entries, splitEntry{TestResult: result, File: base + ".html"})
+	}
+
+	tmpl, err := template.New("index").Parse(indexTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	file, err := os.Create(filepath.Join(dir, "index.html"))
+	if err != nil {
+		return fmt.Errorf("failed to create report file: %w", err)
+	}
+	defer file.Close()
+
+	if err := tmpl.Execute(file, entries); err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+	return nil
+}
+
+const indexTemplate = `
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <title>API Test Reports</title>
+    <link href="https://cdn.jsdelivr.net/npm/tailwindcss@2.2.19/dist/tailwind.min.css" rel="stylesheet">
+</head>
+<body class="bg-gray-100 p-8">
+    <div class="max-w-7xl mx-auto">
+        <div class="bg-white rounded-lg shadow-lg p-6">
+            <h1 class="text-3xl font-bold mb-4">API Test Reports</h1>
+            <table class="min-w-full">
+                <thead>
+                    <tr>
+                        <th class="px-4 py-2 text-left">Endpoint</th>
+                        <th class="px-4 py-2">Success</th>
+                        <th class="px-4 py-2">Avg Latency</th>
+                        <th class="px-4 py-2">RPS</th>
+                    </tr>
+                </thead>
+                <tbody>
+                    {{range .}}
+                    <tr class="{{if eq .FailureCount 0}}bg-green-50{{else}}bg-red-50{{end}}">
+                        <td class="px-4 py-2"><a class="text-blue-700 underline" href="{{.File}}">{{.EndpointName}}</a></td>
+                        <td class="px-4 py-2">{{.SuccessCount}}/{{.TotalRequests}}</td>
+                        <td class="px-4 py-2">{{.AverageLatency}}</td>
+                        <td class="px-4 py-2">{{printf "%.2f" .RequestsPerSecond}}</td>
+                    </tr>
+                    {{end}}
+                </tbody>
+            </table>
+        </div>
+    </div>
+</body>
+</html>
+`
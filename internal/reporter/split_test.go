@@ -0,0 +1,51 @@
+package reporter
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateSplit(t *testing.T) {
+	r := NewReporter()
+	for _, name := range []string{"Get user #1", "get user 1", "/"} {
+		r.AddResult(TestResult{
+			EndpointName:   name,
+			TotalRequests:  1,
+			SuccessCount:   1,
+			RequestDetails: []RequestDetail{{ID: 1, Timestamp: time.Now(), StatusCode: 200, Success: true}},
+		})
+	}
+
+	dir := filepath.Join(t.TempDir(), "split")
+	if err := r.GenerateSplit(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var files []string
+	for _, entry := range entries {
+		files = append(files, entry.Name())
+	}
+	sort.Strings(files)
+	want := []string{"endpoint.html", "endpoint.json", "get-user-1-2.html", "get-user-1-2.json", "get-user-1.html", "get-user-1.json", "index.html"}
+	if strings.Join(files, " ") != strings.Join(want, " ") {
+		t.Fatalf("files = %v, want %v", files, want)
+	}
+
+	index, err := os.ReadFile(filepath.Join(dir, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{"get-user-1.html", "get-user-1-2.html", "endpoint.html"} {
+		if !strings.Contains(string(index), `href="`+file+`"`) {
+			t.Errorf("index does not link %s", file)
+		}
+	}
+}
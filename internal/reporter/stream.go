@@ -0,0 +1,133 @@
+package reporter
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// RunInfo holds the settings of a run needed to regenerate its report.
+type RunInfo struct {
+	Start       time.Time
+	MaxDuration time.Duration
+	Smoke       bool
+	BucketWidth time.Duration
+	Labels      map[string]string
+}
+
+// streamLine is a line of a results file: the run settings on the first line,
+// followed by the result of every endpoint as it finished and the end of the
+// run once it finished.
+type streamLine struct {
+	Run    *RunInfo    `json:"run,omitempty"`
+	Result *TestResult `json:"result,omitempty"`
+	End    *time.Time  `json:"end,omitempty"`
+}
+
+// StreamResults creates filename and appends every result added from now on
+// to it as a JSON line, synced to disk, so a crashed run still leaves the
+// results of its completed endpoints. Call it after the run settings are set,
+// and CloseStream at the end of the run. LoadResults reads the file back.
+func (r *Reporter) StreamResults(filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create results file: %w", err)
+	}
+	r.stream = file
+	r.writeStream(streamLine{Run: &RunInfo{
+		Start:       r.start,
+		MaxDuration: r.maxDuration,
+		Smoke:       r.smoke,
+		BucketWidth: r.bucket,
+		Labels:      r.labels,
+	}})
+	return r.streamErr
+}
+
+// CloseStream ends the run, recording its end in the results file, closes
+// the file and returns the first error writing it.
+func (r *Reporter) CloseStream() error {
+	if r.stream == nil {
+		return nil
+	}
+	r.end = time.Now()
+	r.writeStream(streamLine{End: &r.end})
+	if err := r.stream.Close(); err != nil && r.streamErr == nil {
+		r.streamErr = fmt.Errorf("failed to close results file: %w", err)
+	}
+	r.stream = nil
+	return r.streamErr
+}
+
+// writeStream appends a line to the results file, keeping the first error.
+func (r *Reporter) writeStream(line streamLine) {
+	if r.stream == nil || r.streamErr != nil {
+		return
+	}
+	data, err := json.Marshal(line)
+	if err == nil {
+		_, err = r.stream.Write(append(data, '\n'))
+	}
+	if err == nil {
+		err = r.stream.Sync()
+	}
+	if err != nil {
+		r.streamErr = fmt.Errorf("failed to write results file: %w", err)
+	}
+}
+
+// LoadResults creates a reporter with the run settings and results of a file
+// written by StreamResults, to regenerate the report of a run, including one
+// that did not finish. Such a run ends with its last result.
+func LoadResults(filename string) (*Reporter, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	r := NewReporter()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxResultLine)
+	var lineErr error
+	for n := 1; scanner.Scan(); n++ {
+		if lineErr != nil {
+			return nil, lineErr
+		}
+		var line streamLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			// the last line of a crashed run may be incomplete and is skipped
+			lineErr = fmt.Errorf("%s:%d: %w", filename, n, err)
+			continue
+		}
+		if line.Run != nil {
+			r.start = line.Run.Start
+			r.maxDuration = line.Run.MaxDuration
+			r.smoke = line.Run.Smoke
+			r.bucket = line.Run.BucketWidth
+			r.labels = line.Run.Labels
+		}
+		if line.Result != nil {
+			r.results = append(r.results, *line.Result)
+			if line.Result.EndTime.After(r.end) {
+				r.end = line.Result.EndTime
+			}
+		}
+		if line.End != nil {
+			r.end = *line.End
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read results file: %w", err)
+	}
+	if r.end.IsZero() {
+		r.end = r.start
+	}
+	return r, nil
+}
+
+// maxResultLine bounds the size of a line of a results file, which holds
+// every request of an endpoint.
+const maxResultLine = 1 << 30
@@ -0,0 +1,106 @@
+package reporter
+
+import (
+	"time"
+)
+
+// StatusTimeline counts the requests of all endpoints per status class in
+// consecutive time windows of Width since the start of the run, to show when
+// a server started failing.
+type StatusTimeline struct {
+	Width  time.Duration
+	Labels []string // offset of every window from the start of the run
+	Series []StatusSeries
+}
+
+// StatusSeries is the number of requests of a status class per window.
+type StatusSeries struct {
+	Class  string
+	Counts []int
+}
+
+// statusClasses are the series of a StatusTimeline, in stacking order.
+// Requests without a response are counted as "error".
+var statusClasses = []string{"1xx", "2xx", "3xx", "4xx", "5xx", "error"}
+
+// bucketWidths are the window widths picked from for automatic bucketing.
+var bucketWidths = []time.Duration{
+	100 * time.Millisecond, 250 * time.Millisecond, 500 * time.Millisecond,
+	time.Second, 2 * time.Second, 5 * time.Second, 10 * time.Second, 15 * time.Second, 30 * time.Second,
+	time.Minute, 2 * time.Minute, 5 * time.Minute, 10 * time.Minute, 15 * time.Minute, 30 * time.Minute, time.Hour,
+}
+
+// targetBuckets is the approximate number of windows of automatic bucketing.
+const targetBuckets = 30
+
+// maxBuckets caps the number of windows, so that a width far too small for
+// the run, e.g. --bucket 1ns, does not allocate a window per nanosecond.
+const maxBuckets = 1000
+
+// statusClass returns the class of a status code.
+func statusClass(status int) string {
+	if status < 100 || status >= 600 {
+		return "error"
+	}
+	return statusClasses[status/100-1]
+}
+
+// bucketStatusCodes counts the request details of the results per status
+// class in windows of width since start. A zero width picks one from
+// bucketWidths giving about targetBuckets windows, and a width giving more
+// than maxBuckets windows is widened to fit them. Classes without requests
+// are left out.
+func bucketStatusCodes(results []TestResult, start time.Time, width time.Duration) StatusTimeline {
+	var end time.Time
+	for _, result := range results {
+		for _, detail := range result.RequestDetails {
+			if detail.Timestamp.After(end) {
+				end = detail.Timestamp
+			}
+		}
+	}
+	if end.IsZero() {
+		return StatusTimeline{}
+	}
+
+	if width <= 0 {
+		width = bucketWidths[len(bucketWidths)-1]
+		for _, w := range bucketWidths {
+			if end.Sub(start)/w < targetBuckets {
+				width = w
+				break
+			}
+		}
+	}
+
+	if span := end.Sub(start); span/width >= maxBuckets {
+		width = span/(maxBuckets-1) + 1
+	}
+
+	n := int(end.Sub(start)/width) + 1
+	counts := make(map[string][]int)
+	for _, result := range results {
+		for _, detail := range result.RequestDetails {
+			i := int(detail.Timestamp.Sub(start) / width)
+			if i < 0 {
+				i = 0
+			}
+			class := statusClass(detail.StatusCode)
+			if counts[class] == nil {
+				counts[class] = make([]int, n)
+			}
+			counts[class][i]++
+		}
+	}
+
+	timeline := StatusTimeline{Width: width, Labels: make([]string, n)}
+	for i := range timeline.Labels {
+		timeline.Labels[i] = (time.Duration(i) * width).String()
+	}
+	for _, class := range statusClasses {
+		if counts[class] != nil {
+			timeline.Series = append(timeline.Series, StatusSeries{Class: class, Counts: counts[class]})
+		}
+	}
+	return timeline
+}
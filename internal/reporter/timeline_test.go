@@ -0,0 +1,57 @@
+package reporter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBucketStatusCodes(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(offset time.Duration, status int) RequestDetail {
+		return RequestDetail{Timestamp: start.Add(offset), StatusCode: status}
+	}
+	results := []TestResult{{RequestDetails: []RequestDetail{
+		at(0, 200), at(500*time.Millisecond, 101), at(time.Second, 503), at(2*time.Second, 0),
+	}}}
+
+	timeline := bucketStatusCodes(results, start, time.Second)
+	if len(timeline.Labels) != 3 {
+		t.Fatalf("got %d windows, want 3", len(timeline.Labels))
+	}
+	want := map[string][]int{
+		"1xx":   {1, 0, 0},
+		"2xx":   {1, 0, 0},
+		"5xx":   {0, 1, 0},
+		"error": {0, 0, 1},
+	}
+	if len(timeline.Series) != len(want) {
+		t.Fatalf("got series %+v, want %v", timeline.Series, want)
+	}
+	for _, series := range timeline.Series {
+		for i, count := range want[series.Class] {
+			if series.Counts[i] != count {
+				t.Errorf("%s counts = %v, want %v", series.Class, series.Counts, want[series.Class])
+				break
+			}
+		}
+	}
+}
+
+func TestBucketStatusCodesCapsWindows(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	results := []TestResult{{RequestDetails: []RequestDetail{
+		{Timestamp: start, StatusCode: 200},
+		{Timestamp: start.Add(time.Hour), StatusCode: 200},
+	}}}
+
+	timeline := bucketStatusCodes(results, start, time.Nanosecond)
+	if n := len(timeline.Labels); n > maxBuckets {
+		t.Fatalf("got %d windows, want at most %d", n, maxBuckets)
+	}
+	if timeline.Width <= time.Nanosecond {
+		t.Errorf("width = %s, want it widened", timeline.Width)
+	}
+	if counts := timeline.Series[0].Counts; counts[0] != 1 || counts[len(counts)-1] != 1 {
+		t.Errorf("the first and last windows should hold a request each")
+	}
+}
@@ -0,0 +1,140 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// ReportWriter writes a finished report in one output format, e.g. to a
+// file or to a results database. Implement it to add an output format when
+// embedding tmago, and register it with RegisterFormat to select it by name.
+type ReportWriter interface {
+	Write(report *Report) error
+}
+
+// Built-in report formats.
+const (
+	FormatHTML  = "html"
+	FormatJSON  = "json"
+	FormatCSV   = "csv"
+	FormatJUnit = "junit"
+)
+
+// DefaultFormats are the formats written when none are selected.
+var DefaultFormats = []string{FormatHTML, FormatJSON}
+
+// WriterFactory creates the writer of a format, writing into dir.
+type WriterFactory func(dir string) ReportWriter
+
+var (
+	formatsMu sync.RWMutex
+	formats   = map[string]WriterFactory{
+		FormatHTML:  func(dir string) ReportWriter { return HTMLWriter{Filename: filepath.Join(dir, "report.html")} },
+		FormatJSON:  func(dir string) ReportWriter { return JSONWriter{Filename: filepath.Join(dir, "report.json")} },
+		FormatCSV:   func(dir string) ReportWriter { return CSVWriter{Filename: filepath.Join(dir, "report.csv")} },
+		FormatJUnit: func(dir string) ReportWriter { return JUnitWriter{Filename: filepath.Join(dir, "report.xml")} },
+	}
+)
+
+// RegisterFormat makes a format selectable by name, replacing any format of
+// the same name.
+func RegisterFormat(name string, factory WriterFactory) {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+	formats[name] = factory
+}
+
+// Formats returns the names of the registered formats, sorted.
+func Formats() []string {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewWriter returns the writer of a registered format, writing into dir.
+func NewWriter(format, dir string) (ReportWriter, error) {
+	formatsMu.RLock()
+	factory, ok := formats[format]
+	formatsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown report format %q, expected one of %v", format, Formats())
+	}
+	return factory(dir), nil
+}
+
+// Write prepares the report once and writes it with every writer, returning
+// the errors of the writers that failed. A failing writer does not prevent
+// the others from writing.
+func (r *Reporter) Write(writers ...ReportWriter) error {
+	report := r.prepareReport()
+	var errs []error
+	for _, w := range writers {
+		if err := w.Write(&report); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// HTMLWriter writes the HTML report to Filename. A report larger than the
+// maximum size set with SetMaxReportSize is split into pages.
+type HTMLWriter struct {
+	Filename string
+}
+
+func (w HTMLWriter) Write(report *Report) error {
+	var out bytes.Buffer
+	if err := executeReport(&out, *report); err != nil {
+		return err
+	}
+	if report.maxSize > 0 && int64(out.Len()) > report.maxSize {
+		return writePaginatedHTML(w.Filename, *report, report.maxSize)
+	}
+
+	if err := os.WriteFile(w.Filename, out.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to create report file: %w", err)
+	}
+	return nil
+}
+
+// executeReport renders the HTML report to w.
+func executeReport(w io.Writer, report Report) error {
+	tmpl, err := template.New("report").Funcs(templateFuncs).Parse(reportTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+	if err := tmpl.Execute(w, report); err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+	return nil
+}
+
+// JSONWriter writes the report as indented JSON to Filename.
+type JSONWriter struct {
+	Filename string
+}
+
+func (w JSONWriter) Write(report *Report) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+
+	if err := os.WriteFile(w.Filename, data, 0644); err != nil {
+		return fmt.Errorf("failed to write report file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/JakubPluta/tmago/internal/config"
+	"github.com/JakubPluta/tmago/internal/reporter"
+)
+
+// runAutotune searches for the highest sustainable concurrency of the
+// endpoint. Every step runs its number of users for the step duration and is
+// recorded as a stage. The search stops at the first step whose P95 latency or
+// error rate exceeds the targets, or whose requests per second do not improve
+// enough on the best step, and the best stable step is reported.
+func (r *Runner) runAutotune(ctx context.Context, endpoint config.Endpoint, result *reporter.TestResult) error {
+	tune := *endpoint.Concurrent.Autotune
+	requestChan := make(chan reporter.RequestDetail, int(tune.Max)*2)
+	errChan := make(chan error, int(tune.Max)*2)
+	collected := make(chan error, 1)
+	go func() {
+		collected <- r.collectResults(endpoint, result, requestChan, errChan)
+	}()
+
+	result.IsConcurrent = true
+	result.Autotune = &reporter.AutotuneResult{StopReason: fmt.Sprintf("reached the maximum of %d users", tune.Max)}
+	var best *reporter.StageStats
+	var nextID int64
+
+	for users := int(tune.Start); users <= int(tune.Max) && ctx.Err() == nil; users += int(tune.Step) {
+		stage := reporter.StageStats{Stage: len(result.Stages) + 1, Users: users, Duration: tune.StepDuration}
+		r.logger.Info(fmt.Sprintf("%s: autotune step %d, %d users for %s", endpoint.Name, stage.Stage, users, tune.StepDuration))
+
+		details := r.runAutotuneStep(ctx, endpoint, stage.Stage, users, tune.StepDuration, &nextID, requestChan, errChan)
+		reporter.SummarizeStage(&stage, details)
+		result.Stages = append(result.Stages, stage)
+		result.ConcurrentUsers = users
+		r.logger.Info(fmt.Sprintf("%s: %d users, %.2f RPS, P95 %s, error rate %.2f%%",
+			endpoint.Name, users, stage.RequestsPerSecond, stage.P95Latency, stage.ErrorRate))
+
+		if reason := autotuneDegraded(tune, stage, best); reason != "" {
+			result.Autotune.StopReason = reason
+			break
+		}
+		best = &result.Stages[len(result.Stages)-1]
+	}
+
+	if best != nil {
+		result.Autotune.BestUsers = best.Users
+		result.Autotune.BestRPS = best.RequestsPerSecond
+	}
+	if ctx.Err() != nil {
+		result.Autotune.StopReason = "cancelled"
+	}
+
+	close(requestChan)
+	close(errChan)
+	return <-collected
+}
+
+// runAutotuneStep runs the given number of users for duration, sending every
+// completed request to the collector, and returns the details of the step.
+func (r *Runner) runAutotuneStep(ctx context.Context, endpoint config.Endpoint, stage, users int, duration time.Duration,
+	nextID *int64, requestChan chan<- reporter.RequestDetail, errChan chan<- error) []reporter.RequestDetail {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	details := make([]reporter.RequestDetail, 0)
+	end := time.Now().Add(duration)
+
+	for i := 0; i < users; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for time.Now().Before(end) && ctx.Err() == nil {
+				r.jitter(ctx, endpoint.Concurrent.Jitter)
+				detail, err := r.executeRequest(ctx, endpoint, int(atomic.AddInt64(nextID, 1)))
+				detail.Stage = stage
+				mu.Lock()
+				details = append(details, detail)
+				mu.Unlock()
+				requestChan <- detail
+				if err != nil {
+					errChan <- err
+					continue
+				}
+
+				r.pause(ctx, endpoint.Concurrent, nil)
+			}
+		}()
+	}
+	wg.Wait()
+	return details
+}
+
+// autotuneDegraded returns why a step is worse than acceptable, or an empty
+// string when the search should continue.
+func autotuneDegraded(tune config.Autotune, stage reporter.StageStats, best *reporter.StageStats) string {
+	switch {
+	case stage.TotalRequests == 0:
+		return fmt.Sprintf("no requests completed with %d users", stage.Users)
+	case tune.TargetP95 > 0 && stage.P95Latency > tune.TargetP95:
+		return fmt.Sprintf("P95 latency %s exceeded the target of %s with %d users", stage.P95Latency, tune.TargetP95, stage.Users)
+	case tune.MaxErrorRate > 0 && stage.ErrorRate > tune.MaxErrorRate:
+		return fmt.Sprintf("error rate %.2f%% exceeded %.2f%% with %d users", stage.ErrorRate, tune.MaxErrorRate, stage.Users)
+	case best != nil && stage.RequestsPerSecond < best.RequestsPerSecond*(1+tune.MinImprovementPercent()/100):
+		return fmt.Sprintf("RPS stopped improving with %d users (%.2f vs %.2f with %d users)",
+			stage.Users, stage.RequestsPerSecond, best.RequestsPerSecond, best.Users)
+	}
+	return ""
+}
@@ -0,0 +1,59 @@
+package runner
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAutotuneFindsTheKnee(t *testing.T) {
+	// fast up to 3 requests in flight, then ten times slower
+	var inFlight int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt64(&inFlight, 1)
+		defer atomic.AddInt64(&inFlight, -1)
+		if n > 3 {
+			time.Sleep(200 * time.Millisecond)
+		} else {
+			time.Sleep(20 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+
+	// a negative minImprovement never stops the search on RPS, leaving the
+	// latency target as the only limit
+	report, err := runConfig(t, `
+endpoints:
+  - name: items
+    url: `+server.URL+`/items
+    method: GET
+    concurrent:
+      autotune:
+        start: 1
+        step: 1
+        max: 8
+        stepDuration: 500ms
+        targetP95: 100ms
+        minImprovement: -100
+`, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := endpointResult(t, report, "items")
+	if result.Autotune == nil {
+		t.Fatal("no autotune result")
+	}
+	if result.Autotune.BestUsers != 3 {
+		t.Errorf("best users = %d, want 3 (steps %+v)", result.Autotune.BestUsers, result.Stages)
+	}
+	if len(result.Stages) != 4 {
+		t.Errorf("steps = %d, want the search to stop at the 4th", len(result.Stages))
+	}
+	if reason := result.Autotune.StopReason; !strings.Contains(reason, "exceeded the target of 100ms with 4 users") {
+		t.Errorf("stop reason = %q", reason)
+	}
+}
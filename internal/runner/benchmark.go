@@ -0,0 +1,103 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/JakubPluta/tmago/internal/config"
+	"github.com/JakubPluta/tmago/internal/reporter"
+)
+
+// runBenchmark runs the batch of the endpoint, its users sending the total
+// number of requests, once per benchmark cycle. Every cycle starts with its
+// warm-up requests, sent by the same users but neither recorded nor passed to
+// the sinks, and is recorded as a stage, so that the reporter can aggregate
+// the statistics of the cycles.
+func (r *Runner) runBenchmark(ctx context.Context, endpoint config.Endpoint, result *reporter.TestResult) error {
+	bench := *endpoint.Concurrent.Benchmark
+	users := int(endpoint.Concurrent.Users)
+	requestChan := make(chan reporter.RequestDetail, users*2)
+	errChan := make(chan error, users*2)
+	collected := make(chan error, 1)
+	go func() {
+		collected <- r.collectResults(endpoint, result, requestChan, errChan)
+	}()
+
+	result.IsConcurrent = true
+	result.ConcurrentUsers = users
+	result.TargetRPS = endpoint.Concurrent.TargetRPS
+	result.Benchmark = &reporter.BenchmarkResult{Cycles: int(bench.Cycles), Warmup: int(bench.Warmup)}
+	var nextID int64
+
+	for cycle := 1; cycle <= int(bench.Cycles) && ctx.Err() == nil; cycle++ {
+		if bench.Warmup > 0 {
+			r.logger.Info(fmt.Sprintf("%s: benchmark cycle %d, %d warm-up requests", endpoint.Name, cycle, bench.Warmup))
+			r.runBenchmarkBatch(ctx, endpoint, int(bench.Warmup), func() int { return 0 }, func(reporter.RequestDetail, error) {})
+		}
+
+		r.logger.Info(fmt.Sprintf("%s: benchmark cycle %d, %d requests", endpoint.Name, cycle, endpoint.Concurrent.Total))
+		var mu sync.Mutex
+		details := make([]reporter.RequestDetail, 0, int(endpoint.Concurrent.Total))
+		start := time.Now()
+		r.runBenchmarkBatch(ctx, endpoint, int(endpoint.Concurrent.Total),
+			func() int { return int(atomic.AddInt64(&nextID, 1)) },
+			func(detail reporter.RequestDetail, err error) {
+				detail.Stage = cycle
+				mu.Lock()
+				details = append(details, detail)
+				mu.Unlock()
+				requestChan <- detail
+				if err != nil {
+					errChan <- err
+				}
+			})
+
+		stage := reporter.StageStats{Stage: cycle, Users: users, Duration: time.Since(start)}
+		reporter.SummarizeStage(&stage, details)
+		result.Stages = append(result.Stages, stage)
+		r.logger.Info(fmt.Sprintf("%s: cycle %d, %.2f RPS, P95 %s, error rate %.2f%%",
+			endpoint.Name, cycle, stage.RequestsPerSecond, stage.P95Latency, stage.ErrorRate))
+	}
+
+	close(requestChan)
+	close(errChan)
+	return <-collected
+}
+
+// runBenchmarkBatch sends total requests of the endpoint split between its
+// users, like runConcurrent, numbering them with nextID and passing every
+// completed request to record.
+func (r *Runner) runBenchmarkBatch(ctx context.Context, endpoint config.Endpoint, total int,
+	nextID func() int, record func(reporter.RequestDetail, error)) {
+	users := int(endpoint.Concurrent.Users)
+	var rate *rateController
+	if target := endpoint.Concurrent.TargetRPS; target > 0 {
+		rate = newRateController(target, users, endpoint.Concurrent.Delay)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < users; i++ {
+		requests := total / users
+		if i < total%users {
+			requests++
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < requests && ctx.Err() == nil; j++ {
+				r.jitter(ctx, endpoint.Concurrent.Jitter)
+				detail, err := r.executeRequest(ctx, endpoint, nextID())
+				record(detail, err)
+				if err != nil {
+					continue
+				}
+
+				r.pause(ctx, endpoint.Concurrent, rate)
+			}
+		}()
+	}
+	wg.Wait()
+}
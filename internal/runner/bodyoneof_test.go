@@ -0,0 +1,53 @@
+package runner
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBodyOneOfMatchesAnyCandidate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/second":
+			w.Write([]byte(`{"items": [], "total": 0}`))
+		case "/nullbody":
+			w.Write([]byte(`null`))
+		default:
+			w.Write([]byte(`{"items": [1]}`))
+		}
+	}))
+	defer server.Close()
+
+	endpoint := func(name string) string {
+		return `
+  - name: ` + name + `
+    url: ` + server.URL + `/` + name + `
+    method: GET
+    expect:
+      bodyOneOf:
+        - name: populated
+          value: {"items": ["<any>"], "total": "<any>"}
+        - name: empty
+          value: '{"items": [], "total": 0}'
+        - name: none
+          value: null
+`
+	}
+	report, _ := runConfig(t, "endpoints:"+endpoint("second")+endpoint("nullbody")+endpoint("other"), Options{})
+
+	for _, name := range []string{"second", "nullbody"} {
+		if result := endpointResult(t, report, name); result.SuccessCount != 1 {
+			t.Errorf("%s failed: %v", name, result.RequestDetails[0].ValidationErrors)
+		}
+	}
+	other := endpointResult(t, report, "other")
+	if other.FailureCount != 1 {
+		t.Fatalf("other passed, want it to match no candidate")
+	}
+	want := "body matched none of the 3 bodyOneOf candidates: populated ($.total: missing), empty ($.items.0: unexpected and 1 more differences), none ($: expected null, got {\"items\":[1]})"
+	if got := other.RequestDetails[0].ValidationErrors; len(got) != 1 || got[0] != want {
+		t.Errorf("errors = %q, want %q", got, want)
+	}
+}
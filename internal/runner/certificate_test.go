@@ -0,0 +1,63 @@
+package runner
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpiredCertificateFailsWithTheExpiry(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		NotBefore:    time.Now().Add(-2 * time.Hour),
+		NotAfter:     time.Now().Add(-time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+	server.StartTLS()
+	defer server.Close()
+
+	report, err := runConfig(t, `
+endpoints:
+  - name: expired
+    url: `+server.URL+`
+    method: GET
+    expect:
+      tls:
+        minDaysToExpiry: 30
+`, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	details := endpointResult(t, report, "expired").RequestDetails
+	if len(details) != 1 {
+		t.Fatalf("got %d requests, want 1", len(details))
+	}
+	detail := details[0]
+	if detail.Success || !strings.HasPrefix(detail.ErrorMessage, "certificate of 127.0.0.1 expired on ") {
+		t.Errorf("error = %q, want the expiry", detail.ErrorMessage)
+	}
+	if detail.ErrorCategory != "certificate expired" {
+		t.Errorf("category = %q, want certificate expired", detail.ErrorCategory)
+	}
+}
@@ -0,0 +1,105 @@
+package runner
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/JakubPluta/tmago/internal/config"
+)
+
+// maxRedirects is the number of redirects followed before a request fails.
+const maxRedirects = 10
+
+// clientKey identifies the HTTP client settings of an endpoint.
+type clientKey struct {
+	timeout               time.Duration
+	dialTimeout           time.Duration
+	responseHeaderTimeout time.Duration
+	noRedirect            bool
+	serverName            string
+	disableResumption     bool
+}
+
+// clientFor returns the HTTP client for the endpoint's timeouts and redirect
+// policy. Clients are created on first use and shared by endpoints with the
+// same settings, so their connections are reused.
+func (r *Runner) clientFor(endpoint config.Endpoint) *http.Client {
+	key := clientKey{
+		timeout:               endpoint.RequestTimeout(),
+		dialTimeout:           endpoint.DialTimeout,
+		responseHeaderTimeout: endpoint.ResponseHeaderTimeout,
+		noRedirect:            expectsRedirect(endpoint.Expect),
+	}
+	if strings.HasPrefix(strings.ToLower(endpoint.URL), "https://") {
+		key.serverName = tlsServerName(endpoint)
+		key.disableResumption = endpoint.TLS != nil && endpoint.TLS.DisableResumption
+	}
+
+	r.clientsMu.Lock()
+	defer r.clientsMu.Unlock()
+	if client, ok := r.clients[key]; ok {
+		return client
+	}
+
+	client := &http.Client{Timeout: key.timeout}
+	if key.dialTimeout > 0 || key.responseHeaderTimeout > 0 || key.serverName != "" || key.disableResumption {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		if key.dialTimeout > 0 {
+			transport.DialContext = (&net.Dialer{Timeout: key.dialTimeout, KeepAlive: 30 * time.Second}).DialContext
+		}
+		transport.ResponseHeaderTimeout = key.responseHeaderTimeout
+		if key.serverName != "" || key.disableResumption {
+			transport.TLSClientConfig = &tls.Config{ServerName: key.serverName}
+		}
+		if key.disableResumption {
+			// without a session cache or tickets every handshake is a full
+			// one, and closing connections makes every request handshake
+			transport.TLSClientConfig.ClientSessionCache = nil
+			transport.TLSClientConfig.SessionTicketsDisabled = true
+			transport.DisableKeepAlives = true
+		}
+		client.Transport = transport
+	}
+	if key.noRedirect {
+		// return redirect responses instead of following them
+		client.CheckRedirect = func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	} else if allowed := r.config.AllowedTargets; allowed != nil {
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			if err := allowed.Check(req.URL.String()); err != nil {
+				return fmt.Errorf("redirect: %w", err)
+			}
+			// the limit of the default policy
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			return nil
+		}
+	}
+	r.clients[key] = client
+	return client
+}
+
+// tlsServerName returns the server name to verify for an endpoint overriding
+// its Host, without the port. Templated hosts are not known up front, so the
+// URL host is verified for them.
+func tlsServerName(endpoint config.Endpoint) string {
+	host := endpoint.Host
+	for k, v := range endpoint.Headers {
+		if host == "" && strings.EqualFold(k, "Host") {
+			host = v
+		}
+	}
+	if host == "" || strings.Contains(host, "{{") {
+		return ""
+	}
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		return name
+	}
+	return host
+}
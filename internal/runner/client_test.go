@@ -0,0 +1,74 @@
+package runner
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestAllowedTargetsCheckedOnRedirect(t *testing.T) {
+	var outside atomic.Int32
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		outside.Add(1)
+	}))
+	defer other.Close()
+
+	var sent atomic.Int32
+	allowed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent.Add(1)
+		http.Redirect(w, r, other.URL+"/elsewhere", http.StatusFound)
+	}))
+	defer allowed.Close()
+	host := strings.TrimPrefix(allowed.URL, "http://")
+
+	report, _ := runConfig(t, `
+allowedTargets:
+  hosts: ["`+host+`"]
+endpoints:
+  - name: redirected
+    url: `+allowed.URL+`
+    method: GET
+    retry:
+      transportRetries: 2
+`, Options{})
+
+	if outside.Load() != 0 {
+		t.Errorf("the redirect to a host outside allowedTargets was followed")
+	}
+	if sent.Load() != 1 {
+		t.Errorf("sent %d requests, want 1 as allowedTargets errors are not retried", sent.Load())
+	}
+	result := endpointResult(t, report, "redirected")
+	if len(result.Errors) == 0 || !strings.Contains(result.Errors[0], "not in allowedTargets") {
+		t.Errorf("errors = %v, want the allowedTargets error", result.Errors)
+	}
+}
+
+func TestAllowedTargetsFollowsAllowedRedirects(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer target.Close()
+	u, _ := url.Parse(target.URL)
+	// the same server under another allowed name
+	redirect := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://localhost:"+u.Port()+"/", http.StatusFound)
+	}))
+	defer redirect.Close()
+
+	report, err := runConfig(t, `
+allowedTargets:
+  hosts: [127.0.0.1, localhost]
+endpoints:
+  - name: redirected
+    url: `+redirect.URL+`
+    method: GET
+`, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := endpointResult(t, report, "redirected"); result.SuccessCount != 1 {
+		t.Errorf("errors = %v, want the allowed redirect followed", result.Errors)
+	}
+}
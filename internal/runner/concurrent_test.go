@@ -0,0 +1,131 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/JakubPluta/tmago/internal/config"
+	"github.com/JakubPluta/tmago/internal/reporter"
+)
+
+// slowSink takes a while to record every request, so the users of a
+// concurrent endpoint fill the channel buffers and wait for the collector.
+type slowSink struct{}
+
+func (slowSink) Record(reporter.RequestDetail, string) { time.Sleep(time.Millisecond) }
+
+func TestRunConcurrentUnderBackpressure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	cfg := loadConfig(t, `
+endpoints:
+  - name: busy
+    url: `+server.URL+`
+    method: GET
+    concurrent:
+      users: 4
+      total: 200
+`)
+	r, err := NewRunner(cfg, Options{NoFileLog: true, Sinks: []ResultSink{slowSink{}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- r.Run(context.Background()) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("run did not finish with a slow collector")
+	}
+	if result := endpointResult(t, r.reporter.Report(), "busy"); result.SuccessCount != 200 {
+		t.Errorf("collected %d successful requests, want 200", result.SuccessCount)
+	}
+}
+
+// BenchmarkCollectResults sends the requests of 10 users through the results
+// channel to collectResults, buffered by users*2 as in runConcurrent or by the
+// total number of requests. The buffer sized to the total allocates memory
+// growing with the run, while the small buffer is as fast.
+func BenchmarkCollectResults(b *testing.B) {
+	const users, total = 10, 100_000
+	r, err := NewRunner(&config.Config{}, Options{NoFileLog: true})
+	if err != nil {
+		b.Fatal(err)
+	}
+	endpoint := config.Endpoint{Name: "bench"}
+	for _, buffer := range []int{users * 2, total} {
+		b.Run(fmt.Sprintf("buffer=%d", buffer), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				result := newResult(endpoint)
+				result.RequestDetails = make([]reporter.RequestDetail, 0, total)
+				requestChan := make(chan reporter.RequestDetail, buffer)
+				errChan := make(chan error, buffer)
+
+				var wg sync.WaitGroup
+				for u := 0; u < users; u++ {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						for j := 0; j < total/users; j++ {
+							requestChan <- reporter.RequestDetail{ID: j, StatusCode: 200, Success: true}
+						}
+					}()
+				}
+				go func() {
+					wg.Wait()
+					close(requestChan)
+					close(errChan)
+				}()
+				if err := r.collectResults(endpoint, &result, requestChan, errChan); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestConcurrentRequestIDs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	// 10 requests do not divide evenly between 3 users
+	config := `
+endpoints:
+  - name: uneven
+    url: ` + server.URL + `
+    method: GET
+    concurrent:
+      users: 3
+      total: 10
+`
+	for _, sorted := range []bool{false, true} {
+		report, err := runConfig(t, config, Options{SortRequests: sorted})
+		if err != nil {
+			t.Fatal(err)
+		}
+		details := endpointResult(t, report, "uneven").RequestDetails
+		if len(details) != 10 {
+			t.Fatalf("got %d requests, want 10", len(details))
+		}
+		seen := make(map[int]bool)
+		for i, detail := range details {
+			if detail.ID < 1 || detail.ID > 10 || seen[detail.ID] {
+				t.Fatalf("request ID %d is out of range or repeated", detail.ID)
+			}
+			seen[detail.ID] = true
+			if sorted && detail.ID != i+1 {
+				t.Errorf("sorted requests: ID %d at position %d", detail.ID, i+1)
+			}
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+
+	"github.com/JakubPluta/tmago/internal/config"
+	"github.com/JakubPluta/tmago/internal/validator"
+)
+
+// maxDistinctErrors is the number of distinct worker errors kept per endpoint.
+const maxDistinctErrors = 10
+
+// errorSet aggregates the errors of concurrent workers. Identical errors are
+// counted once, and only the first maxDistinctErrors distinct errors are kept,
+// so thousands of failed requests produce a bounded error.
+type errorSet struct {
+	errs    []error
+	counts  map[string]int
+	dropped int
+}
+
+// add records err, ignoring nil errors.
+func (s *errorSet) add(err error) {
+	if err == nil {
+		return
+	}
+	if s.counts == nil {
+		s.counts = make(map[string]int)
+	}
+
+	msg := err.Error()
+	if _, ok := s.counts[msg]; !ok {
+		if len(s.errs) == maxDistinctErrors {
+			s.dropped++
+			return
+		}
+		s.errs = append(s.errs, err)
+	}
+	s.counts[msg]++
+}
+
+// err returns the recorded errors joined together, with the number of times
+// each occurred, or nil when none was recorded.
+func (s *errorSet) err() error {
+	if len(s.errs) == 0 {
+		return nil
+	}
+
+	errs := make([]error, 0, len(s.errs)+1)
+	for _, err := range s.errs {
+		if n := s.counts[err.Error()]; n > 1 {
+			err = fmt.Errorf("%w (%d times)", err, n)
+		}
+		errs = append(errs, err)
+	}
+	if s.dropped > 0 {
+		errs = append(errs, fmt.Errorf("and %d more errors", s.dropped))
+	}
+	return errors.Join(errs...)
+}
+
+// errorCategory classifies the transport error of a request that got no
+// response for the report, where requests failing for the same reason are
+// counted together across endpoints. Unreachable endpoints are classified by
+// validator.UnreachableReason and expired server certificates as "certificate
+// expired"; other errors by their message without the method and URL of the
+// request, e.g. another TLS certificate error.
+func errorCategory(err error) string {
+	if reason, ok := validator.UnreachableReason(err); ok {
+		return reason
+	}
+	if _, ok := validator.CertificateExpired(err); ok {
+		return "certificate expired"
+	}
+	if errors.Is(err, context.Canceled) {
+		return "canceled"
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err.Error()
+	}
+	return err.Error()
+}
+
+// isTransportError reports whether err is a failure to exchange a request with
+// the server, e.g. a refused or reset connection or a timeout, which
+// transportRetries retry on a new connection. Errors building the request,
+// such as a missing captured variable or an invalid URL, and requests outside
+// the allowed targets would fail the same way again and are not.
+func isTransportError(err error) bool {
+	if errors.Is(err, config.ErrTargetNotAllowed) {
+		return false
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Op != "parse"
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -0,0 +1,66 @@
+package runner
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestErrorSetIsBounded(t *testing.T) {
+	var errs errorSet
+	for i := 0; i < 5000; i++ {
+		errs.add(fmt.Errorf("request failed: error %d", i%(maxDistinctErrors+5)))
+	}
+	errs.add(nil)
+
+	lines := strings.Split(errs.err().Error(), "\n")
+	if len(lines) != maxDistinctErrors+1 {
+		t.Fatalf("lines = %d, want %d distinct errors and a summary: %q", len(lines), maxDistinctErrors, lines)
+	}
+	if lines[0] != "request failed: error 0 (334 times)" {
+		t.Errorf("first line = %q", lines[0])
+	}
+	if last := lines[len(lines)-1]; last != "and 1665 more errors" {
+		t.Errorf("last line = %q", last)
+	}
+
+	var none errorSet
+	if err := none.err(); err != nil {
+		t.Errorf("empty set err = %v", err)
+	}
+}
+
+func TestConcurrentFailuresProduceABoundedError(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed := listener.Addr().String()
+	listener.Close()
+
+	report, err := runConfig(t, `
+endpoints:
+  - name: down
+    url: http://`+closed+`/items
+    method: GET
+    concurrent:
+      users: 4
+      total: 500
+`, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := endpointResult(t, report, "down")
+	if result.FailureCount != 500 {
+		t.Fatalf("failures = %d, want 500", result.FailureCount)
+	}
+	var size int
+	for _, e := range result.Errors {
+		size += len(e)
+	}
+	if size > 4096 {
+		t.Errorf("errors take %d bytes for 500 failures: %.200q", size, result.Errors)
+	}
+}
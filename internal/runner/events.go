@@ -0,0 +1,79 @@
+package runner
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/JakubPluta/tmago/internal/reporter"
+)
+
+// requestEvent is the newline-delimited JSON representation of a completed request.
+type requestEvent struct {
+	Endpoint   string    `json:"endpoint"`
+	ID         int       `json:"id"`
+	Timestamp  time.Time `json:"timestamp"`
+	Status     int       `json:"status"`
+	DurationMs float64   `json:"durationMs"`
+	Size       int64     `json:"size"`
+	Success    bool      `json:"success"`
+	Errors     []string  `json:"errors,omitempty"`
+	// kinds of the failed checks, e.g. "status" or "header"
+	FailureKinds []string `json:"failureKinds,omitempty"`
+	// retries made before the final attempt
+	TransportRetries int    `json:"transportRetries,omitempty"`
+	StatusRetries    int    `json:"statusRetries,omitempty"`
+	MatchedVariant   string `json:"matchedVariant,omitempty"`
+	// target that served the request and why the primary failed, for
+	// endpoints with a fallback
+	ServedBy     string `json:"servedBy,omitempty"`
+	PrimaryError string `json:"primaryError,omitempty"`
+	// duration of the TLS handshake, for requests that made one
+	TLSHandshakeMs float64 `json:"tlsHandshakeMs,omitempty"`
+	// wait for an in-flight slot, included in the duration
+	QueueWaitMs float64 `json:"queueWaitMs,omitempty"`
+}
+
+// EventWriter writes one JSON object per completed request, suitable for
+// piping into jq or a log collector. It is safe for concurrent use.
+type EventWriter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewEventWriter creates an EventWriter writing to w.
+func NewEventWriter(w io.Writer) *EventWriter {
+	return &EventWriter{enc: json.NewEncoder(w)}
+}
+
+// Write emits the given request detail as a single JSON line.
+func (e *EventWriter) Write(endpoint string, detail reporter.RequestDetail) error {
+	event := requestEvent{
+		Endpoint:   endpoint,
+		ID:         detail.ID,
+		Timestamp:  detail.Timestamp,
+		Status:     detail.StatusCode,
+		DurationMs: float64(detail.Duration) / float64(time.Millisecond),
+		Size:       detail.ResponseSize,
+		Success:    detail.Success,
+	}
+	event.TransportRetries = detail.TransportRetries
+	event.StatusRetries = detail.StatusRetries
+	event.MatchedVariant = detail.MatchedVariant
+	event.ServedBy = detail.ServedBy
+	event.PrimaryError = detail.PrimaryError
+	event.TLSHandshakeMs = float64(detail.TLSHandshake) / float64(time.Millisecond)
+	event.QueueWaitMs = float64(detail.QueueWait) / float64(time.Millisecond)
+	if detail.ErrorMessage != "" {
+		event.Errors = append(event.Errors, detail.ErrorMessage)
+	}
+	event.Errors = append(event.Errors, detail.ValidationErrors...)
+	for _, f := range detail.Failures {
+		event.FailureKinds = append(event.FailureKinds, f.Kind)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.enc.Encode(event)
+}
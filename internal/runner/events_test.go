@@ -0,0 +1,70 @@
+package runner
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEventsAreOneJSONObjectPerRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	var events bytes.Buffer
+	_, err := runConfig(t, `
+endpoints:
+  - name: list
+    url: `+server.URL+`/list
+    method: GET
+    concurrent:
+      users: 3
+      total: 6
+  - name: missing
+    url: `+server.URL+`/missing
+    method: GET
+`, Options{Events: &events})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counts := map[string]int{}
+	ids := map[int]bool{}
+	scanner := bufio.NewScanner(&events)
+	for scanner.Scan() {
+		var event map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", scanner.Text(), err)
+		}
+		endpoint, _ := event["endpoint"].(string)
+		counts[endpoint]++
+		switch endpoint {
+		case "list":
+			ids[int(event["id"].(float64))] = true
+			if event["status"] != 200.0 || event["success"] != true {
+				t.Errorf("list event %s, want a successful 200", scanner.Text())
+			}
+		case "missing":
+			errs, _ := event["errors"].([]interface{})
+			if event["status"] != 404.0 || event["success"] != false || len(errs) == 0 {
+				t.Errorf("missing event %s, want a failed 404 with its errors", scanner.Text())
+			}
+		}
+		if _, ok := event["durationMs"].(float64); !ok {
+			t.Errorf("event without durationMs: %s", scanner.Text())
+		}
+	}
+	if counts["list"] != 6 || counts["missing"] != 1 || len(counts) != 2 {
+		t.Errorf("events per endpoint = %v, want 6 list and 1 missing", counts)
+	}
+	if len(ids) != 6 {
+		t.Errorf("list events have %d distinct IDs, want 6", len(ids))
+	}
+}
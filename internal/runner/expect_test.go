@@ -0,0 +1,64 @@
+package runner
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestExpectedValuesReferenceCapturedVariables(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users":
+			w.Write([]byte(`{"id": 42, "name": "Ann"}`))
+		case "/orders/same":
+			w.Write([]byte(`{"userId": 42, "owner": "Ann - 42"}`))
+		default:
+			w.Write([]byte(`{"userId": "42", "owner": "Bob"}`))
+		}
+	}))
+	defer server.Close()
+
+	report, err := runConfig(t, `
+endpoints:
+  - name: create
+    url: `+server.URL+`/users
+    method: POST
+    capture:
+      - name: userId
+        path: id
+      - name: name
+        path: name
+  - name: same
+    url: `+server.URL+`/orders/same
+    method: GET
+    expect:
+      values:
+        - path: userId
+          value: "{{captured.userId}}"
+        - path: owner
+          value: "{{captured.name}} - {{captured.userId}}"
+  - name: other
+    url: `+server.URL+`/orders/other
+    method: GET
+    expect:
+      values:
+        - path: userId
+          value: "{{captured.userId}}"
+`, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result := endpointResult(t, report, "same"); result.SuccessCount != 1 {
+		t.Errorf("same failed: %v", result.RequestDetails[0].ValidationErrors)
+	}
+	// a whole reference keeps the number type of the captured value
+	other := endpointResult(t, report, "other")
+	if other.FailureCount != 1 {
+		t.Fatal("other passed with the string \"42\" for the captured number 42")
+	}
+	if errs := other.RequestDetails[0].ValidationErrors; len(errs) != 1 {
+		t.Errorf("errors = %q, want the type mismatch", errs)
+	}
+}
@@ -0,0 +1,76 @@
+package runner
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFallbackServesFailedRequests(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer primary.Close()
+	fallback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.Header.Get("X-Region") != "eu" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer fallback.Close()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed := listener.Addr().String()
+	listener.Close()
+
+	endpoint := func(name, url string) string {
+		return `
+  - name: ` + name + `
+    url: ` + url + `
+    method: GET
+    expect:
+      status: 200
+    fallback:
+      url: ` + fallback.URL + `/items
+      method: PUT
+      headers:
+        X-Region: eu`
+	}
+	report, err := runConfig(t, "endpoints:"+
+		endpoint("up", primary.URL+"/up")+
+		endpoint("down", primary.URL+"/down")+
+		endpoint("refused", "http://"+closed+"/items")+`
+    concurrent:
+      users: 1
+      total: 3
+`, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	up := endpointResult(t, report, "up")
+	if detail := up.RequestDetails[0]; !detail.Success || detail.ServedBy != "primary" || up.FallbackCount != 0 {
+		t.Errorf("up: success %v, served by %q, %d fallbacks", detail.Success, detail.ServedBy, up.FallbackCount)
+	}
+
+	down := endpointResult(t, report, "down").RequestDetails[0]
+	if !down.Success || down.ServedBy != "fallback" || down.StatusCode != 200 {
+		t.Errorf("down: success %v, served by %q, status %d, errors %v", down.Success, down.ServedBy, down.StatusCode, down.ValidationErrors)
+	}
+	if want := "expected status code 200, got 503"; down.PrimaryError != want {
+		t.Errorf("down: primary error = %q, want %q", down.PrimaryError, want)
+	}
+
+	refused := endpointResult(t, report, "refused")
+	if refused.SuccessCount != 3 || refused.FallbackCount != 3 {
+		t.Errorf("refused: %d successes, %d fallbacks, want 3 and 3", refused.SuccessCount, refused.FallbackCount)
+	}
+	if detail := refused.RequestDetails[0]; detail.PrimaryError == "" || detail.ErrorMessage != "" {
+		t.Errorf("refused: primary error %q, error %q", detail.PrimaryError, detail.ErrorMessage)
+	}
+}
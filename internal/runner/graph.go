@@ -0,0 +1,68 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/JakubPluta/tmago/internal/config"
+)
+
+// runGraph runs the endpoints as a dependency graph: every endpoint starts as
+// soon as the endpoints it depends on finished, so independent branches run in
+// parallel. An endpoint referencing a variable captured by an earlier endpoint
+// depends on it without listing it in dependsOn. An endpoint is skipped, with
+// an error in its result, when an endpoint it depends on failed or was skipped
+// itself, as its requests would lack the data set up by the dependency. The
+// config guarantees unique names and no cycles.
+func (r *Runner) runGraph(ctx context.Context, endpoints []config.Endpoint) {
+	done := make(map[string]chan struct{}, len(endpoints))
+	for _, endpoint := range endpoints {
+		done[endpoint.Name] = make(chan struct{})
+	}
+	graph := r.config.DependencyGraph()
+	var mu sync.Mutex
+	failed := make(map[string]bool, len(endpoints))
+
+	var wg sync.WaitGroup
+	for _, endpoint := range endpoints {
+		wg.Add(1)
+		go func(endpoint config.Endpoint) {
+			defer wg.Done()
+			defer close(done[endpoint.Name])
+
+			var failedDeps []string
+			for _, dep := range graph[endpoint.Name] {
+				<-done[dep]
+				mu.Lock()
+				if failed[dep] {
+					failedDeps = append(failedDeps, dep)
+				}
+				mu.Unlock()
+			}
+
+			ok := false
+			if len(failedDeps) > 0 {
+				r.skipEndpoint(endpoint, failedDeps)
+			} else {
+				result := r.runEndpoint(ctx, endpoint)
+				ok = result.FailureCount == 0 && len(result.Errors) == 0
+			}
+			mu.Lock()
+			failed[endpoint.Name] = !ok
+			mu.Unlock()
+		}(endpoint)
+	}
+	wg.Wait()
+}
+
+// skipEndpoint adds a result without requests to the report for an endpoint
+// skipped because the endpoints it depends on failed.
+func (r *Runner) skipEndpoint(endpoint config.Endpoint, failedDeps []string) {
+	result := newResult(endpoint)
+	err := fmt.Errorf("skipped: depends on %s, which failed", strings.Join(failedDeps, ", "))
+	r.logger.RequestFailed(-1, endpoint.Name, err)
+	result.Errors = append(result.Errors, err.Error())
+	r.finishResult(&result, 0)
+}
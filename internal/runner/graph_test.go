@@ -0,0 +1,57 @@
+package runner
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRunGraphWaitsForCapturedVariables(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		if r.URL.Path == "/create" {
+			// a slow response loses the race without the implicit dependency
+			time.Sleep(50 * time.Millisecond)
+			w.Write([]byte(`{"id": 42}`))
+		}
+	}))
+	defer server.Close()
+
+	report, err := runConfig(t, `
+endpoints:
+  - name: create
+    url: `+server.URL+`/create
+    method: POST
+    capture:
+      - name: id
+        path: id
+  - name: get
+    url: `+server.URL+`/items/{{captured.id}}
+    method: GET
+  - name: other
+    url: `+server.URL+`/other
+    method: GET
+    dependsOn: [create]
+`, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := endpointResult(t, report, "get"); result.SuccessCount != 1 {
+		t.Errorf("get failed: %v", result.Errors)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	found := false
+	for _, path := range paths {
+		found = found || path == "/items/42"
+	}
+	if !found {
+		t.Errorf("requested %v, want /items/42 after the capture", paths)
+	}
+}
@@ -0,0 +1,66 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/JakubPluta/tmago/internal/config"
+)
+
+// ErrHealthCheckFailed is returned by Run when the health check failed and
+// the run was aborted.
+var ErrHealthCheckFailed = errors.New("health check failed")
+
+// ErrSkipped is returned by Run, along with ErrHealthCheckFailed, when the
+// health check failed with onFailure: skip and no endpoint ran.
+var ErrSkipped = errors.New("run skipped")
+
+// checkHealth sends the health check request of the config, if any, and
+// returns an error aborting the run when the target is not healthy.
+func (r *Runner) checkHealth(ctx context.Context) error {
+	check := r.config.HealthCheck
+	if check == nil {
+		return nil
+	}
+
+	err := r.probe(ctx, *check)
+	if err == nil {
+		r.logger.Info(fmt.Sprintf("Health check %s passed", r.config.Redact.Text(check.URL)))
+		return nil
+	}
+	err = fmt.Errorf("%w: %s", ErrHealthCheckFailed, r.config.Redact.Text(err.Error()))
+	if check.OnFailure == config.HealthCheckSkip {
+		err = fmt.Errorf("%w: %w", ErrSkipped, err)
+	}
+	r.logger.Warn(err.Error())
+	return err
+}
+
+// probe sends the health check request and checks its status code.
+func (r *Runner) probe(ctx context.Context, check config.HealthCheck) error {
+	ctx, cancel := context.WithTimeout(ctx, check.RequestTimeout())
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, check.URL, nil)
+	if err != nil {
+		return err
+	}
+	for key, value := range check.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode != check.ExpectedStatus() {
+		return fmt.Errorf("%s returned status %d, expected %d", check.URL, resp.StatusCode, check.ExpectedStatus())
+	}
+	return nil
+}
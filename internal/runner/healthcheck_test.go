@@ -0,0 +1,60 @@
+package runner
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+func TestHealthCheckGatesTheRun(t *testing.T) {
+	var requests int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/up":
+		case "/down":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			atomic.AddInt64(&requests, 1)
+		}
+	}))
+	defer server.Close()
+
+	config := func(path, onFailure string) string {
+		return `
+healthCheck:
+  url: ` + server.URL + path + `
+  onFailure: ` + onFailure + `
+endpoints:
+  - name: items
+    url: ` + server.URL + `/items
+    method: GET
+`
+	}
+
+	_, err := runConfig(t, config("/down", "skip"), Options{})
+	if !errors.Is(err, ErrSkipped) || !errors.Is(err, ErrHealthCheckFailed) {
+		t.Errorf("skip: err = %v, want a skipped run", err)
+	}
+	if _, statErr := os.Stat(filepath.Join(ReportsDir, "report.json")); !os.IsNotExist(statErr) {
+		t.Errorf("skip: a report was written")
+	}
+
+	_, err = runConfig(t, config("/down", "fail"), Options{})
+	if !errors.Is(err, ErrHealthCheckFailed) || errors.Is(err, ErrSkipped) {
+		t.Errorf("fail: err = %v, want a failed run that is not skipped", err)
+	}
+	if n := atomic.LoadInt64(&requests); n != 0 {
+		t.Errorf("%d endpoint requests were sent after a failed health check", n)
+	}
+
+	if _, err := runConfig(t, config("/up", "skip"), Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt64(&requests); n != 1 {
+		t.Errorf("%d endpoint requests after a passed health check, want 1", n)
+	}
+}
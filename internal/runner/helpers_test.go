@@ -0,0 +1,74 @@
+package runner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/JakubPluta/tmago/internal/config"
+	"github.com/JakubPluta/tmago/internal/reporter"
+)
+
+// workDir makes a temporary directory with a reports directory the working
+// directory for the rest of the test, and returns it.
+func workDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	if err := os.Mkdir(ReportsDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// loadConfig writes content to a config file in a temporary directory, which
+// becomes the working directory receiving the reports, and loads it.
+func loadConfig(t *testing.T, content string) *config.Config {
+	t.Helper()
+	path := filepath.Join(workDir(t), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+// runConfig runs the config in content, returning the report and the error of
+// the run.
+func runConfig(t *testing.T, content string, opts Options) (reporter.Report, error) {
+	t.Helper()
+	opts.NoFileLog = true
+	r, err := NewRunner(loadConfig(t, content), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.Run(context.Background())
+	return r.reporter.Report(), err
+}
+
+// endpointResult returns the result of the named endpoint in the report.
+func endpointResult(t *testing.T, report reporter.Report, name string) reporter.TestResult {
+	t.Helper()
+	for _, result := range report.TestResults {
+		if result.EndpointName == name {
+			return result
+		}
+	}
+	t.Fatalf("no result for endpoint %s", name)
+	return reporter.TestResult{}
+}
@@ -0,0 +1,82 @@
+package runner
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestHostOverride(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"host": "` + r.Host + `"}`))
+	}))
+	defer server.Close()
+
+	report, err := runConfig(t, `
+endpoints:
+  - name: field
+    url: `+server.URL+`/health
+    method: GET
+    host: api.example.com
+    expect:
+      values:
+        - path: host
+          value: api.example.com
+  - name: header
+    url: `+server.URL+`/health
+    method: GET
+    headers:
+      Host: admin.example.com:8443
+    expect:
+      values:
+        - path: host
+          value: admin.example.com:8443
+`, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range map[string]string{"field": "api.example.com", "header": "admin.example.com:8443"} {
+		detail := endpointResult(t, report, name).RequestDetails[0]
+		if !detail.Success {
+			t.Errorf("%s: %v", name, detail.ValidationErrors)
+		}
+		if got := detail.Request.Headers["Host"]; got != want {
+			t.Errorf("%s: reported Host = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestHostOverrideSetsTheTLSServerName(t *testing.T) {
+	var mu sync.Mutex
+	var serverNames []string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+		mu.Lock()
+		serverNames = append(serverNames, hello.ServerName)
+		mu.Unlock()
+		return nil, nil
+	}}
+	server.StartTLS()
+	defer server.Close()
+
+	// the test certificate is not trusted, so only the handshake is checked
+	if _, err := runConfig(t, `
+endpoints:
+  - name: tls
+    url: `+server.URL+`/health
+    method: GET
+    headers:
+      host: api.example.com:443
+`, Options{}); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(serverNames) == 0 || serverNames[0] != "api.example.com" {
+		t.Errorf("server names = %q, want api.example.com", serverNames)
+	}
+}
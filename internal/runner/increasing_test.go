@@ -0,0 +1,57 @@
+package runner
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestIncreasingCapture(t *testing.T) {
+	ids := []string{"1", "2", `"5"`, "5", "4", `"n/a"`}
+	var next int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"id": %s}`, ids[atomic.AddInt64(&next, 1)-1])
+	}))
+	defer server.Close()
+
+	report, err := runConfig(t, `
+endpoints:
+  - name: create
+    url: `+server.URL+`/items
+    method: POST
+    capture:
+      - name: itemId
+        path: id
+        increasing: true
+    concurrent:
+      users: 1
+      total: 6
+`, Options{SortRequests: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"",
+		"",
+		"",
+		"capture itemId: value 5 is not greater than the previous value 5",
+		"capture itemId: value 4 is not greater than the previous value 5",
+		"capture itemId: value n/a is not a number",
+	}
+	details := endpointResult(t, report, "create").RequestDetails
+	if len(details) != len(want) {
+		t.Fatalf("got %d requests, want %d", len(details), len(want))
+	}
+	for i, detail := range details {
+		var got string
+		if len(detail.ValidationErrors) > 0 {
+			got = detail.ValidationErrors[0]
+		}
+		if got != want[i] || detail.Success != (want[i] == "") {
+			t.Errorf("request %d: success %v, errors %q, want %q", i+1, detail.Success, detail.ValidationErrors, want[i])
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package runner
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/JakubPluta/tmago/internal/config"
+	"github.com/JakubPluta/tmago/internal/reporter"
+)
+
+// acquireSlot waits for one of the maxInFlight slots of the endpoint, shared
+// by all its users, and returns how long it waited, zero when a slot was
+// free, and the function releasing the slot. Endpoints without maxInFlight
+// get a slot at once. It fails when the context is done before a slot frees
+// up.
+func (r *Runner) acquireSlot(ctx context.Context, endpoint config.Endpoint) (time.Duration, func(), error) {
+	max := int(endpoint.Concurrent.MaxInFlight)
+	if max <= 0 {
+		return 0, func() {}, nil
+	}
+
+	r.slotsMu.Lock()
+	slots, ok := r.slots[endpoint.Name]
+	if !ok {
+		slots = make(chan struct{}, max)
+		r.slots[endpoint.Name] = slots
+	}
+	r.slotsMu.Unlock()
+
+	release := func() { <-slots }
+	select {
+	case slots <- struct{}{}:
+		return 0, release, nil
+	default:
+	}
+
+	start := time.Now()
+	select {
+	case slots <- struct{}{}:
+		return time.Since(start), release, nil
+	case <-ctx.Done():
+		return time.Since(start), func() {}, ctx.Err()
+	}
+}
+
+// attemptInSlot makes a single request to the endpoint while holding one of
+// its in-flight slots, returning the wait for the slot, the response and the
+// duration of the request. The slot is released even when the request
+// panics, so a recovered panic does not leak it.
+func (r *Runner) attemptInSlot(ctx context.Context, endpoint config.Endpoint, detail *reporter.RequestDetail) (time.Duration, *http.Response, []byte, time.Duration, error) {
+	wait, release, err := r.acquireSlot(ctx, endpoint)
+	if err != nil {
+		return wait, nil, nil, 0, err
+	}
+	defer release()
+
+	resp, body, duration, err := r.makeRequest(ctx, endpoint, detail)
+	return wait, resp, body, duration, err
+}
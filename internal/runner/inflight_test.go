@@ -0,0 +1,94 @@
+package runner
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/JakubPluta/tmago/internal/config"
+	"github.com/JakubPluta/tmago/internal/reporter"
+)
+
+func TestQueueWaitIsThatOfTheLastAttempt(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	endpoint := config.Endpoint{
+		Name:       "limited",
+		URL:        server.URL,
+		Method:     http.MethodGet,
+		Retry:      config.RetryConfig{StatusRetries: 1},
+		Concurrent: config.ConcurrentConfig{MaxInFlight: 1},
+	}
+	r, err := NewRunner(&config.Config{Endpoints: []config.Endpoint{endpoint}}, Options{NoFileLog: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// another request holds the only slot while the first attempt queues
+	_, release, err := r.acquireSlot(context.Background(), endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const held = 50 * time.Millisecond
+	time.AfterFunc(held, release)
+
+	var detail reporter.RequestDetail
+	resp, _, _, err := r.send(context.Background(), endpoint, &detail)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || detail.StatusRetries != 1 {
+		t.Fatalf("status %d after %d retries, want 200 after 1", resp.StatusCode, detail.StatusRetries)
+	}
+	if detail.QueueWait >= held {
+		t.Errorf("queue wait %s includes the wait of the first attempt, want that of the retry", detail.QueueWait)
+	}
+}
+
+func TestInFlightRequestsStayUnderTheCap(t *testing.T) {
+	var inFlight, highest atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			seen := highest.Load()
+			if n <= seen || highest.CompareAndSwap(seen, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	report, err := runConfig(t, `
+endpoints:
+  - name: limited
+    url: `+server.URL+`/items
+    method: GET
+    concurrent:
+      users: 40
+      total: 200
+      maxInFlight: 3
+`, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result := endpointResult(t, report, "limited"); result.SuccessCount != 200 {
+		t.Fatalf("%d of 200 requests succeeded", result.SuccessCount)
+	}
+	if n := highest.Load(); n > 3 {
+		t.Errorf("%d requests were in flight at once, want at most 3", n)
+	} else if n < 3 {
+		t.Errorf("at most %d requests were in flight at once, want the cap of 3 to be reached", n)
+	}
+}
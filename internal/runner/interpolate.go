@@ -0,0 +1,118 @@
+package runner
+
+import (
+	"fmt"
+	"math/rand"
+	"regexp"
+	"sync"
+)
+
+// placeholder matches a template placeholder in a request field, e.g.
+// {{captured.userId}} or {{random.uuid}}.
+var placeholder = regexp.MustCompile(`\{\{\s*(captured|random)\.([A-Za-z0-9_\-]+)\s*\}\}`)
+
+const alphanumeric = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// Random is a seeded source of random test data that is safe for concurrent use.
+// Runs using the same seed generate the same sequence of values.
+type Random struct {
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+// NewRandom creates a random data source seeded with seed.
+func NewRandom(seed int64) *Random {
+	return &Random{rnd: rand.New(rand.NewSource(seed))}
+}
+
+// Intn returns a random number in [0, n).
+func (r *Random) Intn(n int) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rnd.Intn(n)
+}
+
+// Float64 returns a random number in [0.0, 1.0).
+func (r *Random) Float64() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rnd.Float64()
+}
+
+// ExpFloat64 returns an exponentially distributed number with a mean of 1.
+func (r *Random) ExpFloat64() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rnd.ExpFloat64()
+}
+
+// String returns a random alphanumeric string of length n.
+func (r *Random) String(n int) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = alphanumeric[r.rnd.Intn(len(alphanumeric))]
+	}
+	return string(b)
+}
+
+// UUID returns a random version 4 UUID.
+func (r *Random) UUID() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b := make([]byte, 16)
+	r.rnd.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// generate returns a random value for the named generator.
+func (r *Random) generate(name string) (string, error) {
+	switch name {
+	case "int":
+		return fmt.Sprintf("%d", r.Intn(1000000)), nil
+	case "float":
+		return fmt.Sprintf("%f", r.Float64()), nil
+	case "string":
+		return r.String(12), nil
+	case "uuid":
+		return r.UUID(), nil
+	case "email":
+		return fmt.Sprintf("%s@example.com", r.String(10)), nil
+	default:
+		return "", fmt.Errorf("unknown random generator %s", name)
+	}
+}
+
+// interpolate replaces {{captured.<name>}} and {{random.<generator>}}
+// placeholders in s. It returns an error for unknown generators and for
+// variables that have not been captured.
+func (r *Runner) interpolate(s string) (string, error) {
+	var firstErr error
+	out := placeholder.ReplaceAllStringFunc(s, func(ref string) string {
+		m := placeholder.FindStringSubmatch(ref)
+		switch m[1] {
+		case "captured":
+			val, ok := r.vars.Get(m[2])
+			if !ok {
+				if firstErr == nil {
+					firstErr = errMissingVariable(m[2])
+				}
+				return ref
+			}
+			return fmt.Sprintf("%v", val)
+		default:
+			val, err := r.random.generate(m[2])
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return ref
+			}
+			return val
+		}
+	})
+	return out, firstErr
+}
@@ -0,0 +1,67 @@
+package runner
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// arrivalSpread returns the coefficient of variation of the times between
+// the arrivals of consecutive requests: 0 when they are evenly spaced, high
+// when they come in bursts.
+func arrivalSpread(arrivals []time.Time) float64 {
+	sort.Slice(arrivals, func(i, j int) bool { return arrivals[i].Before(arrivals[j]) })
+	gaps := make([]float64, len(arrivals)-1)
+	var mean float64
+	for i := range gaps {
+		gaps[i] = float64(arrivals[i+1].Sub(arrivals[i]))
+		mean += gaps[i] / float64(len(gaps))
+	}
+	var variance float64
+	for _, gap := range gaps {
+		variance += (gap - mean) * (gap - mean) / float64(len(gaps))
+	}
+	return math.Sqrt(variance) / mean
+}
+
+func TestJitterSpreadsOutArrivals(t *testing.T) {
+	var mu sync.Mutex
+	var arrivals []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		arrivals = append(arrivals, time.Now())
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	spread := func(jitter string) float64 {
+		arrivals = nil
+		_, err := runConfig(t, `
+endpoints:
+  - name: items
+    url: `+server.URL+`/items
+    method: GET
+    concurrent:
+      users: 5
+      total: 50
+      delay: 100ms
+      jitter: `+jitter+`
+`, Options{Seed: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		return arrivalSpread(arrivals)
+	}
+
+	lockstep, jittered := spread("0s"), spread("100ms")
+	t.Logf("spread of inter-arrival times: %.2f in lockstep, %.2f with jitter", lockstep, jittered)
+	if jittered > lockstep*2/3 {
+		t.Errorf("jitter spread %.2f is not well below the lockstep spread %.2f", jittered, lockstep)
+	}
+}
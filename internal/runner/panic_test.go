@@ -0,0 +1,94 @@
+package runner
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// panickingTransport panics on every other request and answers the others
+// with an empty 200 response.
+type panickingTransport struct {
+	calls atomic.Int32
+}
+
+func (p *panickingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if p.calls.Add(1)%2 == 0 {
+		panic("transport exploded")
+	}
+	return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+}
+
+func TestPanickingRequestsFailWithoutCrashingTheRun(t *testing.T) {
+	cfg := loadConfig(t, `
+endpoints:
+  - name: fragile
+    url: http://fragile.test/items
+    method: GET
+    concurrent:
+      users: 2
+      total: 6
+`)
+	r, err := NewRunner(cfg, Options{NoFileLog: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.clients[clientKey{timeout: cfg.Endpoints[0].RequestTimeout()}] = &http.Client{Transport: &panickingTransport{}}
+
+	if err := r.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	result := endpointResult(t, r.reporter.Report(), "fragile")
+	if result.TotalRequests != 6 || result.SuccessCount != 3 || result.FailureCount != 3 {
+		t.Fatalf("got %d requests, %d successful and %d failed, want 6, 3 and 3",
+			result.TotalRequests, result.SuccessCount, result.FailureCount)
+	}
+	for _, detail := range result.RequestDetails {
+		if detail.Success {
+			continue
+		}
+		if detail.ErrorCategory != "panic" || detail.ErrorMessage != "panic: transport exploded" || !strings.Contains(detail.Stack, "RoundTrip") {
+			t.Errorf("failed request %d: category %q, message %q, stack %q", detail.ID, detail.ErrorCategory, detail.ErrorMessage, detail.Stack)
+		}
+	}
+}
+
+func TestPanickingRequestsReleaseTheirInFlightSlot(t *testing.T) {
+	cfg := loadConfig(t, `
+endpoints:
+  - name: fragile
+    url: http://fragile.test/items
+    method: GET
+    concurrent:
+      users: 2
+      total: 6
+      maxInFlight: 1
+`)
+	r, err := NewRunner(cfg, Options{NoFileLog: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.clients[clientKey{timeout: cfg.Endpoints[0].RequestTimeout()}] = &http.Client{Transport: &panickingTransport{}}
+
+	// a leaked slot blocks every later request until the deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := r.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	result := endpointResult(t, r.reporter.Report(), "fragile")
+	if result.SuccessCount != 3 || result.FailureCount != 3 {
+		t.Errorf("got %d successful and %d failed requests, want 3 and 3", result.SuccessCount, result.FailureCount)
+	}
+	for _, detail := range result.RequestDetails {
+		if !detail.Success && detail.ErrorCategory != "panic" {
+			t.Errorf("request %d failed with %q, want only panics", detail.ID, detail.ErrorMessage)
+		}
+	}
+}
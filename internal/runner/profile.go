@@ -0,0 +1,144 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/JakubPluta/tmago/internal/config"
+	"github.com/JakubPluta/tmago/internal/reporter"
+)
+
+// loadProfile is a stepped load profile with the start offset of every stage.
+type loadProfile struct {
+	stages []config.LoadStage
+	starts []time.Duration
+	total  time.Duration
+}
+
+func newLoadProfile(stages []config.LoadStage) loadProfile {
+	p := loadProfile{stages: stages, starts: make([]time.Duration, len(stages))}
+	for i, stage := range stages {
+		p.starts[i] = p.total
+		p.total += stage.Duration
+	}
+	return p
+}
+
+// stageAt returns the index of the stage active after elapsed, or -1 once the
+// profile has finished.
+func (p loadProfile) stageAt(elapsed time.Duration) int {
+	for i := range p.stages {
+		if elapsed < p.starts[i]+p.stages[i].Duration {
+			return i
+		}
+	}
+	return -1
+}
+
+// stageEnd returns the offset at which the given stage ends.
+func (p loadProfile) stageEnd(stage int) time.Duration {
+	return p.starts[stage] + p.stages[stage].Duration
+}
+
+// maxUsers returns the highest user count of all stages.
+func (p loadProfile) maxUsers() int {
+	users := 0
+	for _, stage := range p.stages {
+		if int(stage.Users) > users {
+			users = int(stage.Users)
+		}
+	}
+	return users
+}
+
+// runStaged runs the endpoint with a stepped load profile. A worker is started
+// for the highest user count of the profile, and worker N only sends requests
+// while the active stage has more than N users, so the number of active users
+// changes at every stage boundary. Every request records the stage it belongs to.
+func (r *Runner) runStaged(ctx context.Context, endpoint config.Endpoint, result *reporter.TestResult) error {
+	profile := newLoadProfile(endpoint.Concurrent.LoadProfile)
+
+	var wg sync.WaitGroup
+	var nextID int64
+	requestChan := make(chan reporter.RequestDetail, profile.maxUsers())
+	errChan := make(chan error, profile.maxUsers())
+
+	result.IsConcurrent = true
+	result.ConcurrentUsers = profile.maxUsers()
+	result.Stages = make([]reporter.StageStats, len(profile.stages))
+	for i, stage := range profile.stages {
+		result.Stages[i] = reporter.StageStats{Stage: i + 1, Users: int(stage.Users), Duration: stage.Duration}
+	}
+
+	var rate *rateController
+	if target := endpoint.Concurrent.TargetRPS; target > 0 {
+		rate = newRateController(target, profile.maxUsers(), endpoint.Concurrent.Delay)
+		result.TargetRPS = target
+	}
+
+	start := time.Now()
+	stageCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go r.logStages(stageCtx, endpoint.Name, profile, start)
+
+	for i := 0; i < profile.maxUsers(); i++ {
+		wg.Add(1)
+		go func(userID int) {
+			defer wg.Done()
+			for {
+				if ctx.Err() != nil {
+					errChan <- ctx.Err()
+					return
+				}
+
+				stage := profile.stageAt(time.Since(start))
+				if stage < 0 {
+					return
+				}
+				if userID >= int(profile.stages[stage].Users) {
+					// inactive in this stage, wait for the next one
+					select {
+					case <-ctx.Done():
+					case <-time.After(time.Until(start.Add(profile.stageEnd(stage)))):
+					}
+					continue
+				}
+
+				r.jitter(ctx, endpoint.Concurrent.Jitter)
+				detail, err := r.executeRequest(ctx, endpoint, int(atomic.AddInt64(&nextID, 1)))
+				detail.Stage = stage + 1
+				requestChan <- detail
+				if err != nil {
+					errChan <- err
+					continue
+				}
+
+				r.pause(ctx, endpoint.Concurrent, rate)
+			}
+		}(i)
+	}
+
+	go func() {
+		wg.Wait()
+		close(requestChan)
+		close(errChan)
+	}()
+
+	return r.collectResults(endpoint, result, requestChan, errChan)
+}
+
+// logStages logs the active user count whenever the load profile enters a new stage.
+func (r *Runner) logStages(ctx context.Context, endpoint string, profile loadProfile, start time.Time) {
+	for i, stage := range profile.stages {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Until(start.Add(profile.starts[i]))):
+			r.logger.Info(fmt.Sprintf("%s: stage %d/%d, %d users for %s",
+				endpoint, i+1, len(profile.stages), stage.Users, stage.Duration))
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package runner
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/JakubPluta/tmago/internal/reporter"
+)
+
+// maxOverlap returns the highest number of the requests that were in flight
+// at the same time.
+func maxOverlap(details []reporter.RequestDetail) int {
+	type edge struct {
+		at    time.Time
+		delta int
+	}
+	var edges []edge
+	for _, d := range details {
+		edges = append(edges, edge{d.Timestamp, 1}, edge{d.Timestamp.Add(d.Duration), -1})
+	}
+	// ends sort before starts at the same time
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].at.Equal(edges[j].at) {
+			return edges[i].delta < edges[j].delta
+		}
+		return edges[i].at.Before(edges[j].at)
+	})
+	current, highest := 0, 0
+	for _, e := range edges {
+		current += e.delta
+		highest = max(highest, current)
+	}
+	return highest
+}
+
+func TestLoadProfileStepsUsersAtTheStageBoundary(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	report, err := runConfig(t, `
+endpoints:
+  - name: ramp
+    url: `+server.URL+`
+    method: GET
+    concurrent:
+      loadProfile:
+        - users: 1
+          duration: 300ms
+        - users: 3
+          duration: 300ms
+`, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := endpointResult(t, report, "ramp")
+	byStage := make(map[int][]reporter.RequestDetail)
+	for _, detail := range result.RequestDetails {
+		byStage[detail.Stage] = append(byStage[detail.Stage], detail)
+	}
+	if len(byStage) != 2 || len(byStage[1]) == 0 || len(byStage[2]) == 0 {
+		t.Fatalf("requests per stage: %d and %d, want both stages", len(byStage[1]), len(byStage[2]))
+	}
+	if n := maxOverlap(byStage[1]); n != 1 {
+		t.Errorf("stage 1 had %d requests in flight, want 1 user", n)
+	}
+	if n := maxOverlap(byStage[2]); n != 3 {
+		t.Errorf("stage 2 had %d requests in flight, want 3 users", n)
+	}
+	if stages := result.Stages; len(stages) != 2 || stages[0].TotalRequests != len(byStage[1]) || stages[1].TotalRequests != len(byStage[2]) {
+		t.Errorf("stage statistics %+v do not count the requests of each stage", stages)
+	}
+}
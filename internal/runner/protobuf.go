@@ -0,0 +1,61 @@
+package runner
+
+import (
+	"fmt"
+
+	"github.com/JakubPluta/tmago/internal/config"
+	"github.com/JakubPluta/tmago/internal/protobuf"
+)
+
+// loadProtobuf loads the descriptor sets of the endpoints sending or reading
+// protobuf messages, once per file, and checks that their messages exist, so
+// that a typo fails the run before any request is sent.
+func (r *Runner) loadProtobuf() error {
+	for _, endpoint := range r.config.Endpoints {
+		p := endpoint.Protobuf
+		if p == nil {
+			continue
+		}
+		descriptors, ok := r.protos[p.Descriptor]
+		if !ok {
+			var err error
+			if descriptors, err = protobuf.Load(p.Descriptor); err != nil {
+				return fmt.Errorf("endpoint %s: %w", endpoint.Name, err)
+			}
+			r.protos[p.Descriptor] = descriptors
+		}
+		for _, name := range []string{p.Request, p.Response} {
+			if name == "" {
+				continue
+			}
+			if _, err := descriptors.Message(name); err != nil {
+				return fmt.Errorf("endpoint %s: %w", endpoint.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// encodeProtobuf encodes the JSON form of a request body to the request
+// message of the endpoint.
+func (r *Runner) encodeProtobuf(p config.Protobuf, body string) (string, error) {
+	message, err := r.protos[p.Descriptor].Message(p.Request)
+	if err != nil {
+		return "", err
+	}
+	data, err := protobuf.Encode(message, []byte(body))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// decodeProtobuf decodes a response body from the response message of the
+// endpoint to its JSON form.
+func (r *Runner) decodeProtobuf(p config.Protobuf, body []byte) ([]byte, error) {
+	message, err := r.protos[p.Descriptor].Message(p.Response)
+	if err != nil {
+		return nil, err
+	}
+	return protobuf.Decode(message, body)
+}
@@ -0,0 +1,135 @@
+package runner
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/JakubPluta/tmago/internal/protobuf"
+)
+
+// writeUserDescriptor writes a descriptor set holding
+// acme.User{int32 id = 1; string display_name = 2; bool active = 3}.
+func writeUserDescriptor(t *testing.T) string {
+	t.Helper()
+	field := func(name, jsonName string, number int32, kind descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(jsonName),
+			Number:   proto.Int32(number),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     kind.Enum(),
+		}
+	}
+	set := &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{{
+		Name:    proto.String("acme/user.proto"),
+		Package: proto.String("acme"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("User"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("id", "id", 1, descriptorpb.FieldDescriptorProto_TYPE_INT32),
+				field("display_name", "displayName", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				field("active", "active", 3, descriptorpb.FieldDescriptorProto_TYPE_BOOL),
+			},
+		}},
+	}}}
+	data, err := proto.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "user.protoset")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestProtobufRoundTrip(t *testing.T) {
+	descriptor := writeUserDescriptor(t)
+	var received []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/error" {
+			// errors are sent as JSON, whatever the endpoint expects
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": "no such user"}`))
+			return
+		}
+		received, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+		w.Write(received)
+	}))
+	defer server.Close()
+
+	report, err := runConfig(t, `
+endpoints:
+  - name: echo
+    url: `+server.URL+`/echo
+    method: POST
+    body: '{"id": 7, "displayName": "Ada", "active": true}'
+    protobuf:
+      descriptor: `+descriptor+`
+      request: acme.User
+      response: acme.User
+    expect:
+      values:
+        - path: id
+          value: 7
+        - path: displayName
+          value: Ada
+        - path: active
+          value: true
+  - name: error
+    url: `+server.URL+`/error
+    method: GET
+    protobuf:
+      descriptor: `+descriptor+`
+      response: acme.User
+    expect:
+      status: 404
+      values:
+        - path: error
+          value: no such user
+`, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"echo", "error"} {
+		if result := endpointResult(t, report, name); result.SuccessCount != 1 {
+			t.Errorf("%s failed: %v %v", name, result.Errors, result.RequestDetails)
+		}
+	}
+
+	// the request was sent in its binary encoding
+	descriptors, err := protobuf.Load(descriptor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := descriptors.Message("acme.User")
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := protobuf.Decode(user, received)
+	if err != nil {
+		t.Fatalf("the request body is not an acme.User: %v", err)
+	}
+	// protojson varies its whitespace, so the JSON is compared decoded
+	var sent map[string]interface{}
+	if err := json.Unmarshal(decoded, &sent); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"id": 7.0, "displayName": "Ada", "active": true}
+	if !reflect.DeepEqual(sent, want) {
+		t.Errorf("sent %v, want %v", sent, want)
+	}
+}
@@ -0,0 +1,65 @@
+package runner
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestReplayValidatesLikeTheRecordedRun(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id": 7, "name": "widget"}`))
+			return
+		}
+		w.Write([]byte(`{"items": [1, 2], "total": 3}`))
+	}))
+
+	config := `
+endpoints:
+  - name: create
+    url: ` + server.URL + `/items
+    method: POST
+    body: '{"name": "widget"}'
+    expect:
+      status: 201
+      values:
+        - path: id
+          value: 7
+  - name: list
+    url: ` + server.URL + `/items
+    method: GET
+    expect:
+      values:
+        - path: total
+          value: 2
+`
+	dir := t.TempDir()
+	recorded, err := runConfig(t, config, Options{RecordDir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// replaying must not need the server
+	server.Close()
+	replayed, err := runConfig(t, config, Options{ReplayDir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"create", "list"} {
+		want := endpointResult(t, recorded, name).RequestDetails[0]
+		got := endpointResult(t, replayed, name).RequestDetails[0]
+		if got.StatusCode != want.StatusCode || got.Success != want.Success || got.ErrorMessage != "" ||
+			!reflect.DeepEqual(got.ValidationErrors, want.ValidationErrors) {
+			t.Errorf("%s replayed as %d %t %q %q, recorded as %d %t %q", name,
+				got.StatusCode, got.Success, got.ErrorMessage, got.ValidationErrors,
+				want.StatusCode, want.Success, want.ValidationErrors)
+		}
+	}
+	if list := endpointResult(t, replayed, "list").RequestDetails[0]; list.Success {
+		t.Error("list passed, want the recorded total of 3 to fail")
+	}
+}
@@ -0,0 +1,101 @@
+package runner
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Recording is a response saved by record mode, replayed instead of the
+// network in replay mode.
+type Recording struct {
+	Method   string        `json:"method"`
+	URL      string        `json:"url"`
+	Status   int           `json:"status"`
+	Header   http.Header   `json:"header"`
+	Trailer  http.Header   `json:"trailer,omitempty"`
+	Body     []byte        `json:"body"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Recordings stores recorded responses in a directory, one JSON file per
+// request keyed by a hash of its method, URL and body.
+type Recordings struct {
+	dir string
+}
+
+// NewRecordings creates a recording store in dir.
+func NewRecordings(dir string) *Recordings {
+	return &Recordings{dir: dir}
+}
+
+// recordingKey identifies a request by its method, URL and body.
+func recordingKey(method, url, body string) string {
+	sum := sha256.Sum256([]byte(method + "\n" + url + "\n" + body))
+	return hex.EncodeToString(sum[:])
+}
+
+func (r *Recordings) path(method, url, body string) string {
+	return filepath.Join(r.dir, recordingKey(method, url, body)+".json")
+}
+
+// Save stores the response to the given request.
+func (r *Recordings) Save(method, url, reqBody string, resp *http.Response, body []byte, duration time.Duration) error {
+	if err := os.MkdirAll(r.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create recordings directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(Recording{
+		Method:   method,
+		URL:      url,
+		Status:   resp.StatusCode,
+		Header:   resp.Header,
+		Trailer:  resp.Trailer,
+		Body:     body,
+		Duration: duration,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode recording: %w", err)
+	}
+	return os.WriteFile(r.path(method, url, reqBody), data, 0644)
+}
+
+// Load returns the recorded response to the given request as an
+// *http.Response, together with its body and recorded duration.
+func (r *Recordings) Load(method, url, reqBody string) (*http.Response, []byte, time.Duration, error) {
+	data, err := os.ReadFile(r.path(method, url, reqBody))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, 0, fmt.Errorf("no recording for %s %s", method, url)
+		}
+		return nil, nil, 0, fmt.Errorf("failed to read recording: %w", err)
+	}
+
+	var rec Recording
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, nil, 0, fmt.Errorf("failed to decode recording for %s %s: %w", method, url, err)
+	}
+
+	resp := &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.Status, http.StatusText(rec.Status)),
+		StatusCode:    rec.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        rec.Header,
+		Trailer:       rec.Trailer,
+		Body:          io.NopCloser(bytes.NewReader(rec.Body)),
+		ContentLength: int64(len(rec.Body)),
+	}
+	if resp.Header == nil {
+		resp.Header = make(http.Header)
+	}
+	return resp, rec.Body, rec.Duration, nil
+}
@@ -0,0 +1,55 @@
+package runner
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRedactedReport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Api-Key", r.Header.Get("X-Api-Key"))
+		w.Write([]byte(`{"user": {"name": "ann", "password": "hunter2-returned"}}`))
+	}))
+	defer server.Close()
+
+	_, err := runConfig(t, `
+redact:
+  headers: [X-Api-Key]
+  paths: [user.password]
+  patterns: ["token=([^&]+)"]
+endpoints:
+  - name: login
+    url: `+server.URL+`/login?token=sesame-token&page=1
+    method: POST
+    headers:
+      X-Api-Key: key-sent-secret
+    body: '{"user": {"name": "ann", "password": "hunter2-sent"}}'
+    expect:
+      values:
+        - path: user.password
+          value: something-else
+`, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(ReportsDir, "report.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := string(data)
+	for _, secret := range []string{"key-sent-secret", "hunter2-sent", "hunter2-returned", "something-else", "sesame-token"} {
+		if strings.Contains(report, secret) {
+			t.Errorf("report contains %q", secret)
+		}
+	}
+	for _, kept := range []string{`\"name\":\"ann\"`, `token=***\u0026page=1`, `"X-Api-Key": "***"`} {
+		if !strings.Contains(report, kept) {
+			t.Errorf("report does not contain %s", kept)
+		}
+	}
+}
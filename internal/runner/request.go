@@ -0,0 +1,55 @@
+package runner
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/JakubPluta/tmago/internal/reporter"
+)
+
+// maxSentBody is the number of request body bytes kept in the report.
+const maxSentBody = 4096
+
+// sentRequest describes req as it was sent, with sensitive data redacted and
+// long bodies truncated.
+func (r *Runner) sentRequest(req *http.Request, body string) *reporter.SentRequest {
+	redact := r.config.Redact
+	sent := &reporter.SentRequest{
+		Method:  req.Method,
+		URL:     redact.Text(req.URL.String()),
+		Headers: make(map[string]string, len(req.Header)),
+		Body:    redact.Body(body),
+	}
+	for k, v := range req.Header {
+		sent.Headers[k] = redact.Header(k, strings.Join(v, ", "))
+	}
+	if req.Host != "" && req.Host != req.URL.Host {
+		sent.Headers["Host"] = req.Host
+	}
+	if len(sent.Body) > maxSentBody {
+		sent.Body = sent.Body[:maxSentBody] + "... (truncated)"
+	}
+	return sent
+}
+
+// redactDetail redacts the sensitive data of the response and the messages
+// of a completed request, before it is logged or recorded.
+func (r *Runner) redactDetail(detail *reporter.RequestDetail) {
+	redact := r.config.Redact
+	for k, v := range detail.Headers {
+		detail.Headers[k] = redact.Header(k, v)
+	}
+	for k, v := range detail.Trailers {
+		detail.Trailers[k] = redact.Header(k, v)
+	}
+	detail.ErrorMessage = redact.Text(detail.ErrorMessage)
+	detail.PrimaryError = redact.Text(detail.PrimaryError)
+	for i, msg := range detail.ValidationErrors {
+		detail.ValidationErrors[i] = redact.Text(msg)
+	}
+	for i := range detail.Failures {
+		detail.Failures[i].Expected = redact.Text(detail.Failures[i].Expected)
+		detail.Failures[i].Actual = redact.Text(detail.Failures[i].Actual)
+		detail.Failures[i].Message = redact.Text(detail.Failures[i].Message)
+	}
+}
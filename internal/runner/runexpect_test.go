@@ -0,0 +1,43 @@
+package runner
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRunExpectationTotalRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	config := func(expected string) string {
+		return `
+expect:
+  totalRequests: ` + expected + `
+endpoints:
+  - name: items
+    url: ` + server.URL + `/items
+    method: GET
+    concurrent:
+      users: 2
+      total: 4
+`
+	}
+
+	if _, err := runConfig(t, config("4"), Options{}); err != nil {
+		t.Errorf("exact total met: %v", err)
+	}
+	if _, err := runConfig(t, config("{min: 3, max: 5}"), Options{}); err != nil {
+		t.Errorf("total within range: %v", err)
+	}
+
+	_, err := runConfig(t, config("{min: 10}"), Options{})
+	if !errors.Is(err, ErrRunExpectation) {
+		t.Fatalf("err = %v, want ErrRunExpectation", err)
+	}
+	if !strings.Contains(err.Error(), "made 4 requests, expected at least 10") {
+		t.Errorf("err = %q, want the actual and expected counts", err)
+	}
+}
@@ -2,81 +2,487 @@ package runner
 
 import (
 	"bytes"
+	"compress/gzip"
+	"compress/zlib"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
+	"os"
+	"runtime/debug"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/JakubPluta/tmago/internal/config"
+	"github.com/JakubPluta/tmago/internal/har"
 	"github.com/JakubPluta/tmago/internal/logger"
+	"github.com/JakubPluta/tmago/internal/protobuf"
 	"github.com/JakubPluta/tmago/internal/reporter"
 	"github.com/JakubPluta/tmago/internal/validator"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
 )
 
 type Runner struct {
 	config   *config.Config
-	client   *http.Client
 	logger   *logger.Logger
 	reporter *reporter.Reporter
+	vars     *Variables
+	random   *Random
+	seed     int64
+	sinks    []ResultSink
+	record   *Recordings
+	replay   *Recordings
+	opts     Options
+	// clients are the HTTP clients by endpoint settings, see clientFor
+	clients   map[clientKey]*http.Client
+	clientsMu sync.Mutex
+	// slots are the in-flight slots by endpoint name, see acquireSlot
+	slots   map[string]chan struct{}
+	slotsMu sync.Mutex
+	// writers write the report at the end of the run
+	writers []reporter.ReportWriter
+	// protos are the protobuf descriptor sets by path, see loadProtobuf
+	protos map[string]*protobuf.Descriptors
 }
 
-func NewRunner(cfg *config.Config) (*Runner, error) {
-	logger, err := logger.NewLogger("logs")
+// Options holds the run-wide settings that are not part of the config file.
+type Options struct {
+	// Seed seeds the random data generators. A zero seed is replaced by a
+	// time-based one, which is logged so the run can be reproduced.
+	Seed int64
+	// Shuffle runs the endpoints in a random order drawn from Seed, still
+	// after the endpoints they depend on, to reveal tests that depend on
+	// the order of the config. It does not apply to scenarios and replays.
+	Shuffle bool
+	// Events, when set, receives every completed request as a JSON line.
+	// Console logging is moved to stderr so the stream stays parseable.
+	Events io.Writer
+	// RecordDir, when set, saves every response in the directory.
+	RecordDir string
+	// ReplayDir, when set, answers requests with the responses recorded in
+	// the directory instead of hitting the network.
+	ReplayDir string
+	// NoFileLog disables the log file, logging only to the console.
+	NoFileLog bool
+	// WebhookURL, when set, receives a JSON summary of the run.
+	WebhookURL string
+	// WebhookOn is WebhookOnFailure (default) or WebhookOnAlways.
+	WebhookOn string
+	// VarsIn, when set, loads variables saved by a previous run before the tests start.
+	VarsIn string
+	// VarsOut, when set, saves the variables captured by the run at the end.
+	VarsOut string
+	// ExportCaptures, when set, writes the variables at the end of the run to
+	// the file in dotenv format, for use by shell scripts.
+	ExportCaptures string
+	// SplitReportsDir, when set, also writes a report per endpoint and an
+	// index linking them to the directory.
+	SplitReportsDir string
+	// MaxDuration, when set, is the wall-clock budget of the run. The run
+	// still completes, but Run returns ErrMaxDurationExceeded when it took longer.
+	MaxDuration time.Duration
+	// Smoke sends a single request per endpoint, ignoring the concurrency,
+	// retry and scenario settings, and prints a pass/fail line for each.
+	Smoke bool
+	// Sample, when set, scales the requests and users of every endpoint and
+	// the scenario down to the fraction, between 0 and 1, of the configured
+	// ones, see config.Config.Sample. NewRunner applies it to the config.
+	Sample float64
+	// BucketWidth is the time window of the status code timeline in the
+	// report, picked for the length of the run when zero.
+	BucketWidth time.Duration
+	// SortRequests lists the requests of every endpoint in the reports by ID
+	// instead of in the order they completed.
+	SortRequests bool
+	// Labels are added to the metadata of the config, overriding its keys,
+	// to label the run in the reports and the webhook summary.
+	Labels map[string]string
+	// MaxJSONSize is the size of the largest response body parsed for the
+	// JSON checks and captures, validator.DefaultMaxJSONSize when zero.
+	MaxJSONSize int64
+	// MaxReportSize, when set, caps the size of the HTML report in bytes,
+	// splitting the request details into separate pages beyond it.
+	MaxReportSize int64
+	// Sinks receive every completed request as it completes, in addition to
+	// the report and the Events stream.
+	Sinks []ResultSink
+	// Formats are the report formats written to ReportsDir at the end of the
+	// run, reporter.DefaultFormats when empty. See reporter.RegisterFormat.
+	Formats []string
+	// Writers receive the report at the end of the run, in addition to the
+	// Formats, e.g. to store it in a results database.
+	Writers []reporter.ReportWriter
+	// ReportWorkers is the number of endpoints whose statistics are computed
+	// concurrently for the report, one per CPU when zero.
+	ReportWorkers int
+	// Traffic, when set, replaces the endpoint runs with a replay of the
+	// recorded requests, see runTraffic. The config lists their endpoints.
+	Traffic []har.Request
+	// Speed scales the time between the requests of Traffic: 2 replays them
+	// twice as fast as recorded. Zero replays them at the recorded pace.
+	Speed float64
+}
+
+// ReportsDir receives the reports of the run.
+const ReportsDir = "reports"
+
+// ResultsFile receives the result of every endpoint as it finishes, so a run
+// that crashes still leaves partial results to regenerate the report from.
+const ResultsFile = "reports/results.jsonl"
+
+// ErrMaxDurationExceeded is returned by Run when the run took longer than
+// Options.MaxDuration.
+var ErrMaxDurationExceeded = errors.New("run exceeded its maximum duration")
+
+// ErrMinRPS is returned by Run when an endpoint did not sustain its expect.minRps.
+var ErrMinRPS = errors.New("endpoint below its minimum throughput")
+
+// ErrRunExpectation is returned by Run when an assertion about the whole run fails.
+var ErrRunExpectation = errors.New("run expectation failed")
+
+func NewRunner(cfg *config.Config, opts Options) (*Runner, error) {
+	logOpts := logger.Options{Dir: "logs", NoFile: opts.NoFileLog}
+	if cfg.Redact != nil {
+		logOpts.Redact = cfg.Redact.Text
+	}
+	if opts.Events != nil {
+		logOpts.Console = os.Stderr
+	}
+	logger, err := logger.NewLoggerWithOptions(logOpts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create logger: %w", err)
 	}
 
-	return &Runner{
+	if opts.WebhookOn == "" {
+		opts.WebhookOn = WebhookOnFailure
+	}
+	if opts.WebhookOn != WebhookOnFailure && opts.WebhookOn != WebhookOnAlways {
+		return nil, fmt.Errorf("invalid webhook policy %q, expected %s or %s", opts.WebhookOn, WebhookOnFailure, WebhookOnAlways)
+	}
+
+	if opts.RecordDir != "" && opts.ReplayDir != "" {
+		return nil, fmt.Errorf("record and replay modes are mutually exclusive")
+	}
+
+	// the run reorders the endpoints with Shuffle, which must not change
+	// the config of the caller, and Sample scales a copy of it
+	copied := *cfg
+	cfg = &copied
+	if opts.Sample != 0 {
+		if cfg, err = cfg.Sample(opts.Sample); err != nil {
+			return nil, err
+		}
+	}
+
+	formats := opts.Formats
+	if len(formats) == 0 {
+		formats = reporter.DefaultFormats
+	}
+	writers := make([]reporter.ReportWriter, 0, len(formats)+len(opts.Writers))
+	for _, format := range formats {
+		writer, err := reporter.NewWriter(format, ReportsDir)
+		if err != nil {
+			return nil, err
+		}
+		writers = append(writers, writer)
+	}
+	writers = append(writers, opts.Writers...)
+
+	seed := opts.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	r := &Runner{
 		config:   cfg,
-		client:   &http.Client{Timeout: time.Second * 30},
+		clients:  make(map[clientKey]*http.Client),
+		slots:    make(map[string]chan struct{}),
 		logger:   logger,
 		reporter: reporter.NewReporter(),
-	}, nil
+		vars:     NewVariables(),
+		random:   NewRandom(seed),
+		seed:     seed,
+		sinks:    append([]ResultSink(nil), opts.Sinks...),
+		writers:  writers,
+		opts:     opts,
+		protos:   make(map[string]*protobuf.Descriptors),
+	}
+	if err := r.loadProtobuf(); err != nil {
+		return nil, err
+	}
+	if opts.Events != nil {
+		r.AddSink(eventSink{events: NewEventWriter(opts.Events), logger: logger})
+	}
+	if opts.VarsIn != "" {
+		if err := r.vars.Load(opts.VarsIn); err != nil {
+			return nil, err
+		}
+	}
+	if opts.RecordDir != "" {
+		r.record = NewRecordings(opts.RecordDir)
+	}
+	if opts.ReplayDir != "" {
+		r.replay = NewRecordings(opts.ReplayDir)
+	}
+	return r, nil
+}
+
+// AddWriter registers a writer receiving the report at the end of the run,
+// in addition to the selected formats. It must be called before Run.
+func (r *Runner) AddWriter(writer reporter.ReportWriter) {
+	r.writers = append(r.writers, writer)
 }
 
 func (r *Runner) Run(ctx context.Context) error {
+	// a target that is down leaves the reports of the previous run untouched
+	if r.replay == nil {
+		if err := r.checkHealth(ctx); err != nil {
+			return err
+		}
+	}
+
 	r.reporter.StartTest() // Initialize start time
+	r.reporter.SetMaxDuration(r.opts.MaxDuration)
+	r.reporter.SetSmoke(r.opts.Smoke)
+	r.reporter.SetBucketWidth(r.opts.BucketWidth)
+	r.reporter.SetSortRequests(r.opts.SortRequests)
+	r.reporter.SetLabels(r.labels())
+	r.reporter.SetMaxReportSize(r.opts.MaxReportSize)
+	r.reporter.SetParallelism(r.opts.ReportWorkers)
+	if err := r.reporter.StreamResults(ResultsFile); err != nil {
+		r.logger.Warn(fmt.Sprintf("results will only be written at the end of the run: %v", err))
+	}
+	defer r.reporter.CloseStream()
+	start := time.Now()
+	r.logger.Info(fmt.Sprintf("Using random seed %d (rerun with --seed %d to reproduce)", r.seed, r.seed))
 
-	for _, endpoint := range r.config.Endpoints {
-		r.logger.TestStarted(endpoint.Name, endpoint.Method, endpoint.URL)
+	for _, script := range r.config.PreScripts {
+		if err := r.runScript(ctx, script); err != nil {
+			return err
+		}
+	}
 
-		result := reporter.TestResult{
-			EndpointName:       endpoint.Name,
-			Method:             endpoint.Method,
-			URL:                endpoint.URL,
-			StartTime:          time.Now(),
-			StatusCodes:        make(map[int]int),
-			ValidationFailures: make(map[string]int),
-			RequestDetails:     make([]reporter.RequestDetail, 0),
+	if r.opts.Shuffle {
+		switch {
+		case r.config.Scenario != nil || len(r.opts.Traffic) > 0:
+			r.logger.Warn("--shuffle has no effect on scenarios and replays")
+		case r.config.HasDependencies() && !r.opts.Smoke:
+			r.logger.Warn("--shuffle has no effect on endpoints run as a dependency graph, which start as soon as their dependencies finished")
+		default:
+			r.shuffleEndpoints()
 		}
+	}
 
-		if endpoint.Concurrent.Users > 0 {
-			err := r.runConcurrent(ctx, endpoint, &result)
-			if err != nil {
-				r.logger.RequestFailed(-1, endpoint.Name, err)
-				result.Errors = append(result.Errors, err.Error())
-			}
-		} else {
-			err := r.runSingle(ctx, endpoint, &result)
-			if err != nil {
-				r.logger.RequestFailed(-1, endpoint.Name, err)
-				result.Errors = append(result.Errors, err.Error())
-			}
+	var runErrs []error
+	if r.opts.Smoke {
+		if err := r.runSmoke(ctx); err != nil {
+			runErrs = append(runErrs, err)
+		}
+	} else if len(r.opts.Traffic) > 0 {
+		r.runTraffic(ctx, r.opts.Traffic)
+	} else if r.config.Scenario != nil {
+		r.runScenario(ctx, *r.config.Scenario)
+	} else if r.config.HasDependencies() {
+		r.runGraph(ctx, r.config.Endpoints)
+	} else {
+		for _, endpoint := range r.config.Endpoints {
+			r.runEndpoint(ctx, endpoint)
 		}
+	}
 
-		result.EndTime = time.Now()
-		duration := result.EndTime.Sub(result.StartTime)
-		result.RequestsPerSecond = float64(result.TotalRequests) / duration.Seconds()
-		result.ErrorRate = float64(result.FailureCount) / float64(result.TotalRequests) * 100
+	if elapsed := time.Since(start); r.opts.MaxDuration > 0 && elapsed > r.opts.MaxDuration {
+		err := fmt.Errorf("%w: took %s, budget %s", ErrMaxDurationExceeded, elapsed.Round(time.Millisecond), r.opts.MaxDuration)
+		r.logger.Warn(err.Error())
+		runErrs = append(runErrs, err)
+	}
+	if err := r.checkRunExpectation(); err != nil {
+		r.logger.Warn(err.Error())
+		runErrs = append(runErrs, err)
+	}
+	for _, result := range r.reporter.Report().TestResults {
+		if err := throughputError(result); err != nil {
+			runErrs = append(runErrs, err)
+		}
+	}
+
+	err := r.reporter.CloseStream()
+	if writeErr := r.reporter.Write(r.writers...); err == nil {
+		err = writeErr
+	}
+	if r.opts.SplitReportsDir != "" {
+		if splitErr := r.reporter.GenerateSplit(r.opts.SplitReportsDir); err == nil {
+			err = splitErr
+		}
+	}
+	if r.opts.VarsOut != "" {
+		if varsErr := r.vars.Save(r.opts.VarsOut); err == nil {
+			err = varsErr
+		}
+	}
+	if r.opts.ExportCaptures != "" {
+		if exportErr := r.vars.SaveDotenv(r.opts.ExportCaptures); err == nil {
+			err = exportErr
+		}
+	}
+	r.notifyWebhook(ctx, r.reporter.Report())
+	if err == nil {
+		err = errors.Join(runErrs...)
+	}
+	return err
+}
+
+// labels returns the metadata of the config merged with the labels of the
+// options, or nil when there are none.
+func (r *Runner) labels() map[string]string {
+	if len(r.config.Metadata) == 0 && len(r.opts.Labels) == 0 {
+		return nil
+	}
+	labels := make(map[string]string, len(r.config.Metadata)+len(r.opts.Labels))
+	for k, v := range r.config.Metadata {
+		labels[k] = v
+	}
+	for k, v := range r.opts.Labels {
+		labels[k] = v
+	}
+	return labels
+}
 
-		r.reporter.AddResult(result)
-		r.logger.Info(fmt.Sprintf("Test %s completed. TotalRequests: %d, Success: %d, Failures: %d",
-			endpoint.Name, result.TotalRequests, result.SuccessCount, result.FailureCount))
+// checkRunExpectation checks the assertions about the whole run, returning an
+// error wrapping ErrRunExpectation when one fails. They do not apply to smoke
+// and sampled runs.
+func (r *Runner) checkRunExpectation() error {
+	expect := r.config.Expect
+	if expect == nil || expect.TotalRequests == nil || r.opts.Smoke || r.opts.Sample != 0 {
+		return nil
 	}
 
-	return r.reporter.GenerateHTML("reports/report.html")
+	total := r.reporter.Report().TotalRequests
+	if !expect.TotalRequests.Contains(total) {
+		return fmt.Errorf("%w: made %d requests, expected %s", ErrRunExpectation, total, expect.TotalRequests)
+	}
+	return nil
+}
+
+// throughputError returns an error wrapping ErrMinRPS when the result did
+// not sustain its expected minimum throughput.
+func throughputError(result reporter.TestResult) error {
+	if result.MinRPS == 0 || result.RequestsPerSecond >= result.MinRPS {
+		return nil
+	}
+	return fmt.Errorf("%w: %s made %.2f requests per second, expected at least %.2f",
+		ErrMinRPS, result.EndpointName, result.RequestsPerSecond, result.MinRPS)
+}
+
+// runEndpoint runs the tests of a single endpoint, using the single, concurrent
+// or staged runner depending on its configuration, adds the result to the report
+// and returns it.
+func (r *Runner) runEndpoint(ctx context.Context, endpoint config.Endpoint) reporter.TestResult {
+	r.logger.TestStarted(endpoint.Name, endpoint.Method, endpoint.URL)
+
+	result := newResult(endpoint)
+
+	var scriptErr error
+	if endpoint.PreScript != nil {
+		scriptErr = r.runScript(ctx, *endpoint.PreScript)
+	}
+
+	if scriptErr != nil {
+		r.logger.RequestFailed(-1, endpoint.Name, scriptErr)
+		result.Errors = append(result.Errors, scriptErr.Error())
+	} else if endpoint.Concurrent.Benchmark != nil {
+		err := r.runBenchmark(ctx, endpoint, &result)
+		if err != nil {
+			r.logger.RequestFailed(-1, endpoint.Name, err)
+			result.Errors = append(result.Errors, err.Error())
+		}
+	} else if endpoint.Concurrent.Autotune != nil {
+		err := r.runAutotune(ctx, endpoint, &result)
+		if err != nil {
+			r.logger.RequestFailed(-1, endpoint.Name, err)
+			result.Errors = append(result.Errors, err.Error())
+		}
+	} else if len(endpoint.Concurrent.LoadProfile) > 0 {
+		err := r.runStaged(ctx, endpoint, &result)
+		if err != nil {
+			r.logger.RequestFailed(-1, endpoint.Name, err)
+			result.Errors = append(result.Errors, err.Error())
+		}
+	} else if endpoint.Concurrent.Users > 0 {
+		err := r.runConcurrent(ctx, endpoint, &result)
+		if err != nil {
+			r.logger.RequestFailed(-1, endpoint.Name, err)
+			result.Errors = append(result.Errors, err.Error())
+		}
+	} else {
+		err := r.runSingle(ctx, endpoint, &result)
+		if err != nil {
+			r.logger.RequestFailed(-1, endpoint.Name, err)
+			result.Errors = append(result.Errors, err.Error())
+		}
+	}
+
+	var minRPS float64
+	if endpoint.Concurrent.IsConcurrent() {
+		minRPS = endpoint.Expect.MinRPS
+	}
+	r.finishResult(&result, minRPS)
+	return result
+}
+
+// newResult creates an empty result for the endpoint, starting now.
+func newResult(endpoint config.Endpoint) reporter.TestResult {
+	result := reporter.TestResult{
+		EndpointName:       endpoint.Name,
+		Method:             endpoint.Method,
+		URL:                endpoint.URL,
+		StartTime:          time.Now(),
+		StatusCodes:        make(map[int]int),
+		ValidationFailures: make(map[string]int),
+		RequestDetails:     make([]reporter.RequestDetail, 0),
+		MaxInFlight:        int(endpoint.Concurrent.MaxInFlight),
+		// failed requests, including attempts retried by retry.count, only
+		// count towards the latency statistics when asked to
+		LatencyIncludesFailures: endpoint.Latency == config.LatencyAll,
+	}
+	if endpoint.SLO != nil {
+		result.SLO = &reporter.SLOResult{
+			Target:    endpoint.SLO.TargetFraction(),
+			Threshold: endpoint.SLO.Threshold,
+		}
+	}
+	return result
+}
+
+// finishResult computes the rates of a completed result, checks its
+// throughput against minRPS unless zero, and adds it to the report.
+func (r *Runner) finishResult(result *reporter.TestResult, minRPS float64) {
+	result.EndTime = time.Now()
+	result.URL = r.config.Redact.Text(result.URL)
+	for i, msg := range result.Errors {
+		result.Errors[i] = r.config.Redact.Text(msg)
+	}
+	duration := result.EndTime.Sub(result.StartTime)
+	result.RequestsPerSecond = float64(result.TotalRequests) / duration.Seconds()
+	result.BytesPerSecond = float64(result.BytesTransferred) / duration.Seconds()
+	if result.TotalRequests > 0 {
+		result.ErrorRate = float64(result.FailureCount) / float64(result.TotalRequests) * 100
+	}
+	result.MinRPS = minRPS
+	if err := throughputError(*result); err != nil {
+		r.logger.Warn(err.Error())
+		result.Errors = append(result.Errors, err.Error())
+	}
+
+	r.reporter.AddResult(*result)
+	r.logger.Info(fmt.Sprintf("Test %s completed. TotalRequests: %d, Success: %d, Failures: %d",
+		result.EndpointName, result.TotalRequests, result.SuccessCount, result.FailureCount))
 }
 
 func (r *Runner) runSingle(ctx context.Context, endpoint config.Endpoint, result *reporter.TestResult) error {
@@ -87,59 +493,33 @@ func (r *Runner) runSingle(ctx context.Context, endpoint config.Endpoint, result
 			time.Sleep(endpoint.Retry.Delay)
 		}
 
-		requestDetail := reporter.RequestDetail{
-			ID:        result.TotalRequests + 1,
-			Timestamp: time.Now(),
-		}
-
-		resp, body, duration, err := r.makeRequest(ctx, endpoint)
-		requestDetail.Duration = duration
-
+		requestDetail, err := r.executeRequest(ctx, endpoint, len(result.RequestDetails)+1)
 		if err != nil {
 			lastErr = err
-			requestDetail.Success = false
-			requestDetail.ErrorMessage = err.Error()
-			result.RequestDetails = append(result.RequestDetails, requestDetail)
+			r.addDetail(endpoint.Name, result, requestDetail)
 			continue
 		}
 
-		requestDetail.StatusCode = resp.StatusCode
-		requestDetail.ResponseSize = int64(len(body))
-		requestDetail.Headers = make(map[string]string)
-		for k, v := range resp.Header {
-			requestDetail.Headers[k] = v[0]
-		}
-
-		validationResult := r.validateResponse(resp, body, duration, endpoint)
-		requestDetail.Success = validationResult.IsValid
-		requestDetail.ValidationErrors = validationResult.Errors
-
 		result.TotalRequests++
-		result.StatusCodes[resp.StatusCode]++
-		result.BytesTransferred += int64(len(body))
+		result.StatusCodes[requestDetail.StatusCode]++
+		result.BytesTransferred += requestDetail.ResponseSize
 
-		if validationResult.IsValid {
+		if requestDetail.Success {
 			result.SuccessCount++
-			if result.MinLatency == 0 || duration < result.MinLatency {
-				result.MinLatency = duration
-			}
-			if duration > result.MaxLatency {
-				result.MaxLatency = duration
-			}
 		} else {
 			result.FailureCount++
-			for _, err := range validationResult.Errors {
+			for _, err := range requestDetail.ValidationErrors {
 				result.ValidationFailures[err]++
 			}
 		}
 
-		result.RequestDetails = append(result.RequestDetails, requestDetail)
+		r.addDetail(endpoint.Name, result, requestDetail)
 
-		if validationResult.IsValid {
+		if requestDetail.Success {
 			return nil
 		}
 
-		lastErr = fmt.Errorf("validation failed: %v", validationResult.Errors)
+		lastErr = fmt.Errorf("validation failed: %v", requestDetail.ValidationErrors)
 	}
 
 	return lastErr
@@ -147,59 +527,52 @@ func (r *Runner) runSingle(ctx context.Context, endpoint config.Endpoint, result
 
 func (r *Runner) runConcurrent(ctx context.Context, endpoint config.Endpoint, result *reporter.TestResult) error {
 	var wg sync.WaitGroup
-	requestChan := make(chan reporter.RequestDetail, endpoint.Concurrent.Total)
-	errChan := make(chan error, endpoint.Concurrent.Total)
+	// collectResults drains both channels while the users run, so buffering a
+	// couple of results per user is enough and keeps memory independent of Total
+	requestChan := make(chan reporter.RequestDetail, endpoint.Concurrent.Users*2)
+	errChan := make(chan error, endpoint.Concurrent.Users*2)
 
-	requestsPerUser := endpoint.Concurrent.Total / endpoint.Concurrent.Users
+	users, total := int(endpoint.Concurrent.Users), int(endpoint.Concurrent.Total)
+	var nextID int64
 	result.IsConcurrent = true
-	result.ConcurrentUsers = endpoint.Concurrent.Users
+	result.ConcurrentUsers = int(endpoint.Concurrent.Users)
+
+	var rate *rateController
+	if target := endpoint.Concurrent.TargetRPS; target > 0 {
+		rate = newRateController(target, int(endpoint.Concurrent.Users), endpoint.Concurrent.Delay)
+		result.TargetRPS = target
+	}
 
-	for i := 0; i < endpoint.Concurrent.Users; i++ {
+	for i := 0; i < users; i++ {
+		// the first users send one more request when the total does not
+		// divide evenly
+		requests := total / users
+		if i < total%users {
+			requests++
+		}
 		wg.Add(1)
-		go func(userID int) {
+		go func() {
 			defer wg.Done()
-			for j := 0; j < requestsPerUser; j++ {
+			for j := 0; j < requests; j++ {
 				select {
 				case <-ctx.Done():
 					errChan <- ctx.Err()
 					return
 				default:
-					requestID := userID*requestsPerUser + j + 1
-					detail := reporter.RequestDetail{
-						ID:        requestID,
-						Timestamp: time.Now(),
-					}
-
-					resp, body, duration, err := r.makeRequest(ctx, endpoint)
-					detail.Duration = duration
-
+					r.jitter(ctx, endpoint.Concurrent.Jitter)
+					// IDs are unique and contiguous across users, in the order
+					// requests start
+					detail, err := r.executeRequest(ctx, endpoint, int(atomic.AddInt64(&nextID, 1)))
+					requestChan <- detail
 					if err != nil {
-						detail.Success = false
-						detail.ErrorMessage = err.Error()
-						requestChan <- detail
 						errChan <- err
 						continue
 					}
 
-					detail.StatusCode = resp.StatusCode
-					detail.ResponseSize = int64(len(body))
-					detail.Headers = make(map[string]string)
-					for k, v := range resp.Header {
-						detail.Headers[k] = v[0]
-					}
-
-					validationResult := r.validateResponse(resp, body, duration, endpoint)
-					detail.Success = validationResult.IsValid
-					detail.ValidationErrors = validationResult.Errors
-
-					requestChan <- detail
-
-					if endpoint.Concurrent.Delay > 0 {
-						time.Sleep(endpoint.Concurrent.Delay)
-					}
+					r.pause(ctx, endpoint.Concurrent, rate)
 				}
 			}
-		}(i)
+		}()
 	}
 
 	go func() {
@@ -208,85 +581,566 @@ func (r *Runner) runConcurrent(ctx context.Context, endpoint config.Endpoint, re
 		close(errChan)
 	}()
 
-	var totalLatency time.Duration
-	var minLatency time.Duration
-	var maxLatency time.Duration
-	var totalBytes int64
+	return r.collectResults(endpoint, result, requestChan, errChan)
+}
 
-	for detail := range requestChan {
-		result.RequestDetails = append(result.RequestDetails, detail)
-		result.TotalRequests++
-		result.StatusCodes[detail.StatusCode]++
-		result.BytesTransferred += detail.ResponseSize
+// executeRequest sends a single request and validates the response. The
+// returned error is the transport error that made the request fail, if any,
+// or the recovered panic of a request that panicked.
+// For endpoints expected to be unreachable, transport errors are validated
+// instead and no error is returned. A failed request of an endpoint with a
+// fallback is sent to the fallback, see fallBack.
+func (r *Runner) executeRequest(ctx context.Context, endpoint config.Endpoint, id int) (detail reporter.RequestDetail, err error) {
+	detail = reporter.RequestDetail{
+		ID:        id,
+		Timestamp: time.Now(),
+	}
 
-		if detail.Success {
-			result.SuccessCount++
-			if minLatency == 0 || detail.Duration < minLatency {
-				minLatency = detail.Duration
+	// deferred first to also redact the message of a recovered panic
+	defer r.redactDetail(&detail)
+
+	// a panic fails the request instead of crashing the run
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+			detail.Success = false
+			detail.ErrorMessage = err.Error()
+			detail.ErrorCategory = "panic"
+			detail.Stack = string(debug.Stack())
+			r.logger.Debug(fmt.Sprintf("%s: request %d panicked: %v\n%s", endpoint.Name, id, p, detail.Stack))
+		}
+	}()
+
+	err = r.attempt(ctx, endpoint, &detail)
+	if endpoint.Fallback != nil {
+		detail.ServedBy = reporter.ServedByPrimary
+		if !detail.Success && ctx.Err() == nil {
+			err = r.fallBack(ctx, endpoint, &detail)
+		}
+	}
+	return detail, err
+}
+
+// attempt sends a request to the endpoint and validates the response,
+// recording the outcome in detail. It returns the transport error that made
+// the request fail, if any.
+func (r *Runner) attempt(ctx context.Context, endpoint config.Endpoint, detail *reporter.RequestDetail) error {
+	_, err := r.exchange(ctx, endpoint, detail)
+	return err
+}
+
+// exchange is attempt, also returning the response body, nil when no
+// response was received.
+func (r *Runner) exchange(ctx context.Context, endpoint config.Endpoint, detail *reporter.RequestDetail) ([]byte, error) {
+	resp, body, duration, err := r.send(ctx, endpoint, detail)
+	detail.Duration = duration
+
+	if err != nil {
+		detail.Success = false
+		detail.ErrorMessage = err.Error()
+		detail.ErrorCategory = errorCategory(err)
+		if msg, ok := validator.CertificateExpired(err); ok {
+			// an expired certificate fails the handshake before expect.tls
+			// can check it, so the failure is reported like the check would
+			detail.ErrorMessage = msg
+			detail.Failures = append(detail.Failures, reporter.FailureEntry{Kind: validator.KindCertificate, Message: msg})
+		}
+		if endpoint.Expect.Unreachable {
+			validationResult := r.validateTransportError(err, duration, endpoint)
+			detail.Success = validationResult.IsValid
+			detail.ValidationErrors = validationResult.Errors
+			detail.Failures = failureEntries(validationResult.Failures)
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	detail.StatusCode = resp.StatusCode
+	detail.ResponseSize = int64(len(body))
+	detail.ContentLength = resp.ContentLength
+	detail.Headers = make(map[string]string)
+	for k, v := range resp.Header {
+		detail.Headers[k] = v[0]
+	}
+	if len(resp.Trailer) > 0 {
+		detail.Trailers = make(map[string]string)
+		for k, v := range resp.Trailer {
+			if len(v) > 0 {
+				detail.Trailers[k] = v[0]
 			}
-			if detail.Duration > maxLatency {
-				maxLatency = detail.Duration
+		}
+	}
+
+	validationResult := r.validateResponse(ctx, resp, body, duration, endpoint, detail)
+	detail.Success = validationResult.IsValid
+	detail.ValidationErrors = validationResult.Errors
+	detail.Failures = failureEntries(validationResult.Failures)
+	detail.MatchedVariant = validationResult.MatchedVariant
+	for _, d := range validationResult.Diff {
+		detail.Diff = append(detail.Diff, reporter.DiffEntry(d))
+	}
+	if endpoint.SSE != nil && endpoint.SSE.Events > 0 && detail.EventCount < endpoint.SSE.Events {
+		msg := fmt.Sprintf("expected %d events, received %d", endpoint.SSE.Events, detail.EventCount)
+		r.logger.Warn(msg)
+		detail.ValidationErrors = append(detail.ValidationErrors, msg)
+		detail.Failures = append(detail.Failures, reporter.FailureEntry{Kind: validator.KindEvents,
+			Expected: strconv.Itoa(endpoint.SSE.Events), Actual: strconv.Itoa(detail.EventCount), Message: msg})
+		detail.Success = false
+	}
+
+	return body, nil
+}
+
+// fallBack sends a request that failed against the endpoint to its fallback
+// and replaces detail with the outcome, recording why the primary target
+// failed. The duration covers both attempts, as a failing over client would
+// see it. It returns the transport error of the fallback, if any.
+func (r *Runner) fallBack(ctx context.Context, endpoint config.Endpoint, detail *reporter.RequestDetail) error {
+	reason := detail.ErrorMessage
+	if reason == "" {
+		reason = strings.Join(detail.ValidationErrors, "; ")
+	}
+	r.logger.Debug(fmt.Sprintf("%s: request %d failed, trying the fallback: %s", endpoint.Name, detail.ID, reason))
+
+	fallback := reporter.RequestDetail{
+		ID:           detail.ID,
+		Timestamp:    detail.Timestamp,
+		ServedBy:     reporter.ServedByFallback,
+		PrimaryError: reason,
+	}
+	err := r.attempt(ctx, endpoint.FallbackEndpoint(), &fallback)
+	fallback.Duration += detail.Duration
+	*detail = fallback
+	return err
+}
+
+// collectResults aggregates the request details of concurrent workers into
+// the endpoint result until both channels are closed, and returns the
+// distinct worker errors, bounded by maxDistinctErrors.
+func (r *Runner) collectResults(endpoint config.Endpoint, result *reporter.TestResult, requestChan <-chan reporter.RequestDetail, errChan <-chan error) error {
+	var totalBytes int64
+	var errs errorSet
+
+	for requestChan != nil || errChan != nil {
+		select {
+		case detail, ok := <-requestChan:
+			if !ok {
+				requestChan = nil
+				continue
 			}
-			totalLatency += detail.Duration
-			totalBytes += detail.ResponseSize
-		} else {
-			result.FailureCount++
-			for _, err := range detail.ValidationErrors {
-				result.ValidationFailures[err]++
+			r.addDetail(endpoint.Name, result, detail)
+			result.TotalRequests++
+			result.StatusCodes[detail.StatusCode]++
+			result.BytesTransferred += detail.ResponseSize
+
+			if detail.Success {
+				result.SuccessCount++
+				totalBytes += detail.ResponseSize
+			} else {
+				result.FailureCount++
+				for _, err := range detail.ValidationErrors {
+					result.ValidationFailures[err]++
+				}
+			}
+		case err, ok := <-errChan:
+			if !ok {
+				errChan = nil
+				continue
 			}
+			errs.add(err)
 		}
 	}
 
 	if result.SuccessCount > 0 {
-		result.MinLatency = minLatency
-		result.MaxLatency = maxLatency
-		result.AverageLatency = totalLatency / time.Duration(result.SuccessCount)
 		result.ResponseSizes.Min = totalBytes / int64(result.SuccessCount)
 		result.ResponseSizes.Max = totalBytes / int64(result.SuccessCount)
 		result.ResponseSizes.Avg = totalBytes / int64(result.SuccessCount)
 	}
 
-	var lastErr error
-	for err := range errChan {
-		if err != nil {
-			if lastErr == nil {
-				lastErr = err
-			} else {
-				lastErr = fmt.Errorf("%v; %v", lastErr, err)
-			}
-		}
+	return errs.err()
+}
+
+// addDetail passes a completed request to the report sink of the endpoint
+// result and to the registered result sinks.
+func (r *Runner) addDetail(endpoint string, result *reporter.TestResult, detail reporter.RequestDetail) {
+	reportSink{result: result}.Record(detail, endpoint)
+	for _, sink := range r.sinks {
+		sink.Record(detail, endpoint)
 	}
+}
 
-	return lastErr
+// send makes a request to the endpoint, retrying transport errors and
+// retryable statuses as configured. The number of retries of each kind is
+// recorded in detail. The returned duration is that of the last attempt,
+// including its wait for an in-flight slot.
+func (r *Runner) send(ctx context.Context, endpoint config.Endpoint, detail *reporter.RequestDetail) (*http.Response, []byte, time.Duration, error) {
+	for {
+		wait, resp, body, duration, err := r.attemptInSlot(ctx, endpoint, detail)
+		// like the duration, the wait is that of the last attempt
+		detail.QueueWait = wait
+		duration += wait
+
+		switch {
+		case err != nil && ctx.Err() == nil && isTransportError(err) && detail.TransportRetries < endpoint.Retry.TransportRetries:
+			detail.TransportRetries++
+			r.logger.Debug(fmt.Sprintf("%s: transport error, reconnecting (%d/%d): %v",
+				endpoint.Name, detail.TransportRetries, endpoint.Retry.TransportRetries, err))
+		case err == nil && endpoint.Retry.ShouldRetryStatus(resp.StatusCode) && detail.StatusRetries < endpoint.Retry.StatusRetries:
+			detail.StatusRetries++
+			r.logger.Debug(fmt.Sprintf("%s: status %d, retrying (%d/%d)",
+				endpoint.Name, resp.StatusCode, detail.StatusRetries, endpoint.Retry.StatusRetries))
+		default:
+			return resp, body, duration, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, nil, duration, ctx.Err()
+		case <-time.After(endpoint.Retry.Delay):
+		}
+	}
 }
 
-func (r *Runner) makeRequest(ctx context.Context, endpoint config.Endpoint) (*http.Response, []byte, time.Duration, error) {
+func (r *Runner) makeRequest(ctx context.Context, endpoint config.Endpoint, detail *reporter.RequestDetail) (*http.Response, []byte, time.Duration, error) {
+	url, err := r.interpolate(endpoint.URL)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("url: %w", err)
+	}
+	if r.config.AllowedTargets != nil {
+		if err := r.config.AllowedTargets.Check(url); err != nil {
+			return nil, nil, 0, err
+		}
+	}
+	reqBody, err := r.interpolate(endpoint.Body)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("body: %w", err)
+	}
+	// the body is recorded and shown in its JSON form, and sent encoded
+	sentBody := reqBody
+	protoRequest := endpoint.Protobuf != nil && endpoint.Protobuf.Request != ""
+	if protoRequest {
+		if sentBody, err = r.encodeProtobuf(*endpoint.Protobuf, reqBody); err != nil {
+			return nil, nil, 0, fmt.Errorf("body: %w", err)
+		}
+	}
+
 	start := time.Now()
 
-	req, err := http.NewRequestWithContext(ctx, endpoint.Method, endpoint.URL, bytes.NewBufferString(endpoint.Body))
+	method := endpoint.Method
+	if endpoint.MethodOverride != nil {
+		method = endpoint.MethodOverride.CarrierMethod()
+	}
+
+	reqCtx := ctx
+	var stopSSE context.CancelFunc
+	if endpoint.SSE != nil {
+		// cancelling the request is the only way to interrupt a blocked read
+		reqCtx, stopSSE = context.WithCancel(ctx)
+		defer stopSSE()
+	}
+
+	var timing tlsTiming
+	reqCtx = timing.trace(reqCtx)
+
+	req, err := http.NewRequestWithContext(reqCtx, method, url, bytes.NewBufferString(sentBody))
 	if err != nil {
 		return nil, nil, 0, err
 	}
 
 	for k, v := range endpoint.Headers {
-		req.Header.Add(k, v)
+		value, err := r.interpolate(v)
+		if err != nil {
+			return nil, nil, 0, fmt.Errorf("header %s: %w", k, err)
+		}
+		// Go sends req.Host and ignores a Host entry in the header map
+		if strings.EqualFold(k, "Host") {
+			req.Host = value
+			continue
+		}
+		req.Header.Add(k, value)
+	}
+	if endpoint.Host != "" {
+		host, err := r.interpolate(endpoint.Host)
+		if err != nil {
+			return nil, nil, 0, fmt.Errorf("host: %w", err)
+		}
+		req.Host = host
+	}
+
+	// an explicit Accept-Encoding stops the transport from transparently
+	// decompressing gzip, keeping the negotiated encoding observable
+	if endpoint.Expect.ContentEncoding != "" && req.Header.Get("Accept-Encoding") == "" {
+		req.Header.Set("Accept-Encoding", endpoint.Expect.ContentEncoding)
+	}
+	if endpoint.Expect.MaxCompressedRatio > 0 && req.Header.Get("Accept-Encoding") == "" {
+		req.Header.Set("Accept-Encoding", "gzip, deflate")
+	}
+
+	if endpoint.MethodOverride != nil {
+		req.Header.Set(endpoint.MethodOverride.HeaderName(), endpoint.Method)
 	}
 
-	resp, err := r.client.Do(req)
+	if protoRequest && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", protobuf.ContentType)
+	}
+	if endpoint.Protobuf != nil && endpoint.Protobuf.Response != "" && req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", protobuf.ContentType)
+	}
+
+	if endpoint.HMAC != nil {
+		signature, err := signBody(*endpoint.HMAC, sentBody)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		header := endpoint.HMAC.Header
+		if header == "" {
+			header = DefaultSignatureHeader
+		}
+		req.Header.Set(header, signature)
+	}
+
+	detail.Request = r.sentRequest(req, reqBody)
+
+	if r.replay != nil {
+		return r.replay.Load(req.Method, url, reqBody)
+	}
+
+	resp, err := r.clientFor(endpoint).Do(req)
+	timing.record(detail)
 	if err != nil {
 		return nil, nil, time.Since(start), err
 	}
 	defer resp.Body.Close()
+	if resp.Request != nil && resp.Request != req {
+		// followed a redirect, which only resends the body when the method is kept
+		redirectBody := ""
+		if resp.Request.Method == req.Method {
+			redirectBody = reqBody
+		}
+		detail.Request = r.sentRequest(resp.Request, redirectBody)
+	}
 
-	body, err := io.ReadAll(resp.Body)
+	var body []byte
+	if endpoint.SSE != nil {
+		body, err = readSSE(ctx, resp.Body, *endpoint.SSE, start, detail, stopSSE)
+	} else {
+		// a body shorter than its Content-Length is kept for the content length
+		// check; resp.Trailer is filled in once the body has been read to the end
+		body, err = io.ReadAll(resp.Body)
+		if errors.Is(err, io.ErrUnexpectedEOF) && endpoint.Expect.CheckContentLength {
+			err = nil
+		}
+	}
 	if err != nil {
 		return nil, nil, time.Since(start), err
 	}
+	duration := time.Since(start)
+
+	if r.record != nil {
+		if err := r.record.Save(req.Method, url, reqBody, resp, body, duration); err != nil {
+			r.logger.Warn(fmt.Sprintf("failed to record response: %v", err))
+		}
+	}
+	if r.savesResponses(endpoint) {
+		path, err := saveResponse(endpoint, detail.ID, body)
+		if err != nil {
+			r.logger.Warn(fmt.Sprintf("%s: %v", endpoint.Name, err))
+		}
+		detail.SavedTo = path
+	}
+
+	return resp, body, duration, nil
+}
+
+// expectsRedirect reports whether the expectation validates redirect responses
+// themselves, in which case redirects are not followed.
+func expectsRedirect(expect config.Expectation) bool {
+	if expect.AllowRedirects || expect.RedirectLocation != nil {
+		return true
+	}
+	for _, block := range expect.ByStatus {
+		if block.RedirectLocation != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// validateResponse validates the response against the endpoint expectations,
+// with captured variable references resolved. The Content-Length is checked
+// against the bytes read when configured, compressed bodies are decompressed,
+// recording their size in detail and checking the compression ratio when
+// configured, protobuf bodies are decoded to JSON, and the body is transcoded
+// to UTF-8 according to the charset declared in the Content-Type header. When
+// the response is valid, the endpoint captures are extracted into the runner's
+// variable store.
+func (r *Runner) validateResponse(ctx context.Context, resp *http.Response, body []byte, duration time.Duration, endpoint config.Endpoint, detail *reporter.RequestDetail) validator.ValidationResult {
+	expect, resolveErrs := r.vars.resolveExpectation(endpoint.Expect)
+	var failures []validator.ValidationError
+	for _, err := range resolveErrs {
+		failures = append(failures, validator.ValidationError{Kind: validator.KindCapture, Message: err.Error()})
+	}
+
+	if endpoint.Expect.CheckContentLength && resp.ContentLength >= 0 && resp.ContentLength != int64(len(body)) {
+		failures = append(failures, validator.ValidationError{
+			Kind:     validator.KindContentLength,
+			Expected: strconv.FormatInt(resp.ContentLength, 10),
+			Actual:   strconv.Itoa(len(body)),
+			Message:  fmt.Sprintf("content length mismatch: Content-Length is %d, read %d bytes", resp.ContentLength, len(body)),
+		})
+	}
+
+	contentEncoding := resp.Header.Get("Content-Encoding")
+	decompressed, err := decompressBody(contentEncoding, body)
+	if err == nil {
+		if compressed(contentEncoding) {
+			detail.DecompressedSize = int64(len(decompressed))
+		}
+		if maxRatio := endpoint.Expect.MaxCompressedRatio; maxRatio > 0 {
+			if failure, ok := checkCompressedRatio(maxRatio, contentEncoding, len(body), len(decompressed)); !ok {
+				failures = append(failures, failure)
+			}
+		}
+		body = decompressed
+	} else if readsBody(endpoint) || endpoint.Expect.MaxCompressedRatio > 0 {
+		failures = append(failures, validator.ValidationError{Kind: validator.KindBody, Message: err.Error()})
+	}
 
-	return resp, body, time.Since(start), nil
+	// other responses, e.g. JSON error bodies, are validated as they are
+	if p := endpoint.Protobuf; p != nil && p.Response != "" && protobuf.IsContentType(resp.Header.Get("Content-Type")) {
+		if decoded, err := r.decodeProtobuf(*p, body); err == nil {
+			body = decoded
+		} else if readsBody(endpoint) {
+			failures = append(failures, validator.ValidationError{Kind: validator.KindBody,
+				Message: fmt.Sprintf("failed to decode protobuf response body: %v", err)})
+		}
+	}
+
+	body, err = decodeBody(resp.Header.Get("Content-Type"), body)
+	if err != nil {
+		failures = append(failures, validator.ValidationError{Kind: validator.KindBody, Message: err.Error()})
+	}
+
+	v := validator.NewValidator(expect, r.config.Redact, r.logger)
+	v.SetMaxJSONSize(r.opts.MaxJSONSize)
+	result := v.Validate(ctx, resp, body, duration)
+
+	if result.IsValid && len(failures) == 0 {
+		for _, err := range r.vars.capture(ctx, endpoint.Capture, body, r.opts.MaxJSONSize) {
+			failures = append(failures, validator.ValidationError{Kind: validator.KindCapture, Message: err.Error()})
+		}
+	}
+	for _, failure := range failures {
+		result.AddError(failure)
+	}
+	return result
+}
+
+// failureEntries converts the typed validation errors of a result for the
+// request details.
+func failureEntries(failures []validator.ValidationError) []reporter.FailureEntry {
+	var entries []reporter.FailureEntry
+	for _, f := range failures {
+		entries = append(entries, reporter.FailureEntry(f))
+	}
+	return entries
+}
+
+// validateTransportError validates a request that failed before a response was
+// received, which passes only for endpoints expected to be unreachable.
+func (r *Runner) validateTransportError(err error, duration time.Duration, endpoint config.Endpoint) validator.ValidationResult {
+	v := validator.NewValidator(endpoint.Expect, r.config.Redact, r.logger)
+	return v.ValidateTransportError(err, duration)
+}
+
+// decodeBody transcodes a response body to UTF-8 using the charset parameter
+// of the given Content-Type header. Bodies without a declared charset, or
+// declared as UTF-8, are returned unchanged.
+func decodeBody(contentType string, body []byte) ([]byte, error) {
+	if contentType == "" {
+		return body, nil
+	}
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return body, nil
+	}
+	name := params["charset"]
+	if name == "" {
+		return body, nil
+	}
+
+	enc, err := htmlindex.Get(name)
+	if err != nil {
+		return body, fmt.Errorf("unsupported response charset %s", name)
+	}
+	if enc == encoding.Nop || enc == unicode.UTF8 {
+		return body, nil
+	}
+
+	decoded, err := enc.NewDecoder().Bytes(body)
+	if err != nil {
+		return body, fmt.Errorf("failed to decode %s response body: %w", name, err)
+	}
+	return decoded, nil
+}
+
+// decompressBody decompresses a response body sent with a gzip or deflate
+// Content-Encoding. Other encodings, such as br, cannot be decoded.
+func decompressBody(contentEncoding string, body []byte) ([]byte, error) {
+	var reader io.ReadCloser
+	var err error
+	switch strings.ToLower(strings.TrimSpace(contentEncoding)) {
+	case "", "identity":
+		return body, nil
+	case "gzip", "x-gzip":
+		reader, err = gzip.NewReader(bytes.NewReader(body))
+	case "deflate":
+		reader, err = zlib.NewReader(bytes.NewReader(body))
+	default:
+		return body, fmt.Errorf("cannot decode %s response body", contentEncoding)
+	}
+	if err != nil {
+		return body, fmt.Errorf("failed to decompress %s response body: %w", contentEncoding, err)
+	}
+	defer reader.Close()
+
+	decompressed, err := io.ReadAll(reader)
+	if err != nil {
+		return body, fmt.Errorf("failed to decompress %s response body: %w", contentEncoding, err)
+	}
+	return decompressed, nil
+}
+
+// compressed reports whether a Content-Encoding compresses the body.
+func compressed(contentEncoding string) bool {
+	encoding := strings.ToLower(strings.TrimSpace(contentEncoding))
+	return encoding != "" && encoding != "identity"
+}
+
+// checkCompressedRatio checks that a response body compressed to at most maxRatio
+// of its decompressed size, reporting the observed ratio otherwise.
+func checkCompressedRatio(maxRatio float64, contentEncoding string, size, decompressedSize int) (validator.ValidationError, bool) {
+	expected := strconv.FormatFloat(maxRatio, 'f', -1, 64)
+	if !compressed(contentEncoding) {
+		return validator.ValidationError{Kind: validator.KindCompression, Expected: expected,
+			Message: fmt.Sprintf("expected a compressed response with a ratio of at most %s, got no Content-Encoding", expected)}, false
+	}
+	if decompressedSize == 0 {
+		return validator.ValidationError{}, true
+	}
+	ratio := float64(size) / float64(decompressedSize)
+	if ratio <= maxRatio {
+		return validator.ValidationError{}, true
+	}
+	actual := strconv.FormatFloat(ratio, 'f', 3, 64)
+	return validator.ValidationError{Kind: validator.KindCompression, Expected: expected, Actual: actual,
+		Message: fmt.Sprintf("compressed ratio %s (%s: %d of %d bytes), expected at most %s", actual, contentEncoding, size, decompressedSize, expected)}, false
 }
 
-func (r *Runner) validateResponse(resp *http.Response, body []byte, duration time.Duration, endpoint config.Endpoint) validator.ValidationResult {
-	v := validator.NewValidator(endpoint.Expect.MaxTime, endpoint.Expect.Status)
-	return v.Validate(resp, body, duration, endpoint.Expect.Values)
+// readsBody reports whether the expectations or captures of the endpoint
+// need the content of the response body.
+func readsBody(endpoint config.Endpoint) bool {
+	expect := endpoint.Expect
+	return len(endpoint.Capture) > 0 || expect.JSON || len(expect.Values) > 0 ||
+		len(expect.AnyOf) > 0 || expect.Match != nil || len(expect.BodyOneOf) > 0 || len(expect.ByStatus) > 0
 }
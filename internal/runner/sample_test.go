@@ -0,0 +1,23 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/JakubPluta/tmago/internal/config"
+)
+
+func TestNewRunnerSamplesWithoutChangingTheConfig(t *testing.T) {
+	cfg := &config.Config{Endpoints: []config.Endpoint{
+		{Name: "load", Concurrent: config.ConcurrentConfig{Users: 10, Total: 100}},
+	}}
+	r, err := NewRunner(cfg, Options{Sample: 0.5, NoFileLog: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := r.config.Endpoints[0].Concurrent; c.Users != 5 || c.Total != 50 {
+		t.Errorf("the runner runs %d users and %d requests, want 5 and 50", c.Users, c.Total)
+	}
+	if c := cfg.Endpoints[0].Concurrent; c.Users != 10 || c.Total != 100 {
+		t.Errorf("the config of the caller was changed to %d users and %d requests", c.Users, c.Total)
+	}
+}
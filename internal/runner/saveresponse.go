@@ -0,0 +1,43 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/JakubPluta/tmago/internal/config"
+)
+
+// unsafePathChars matches runs of characters not allowed in the endpoint name
+// of a saved response path.
+var unsafePathChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// savesResponses reports whether the responses of the endpoint are saved to
+// files. Those of concurrent endpoints, including endpoints of a scenario,
+// are only saved when explicitly allowed.
+func (r *Runner) savesResponses(endpoint config.Endpoint) bool {
+	save := endpoint.SaveResponseTo
+	if save == nil {
+		return false
+	}
+	concurrent := endpoint.Concurrent.IsConcurrent() || (r.config.Scenario != nil && !r.opts.Smoke)
+	return save.AllowConcurrent || !concurrent
+}
+
+// saveResponse writes the body of a response of the endpoint to its
+// saveResponseTo path and returns the path of the file.
+func saveResponse(endpoint config.Endpoint, id int, body []byte) (string, error) {
+	name := strings.Trim(unsafePathChars.ReplaceAllString(endpoint.Name, "-"), "-")
+	path := strings.NewReplacer("{name}", name, "{id}", strconv.Itoa(id)).Replace(endpoint.SaveResponseTo.Path)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create response directory: %w", err)
+	}
+	if err := os.WriteFile(path, body, 0644); err != nil {
+		return "", fmt.Errorf("failed to save response: %w", err)
+	}
+	return path, nil
+}
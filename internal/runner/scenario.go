@@ -0,0 +1,121 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/JakubPluta/tmago/internal/config"
+	"github.com/JakubPluta/tmago/internal/reporter"
+)
+
+// pickWeighted returns the index of an endpoint chosen at random with
+// probability proportional to its weight.
+func (r *Runner) pickWeighted(endpoints []config.WeightedEndpoint, totalWeight int) int {
+	n := r.random.Intn(totalWeight)
+	for i, we := range endpoints {
+		if n < we.Weight {
+			return i
+		}
+		n -= we.Weight
+	}
+	return len(endpoints) - 1
+}
+
+// runScenario runs a mixed workload: the scenario's virtual users send its
+// total number of requests between them, picking the endpoint of every request
+// by weight from the seeded random source. Each endpoint gets its own result,
+// aggregated from the requests that were sent to it.
+//
+// The pre-request script of every endpoint runs once before the users start,
+// like it does before the requests of an endpoint run on its own. An endpoint
+// whose script fails records the error and gets no requests; the others share
+// the total by their weights.
+func (r *Runner) runScenario(ctx context.Context, scenario config.Scenario) {
+	endpoints := make([]config.Endpoint, len(scenario.Endpoints))
+	results := make([]reporter.TestResult, len(scenario.Endpoints))
+	requestChans := make([]chan reporter.RequestDetail, len(scenario.Endpoints))
+	errChans := make([]chan error, len(scenario.Endpoints))
+	weighted := append([]config.WeightedEndpoint(nil), scenario.Endpoints...)
+	totalWeight := 0
+	for i, we := range scenario.Endpoints {
+		endpoints[i], _ = r.config.Endpoint(we.Name)
+		results[i] = newResult(endpoints[i])
+		results[i].IsConcurrent = true
+		results[i].ConcurrentUsers = int(scenario.Users)
+		requestChans[i] = make(chan reporter.RequestDetail, scenario.Users)
+		errChans[i] = make(chan error, scenario.Users)
+		r.logger.TestStarted(we.Name, endpoints[i].Method, endpoints[i].URL)
+
+		if endpoints[i].PreScript != nil {
+			if err := r.runScript(ctx, *endpoints[i].PreScript); err != nil {
+				r.logger.RequestFailed(-1, we.Name, err)
+				results[i].Errors = append(results[i].Errors, err.Error())
+				weighted[i].Weight = 0
+			}
+		}
+		totalWeight += weighted[i].Weight
+	}
+	r.logger.Info(fmt.Sprintf("Running scenario with %d users and %d requests across %d endpoints",
+		scenario.Users, scenario.Total, len(endpoints)))
+
+	// one collector per endpoint
+	var collectors sync.WaitGroup
+	collectErrs := make([]error, len(endpoints))
+	for i := range endpoints {
+		collectors.Add(1)
+		go func(i int) {
+			defer collectors.Done()
+			collectErrs[i] = r.collectResults(endpoints[i], &results[i], requestChans[i], errChans[i])
+		}(i)
+	}
+
+	var workers sync.WaitGroup
+	var sent int64
+	for u := 0; u < int(scenario.Users) && totalWeight > 0; u++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for {
+				id := atomic.AddInt64(&sent, 1)
+				if id > int64(scenario.Total) {
+					return
+				}
+				i := r.pickWeighted(weighted, totalWeight)
+				if ctx.Err() != nil {
+					errChans[i] <- ctx.Err()
+					return
+				}
+
+				r.jitter(ctx, scenario.Jitter)
+				detail, err := r.executeRequest(ctx, endpoints[i], int(id))
+				requestChans[i] <- detail
+				if err != nil {
+					errChans[i] <- err
+					continue
+				}
+
+				if scenario.Delay > 0 {
+					time.Sleep(scenario.Delay)
+				}
+			}
+		}()
+	}
+
+	workers.Wait()
+	for i := range endpoints {
+		close(requestChans[i])
+		close(errChans[i])
+	}
+	collectors.Wait()
+
+	for i := range results {
+		if collectErrs[i] != nil {
+			r.logger.RequestFailed(-1, endpoints[i].Name, collectErrs[i])
+			results[i].Errors = append(results[i].Errors, collectErrs[i].Error())
+		}
+		r.finishResult(&results[i], endpoints[i].Expect.MinRPS)
+	}
+}
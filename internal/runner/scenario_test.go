@@ -0,0 +1,61 @@
+package runner
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestRunScenarioRunsPreScripts(t *testing.T) {
+	var mu sync.Mutex
+	tokens := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		tokens[r.URL.Path+" "+r.Header.Get("Authorization")]++
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	report, err := runConfig(t, `
+endpoints:
+  - name: list
+    url: `+server.URL+`/list
+    method: GET
+    headers:
+      Authorization: "Bearer {{captured.token}}"
+    preScript:
+      command: echo secret
+      var: token
+  - name: broken
+    url: `+server.URL+`/broken
+    method: GET
+    preScript:
+      command: exit 1
+      var: other
+scenario:
+  users: 2
+  total: 10
+  endpoints:
+    - name: list
+      weight: 1
+    - name: broken
+      weight: 1
+`, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result := endpointResult(t, report, "list"); result.SuccessCount != 10 {
+		t.Errorf("list made %d successful requests, want all 10: %v", result.SuccessCount, result.Errors)
+	}
+	broken := endpointResult(t, report, "broken")
+	if broken.TotalRequests != 0 || len(broken.Errors) == 0 {
+		t.Errorf("broken made %d requests with errors %v, want none and the script error", broken.TotalRequests, broken.Errors)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if got := tokens["/list Bearer secret"]; got != 10 {
+		t.Errorf("requests with the script token = %d, want 10 (all requests: %v)", got, tokens)
+	}
+}
@@ -0,0 +1,57 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/JakubPluta/tmago/internal/config"
+)
+
+// DefaultScriptTimeout bounds a script that does not configure its own timeout.
+const DefaultScriptTimeout = 10 * time.Second
+
+// runScript executes a pre-request script with `sh -c` and stores its trimmed
+// stdout in the variable store under the script's variable name.
+//
+// The command is killed when it exceeds its timeout. Its output must be a
+// single line without control characters, so it can safely be interpolated
+// into URLs and headers.
+func (r *Runner) runScript(ctx context.Context, script config.ScriptConfig) error {
+	timeout := script.Timeout
+	if timeout <= 0 {
+		timeout = DefaultScriptTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", script.Command)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// don't wait for children of the shell that keep the output pipes open
+	cmd.WaitDelay = 100 * time.Millisecond
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("script for %s timed out after %s", script.Var, timeout)
+		}
+		return fmt.Errorf("script for %s failed: %w: %s", script.Var, err, strings.TrimSpace(stderr.String()))
+	}
+
+	value := strings.TrimSpace(stdout.String())
+	if value == "" {
+		return fmt.Errorf("script for %s produced no output", script.Var)
+	}
+	if strings.IndexFunc(value, unicode.IsControl) >= 0 {
+		return fmt.Errorf("script for %s output contains line breaks or control characters", script.Var)
+	}
+
+	r.vars.Set(script.Var, value)
+	r.logger.Debug(fmt.Sprintf("script for %s completed", script.Var))
+	return nil
+}
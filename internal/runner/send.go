@@ -0,0 +1,22 @@
+package runner
+
+import (
+	"context"
+	"time"
+
+	"github.com/JakubPluta/tmago/internal/config"
+	"github.com/JakubPluta/tmago/internal/reporter"
+)
+
+// Send sends a single request to the endpoint, with its transport and status
+// retries but without a fallback, and validates the response against its
+// expectation, for interactive use. Variables captured by the endpoint are
+// available to later requests. It returns the request detail, redacted like
+// in the reports, and the response body as received, nil without a response.
+// The request is not added to the report.
+func (r *Runner) Send(ctx context.Context, endpoint config.Endpoint, id int) (reporter.RequestDetail, []byte, error) {
+	detail := reporter.RequestDetail{ID: id, Timestamp: time.Now()}
+	body, err := r.exchange(ctx, endpoint, &detail)
+	r.redactDetail(&detail)
+	return detail, body, err
+}
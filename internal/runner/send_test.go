@@ -0,0 +1,93 @@
+package runner
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/JakubPluta/tmago/internal/config"
+	"github.com/JakubPluta/tmago/internal/reporter"
+)
+
+func TestSendRetriesOnlyTransportErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	refused := server.URL
+	server.Close()
+
+	tests := []struct {
+		name        string
+		url         string
+		wantRetries int
+	}{
+		{name: "connection refused", url: refused, wantRetries: 2},
+		{name: "missing captured variable", url: refused + "/{{captured.missing}}", wantRetries: 0},
+		{name: "invalid URL", url: "http://[::1", wantRetries: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := config.Endpoint{
+				Name:   "retried",
+				URL:    tt.url,
+				Method: http.MethodGet,
+				Retry:  config.RetryConfig{TransportRetries: 2},
+			}
+			r, err := NewRunner(&config.Config{Endpoints: []config.Endpoint{endpoint}}, Options{NoFileLog: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var detail reporter.RequestDetail
+			if _, _, _, err := r.send(context.Background(), endpoint, &detail); err == nil {
+				t.Fatal("expected an error")
+			}
+			if detail.TransportRetries != tt.wantRetries {
+				t.Errorf("retried %d times, want %d", detail.TransportRetries, tt.wantRetries)
+			}
+		})
+	}
+}
+
+func TestSendRetriesADroppedConnectionAndThenAStatus(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			// drop the connection without a response
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			conn.Close()
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	endpoint := config.Endpoint{
+		Name:   "flaky",
+		URL:    server.URL,
+		Method: http.MethodGet,
+		Retry:  config.RetryConfig{TransportRetries: 2, StatusRetries: 2},
+	}
+	r, err := NewRunner(&config.Config{Endpoints: []config.Endpoint{endpoint}}, Options{NoFileLog: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var detail reporter.RequestDetail
+	resp, _, _, err := r.send(context.Background(), endpoint, &detail)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("final status %d, want 200", resp.StatusCode)
+	}
+	if detail.TransportRetries != 1 || detail.StatusRetries != 1 {
+		t.Errorf("%d transport and %d status retries, want 1 and 1", detail.TransportRetries, detail.StatusRetries)
+	}
+	if n := calls.Load(); n != 3 {
+		t.Errorf("the server got %d requests, want 3", n)
+	}
+}
@@ -0,0 +1,57 @@
+package runner
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"github.com/JakubPluta/tmago/internal/config"
+)
+
+// shuffleEndpoints puts the endpoints of the config in a random order drawn
+// from the seed of the run, in which every endpoint still comes after the
+// endpoints it depends on, including those capturing the variables it
+// references, and logs the order. A separate source is used so
+// that the generated test data does not change with the shuffle.
+func (r *Runner) shuffleEndpoints() {
+	rnd := rand.New(rand.NewSource(r.seed))
+	remaining := append([]config.Endpoint(nil), r.config.Endpoints...)
+	order := make([]config.Endpoint, 0, len(remaining))
+	placed := make(map[string]bool, len(remaining))
+	graph := r.config.DependencyGraph()
+
+	for len(remaining) > 0 {
+		var ready []int
+		for i, endpoint := range remaining {
+			if dependenciesPlaced(graph[endpoint.Name], placed) {
+				ready = append(ready, i)
+			}
+		}
+		if len(ready) == 0 {
+			// a cycle, rejected by the config validation
+			order = append(order, remaining...)
+			break
+		}
+		i := ready[rnd.Intn(len(ready))]
+		order = append(order, remaining[i])
+		placed[remaining[i].Name] = true
+		remaining = append(remaining[:i], remaining[i+1:]...)
+	}
+	r.config.Endpoints = order
+
+	names := make([]string, len(order))
+	for i, endpoint := range order {
+		names[i] = endpoint.Name
+	}
+	r.logger.Info(fmt.Sprintf("Shuffled endpoint order: %s", strings.Join(names, ", ")))
+}
+
+// dependenciesPlaced reports whether every endpoint in deps is placed.
+func dependenciesPlaced(deps []string, placed map[string]bool) bool {
+	for _, dep := range deps {
+		if !placed[dep] {
+			return false
+		}
+	}
+	return true
+}
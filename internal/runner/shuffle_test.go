@@ -0,0 +1,56 @@
+package runner
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/JakubPluta/tmago/internal/config"
+)
+
+// shuffledNames shuffles the endpoints with the seed and returns their names.
+func shuffledNames(t *testing.T, endpoints []config.Endpoint, seed int64) []string {
+	t.Helper()
+	r, err := NewRunner(&config.Config{Endpoints: endpoints}, Options{Seed: seed, NoFileLog: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.shuffleEndpoints()
+	names := make([]string, len(r.config.Endpoints))
+	for i, endpoint := range r.config.Endpoints {
+		names[i] = endpoint.Name
+	}
+	return names
+}
+
+func TestShuffleEndpointsKeepsDependencies(t *testing.T) {
+	endpoints := []config.Endpoint{
+		{Name: "login", Capture: []config.Capture{{Name: "token", Path: "token"}}},
+		{Name: "profile", Headers: map[string]string{"Authorization": "Bearer {{captured.token}}"}},
+		{Name: "health"},
+		{Name: "version"},
+		{Name: "cleanup", DependsOn: []string{"health"}},
+	}
+
+	orders := map[string]bool{}
+	for seed := int64(1); seed <= 30; seed++ {
+		names := shuffledNames(t, endpoints, seed)
+		position := make(map[string]int, len(names))
+		for i, name := range names {
+			position[name] = i
+		}
+		if position["profile"] < position["login"] {
+			t.Errorf("seed %d: %v runs profile before login, whose token it references", seed, names)
+		}
+		if position["cleanup"] < position["health"] {
+			t.Errorf("seed %d: %v runs cleanup before health, which it depends on", seed, names)
+		}
+		orders[names[0]+names[1]+names[2]+names[3]+names[4]] = true
+	}
+	if len(orders) < 2 {
+		t.Errorf("30 seeds produced a single order")
+	}
+
+	if a, b := shuffledNames(t, endpoints, 7), shuffledNames(t, endpoints, 7); !reflect.DeepEqual(a, b) {
+		t.Errorf("the same seed produced %v and %v", a, b)
+	}
+}
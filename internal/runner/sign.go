@@ -0,0 +1,37 @@
+package runner
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"strings"
+
+	"github.com/JakubPluta/tmago/internal/config"
+)
+
+// DefaultSignatureHeader is the header carrying the HMAC signature when none is configured.
+const DefaultSignatureHeader = "X-Signature"
+
+// signBody computes the hex encoded HMAC of body with the configured secret
+// and algorithm (sha256 by default), prefixed with the configured prefix.
+func signBody(cfg config.HMACConfig, body string) (string, error) {
+	var newHash func() hash.Hash
+	switch strings.ToLower(cfg.Algorithm) {
+	case "", "sha256":
+		newHash = sha256.New
+	case "sha1":
+		newHash = sha1.New
+	case "sha512":
+		newHash = sha512.New
+	default:
+		return "", fmt.Errorf("unsupported hmac algorithm %s", cfg.Algorithm)
+	}
+
+	mac := hmac.New(newHash, []byte(cfg.Secret))
+	mac.Write([]byte(body))
+	return cfg.Prefix + hex.EncodeToString(mac.Sum(nil)), nil
+}
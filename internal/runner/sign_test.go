@@ -0,0 +1,24 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/JakubPluta/tmago/internal/config"
+)
+
+func TestSignBody(t *testing.T) {
+	// Test case 2 of RFC 4231
+	cfg := config.HMACConfig{Secret: "Jefe", Prefix: "sha256="}
+	got, err := signBody(cfg, "what do ya want for nothing?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "sha256=5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"
+	if got != want {
+		t.Errorf("signature = %s, want %s", got, want)
+	}
+
+	if _, err := signBody(config.HMACConfig{Secret: "Jefe", Algorithm: "md5"}, ""); err == nil {
+		t.Error("expected an error for an unsupported algorithm")
+	}
+}
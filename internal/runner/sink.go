@@ -0,0 +1,46 @@
+package runner
+
+import (
+	"fmt"
+
+	"github.com/JakubPluta/tmago/internal/logger"
+	"github.com/JakubPluta/tmago/internal/reporter"
+)
+
+// ResultSink receives every completed request as soon as it completes, e.g. to
+// forward it to a datastore in real time. The report is built from the same
+// requests independently of the sinks. Record may be called from several
+// goroutines and should not block, as it holds up the collection of results.
+type ResultSink interface {
+	Record(detail reporter.RequestDetail, endpointName string)
+}
+
+// reportSink collects the completed requests of an endpoint into its result,
+// from which the reports are built. It is the default sink, ahead of the
+// registered ones.
+type reportSink struct {
+	result *reporter.TestResult
+}
+
+func (s reportSink) Record(detail reporter.RequestDetail, endpointName string) {
+	s.result.RequestDetails = append(s.result.RequestDetails, detail)
+}
+
+// eventSink writes the completed requests to an EventWriter, logging write
+// errors instead of failing the run.
+type eventSink struct {
+	events *EventWriter
+	logger *logger.Logger
+}
+
+func (s eventSink) Record(detail reporter.RequestDetail, endpointName string) {
+	if err := s.events.Write(endpointName, detail); err != nil {
+		s.logger.Warn(fmt.Sprintf("failed to write request event: %v", err))
+	}
+}
+
+// AddSink registers a sink receiving every completed request, in addition to
+// the report. It must be called before Run.
+func (r *Runner) AddSink(sink ResultSink) {
+	r.sinks = append(r.sinks, sink)
+}
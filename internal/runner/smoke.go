@@ -0,0 +1,65 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/JakubPluta/tmago/internal/config"
+	"github.com/JakubPluta/tmago/internal/reporter"
+)
+
+// ErrSmokeFailed is returned by Run in smoke mode when an endpoint failed.
+var ErrSmokeFailed = errors.New("smoke test failed")
+
+// runSmoke sends a single request to every endpoint, without concurrency or
+// retries, and prints a pass/fail line per endpoint. The scenario, if any, is
+// not run.
+func (r *Runner) runSmoke(ctx context.Context) error {
+	// stdout carries the event stream with --jsonl
+	var out io.Writer = os.Stdout
+	if r.opts.Events != nil {
+		out = os.Stderr
+	}
+
+	failed := 0
+	for _, endpoint := range r.config.Endpoints {
+		endpoint.Concurrent = config.ConcurrentConfig{}
+		endpoint.Retry = config.RetryConfig{}
+
+		result := r.runEndpoint(ctx, endpoint)
+		if result.SuccessCount > 0 {
+			fmt.Fprintf(out, "✅ PASS %s %s %s\n", endpoint.Name, endpoint.Method, smokeSummary(result))
+			continue
+		}
+		failed++
+		fmt.Fprintf(out, "❌ FAIL %s %s %s\n", endpoint.Name, endpoint.Method, smokeSummary(result))
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%w: %d of %d endpoints failed", ErrSmokeFailed, failed, len(r.config.Endpoints))
+	}
+	return nil
+}
+
+// smokeSummary describes the single request of a smoke test result.
+func smokeSummary(result reporter.TestResult) string {
+	if len(result.RequestDetails) == 0 {
+		if len(result.Errors) > 0 {
+			return result.Errors[0]
+		}
+		return "no request sent"
+	}
+
+	detail := result.RequestDetails[len(result.RequestDetails)-1]
+	switch {
+	case detail.ErrorMessage != "":
+		return detail.ErrorMessage
+	case len(detail.ValidationErrors) > 0:
+		return fmt.Sprintf("(%d, %s): %v", detail.StatusCode, detail.Duration.Round(time.Millisecond), detail.ValidationErrors)
+	}
+	return fmt.Sprintf("(%d, %s)", detail.StatusCode, detail.Duration.Round(time.Millisecond))
+}
@@ -0,0 +1,90 @@
+package runner
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/JakubPluta/tmago/internal/config"
+	"github.com/JakubPluta/tmago/internal/reporter"
+)
+
+// sseEvent is a server-sent event as exposed to value checks. Data is decoded
+// when it is valid JSON and kept as a string otherwise.
+type sseEvent struct {
+	Event string      `json:"event,omitempty"`
+	ID    string      `json:"id,omitempty"`
+	Data  interface{} `json:"data"`
+}
+
+// readSSE reads server-sent events from body until the configured number of
+// events was received, the configured duration elapsed or the stream ended.
+// When the duration elapses, stop is called to cancel the request and
+// interrupt the read. The arrival of every event, measured from start, is
+// recorded in detail, and the events are returned as a JSON array for the
+// value checks.
+func readSSE(ctx context.Context, body io.Reader, cfg config.SSEConfig, start time.Time, detail *reporter.RequestDetail, stop func()) ([]byte, error) {
+	var expired atomic.Bool
+	timer := time.AfterFunc(cfg.ReadDuration(), func() {
+		expired.Store(true)
+		stop()
+	})
+	defer timer.Stop()
+
+	events := make([]sseEvent, 0)
+	var event sseEvent
+	var data []string
+	reader := bufio.NewReader(body)
+	for cfg.Events == 0 || len(events) < cfg.Events {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if errors.Is(err, io.EOF) || expired.Load() {
+				break
+			}
+			return nil, err
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			// a blank line dispatches the event, if it has data
+			if data != nil {
+				event.Data = sseData(strings.Join(data, "\n"))
+				events = append(events, event)
+				detail.EventLatencies = append(detail.EventLatencies, time.Since(start))
+			}
+			event, data = sseEvent{}, nil
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "data":
+			data = append(data, value)
+		case "event":
+			event.Event = value
+		case "id":
+			event.ID = value
+		}
+	}
+
+	detail.EventCount = len(events)
+	return json.Marshal(events)
+}
+
+// sseData decodes the data of an event when it is JSON.
+func sseData(data string) interface{} {
+	var v interface{}
+	if err := json.Unmarshal([]byte(data), &v); err == nil {
+		return v
+	}
+	return data
+}
@@ -0,0 +1,75 @@
+package runner
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSSEReadsEvents(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := 1; i <= 3; i++ {
+			fmt.Fprintf(w, "event: tick\nid: %d\ndata: {\"n\": %d}\n\n", i, i)
+			w.(http.Flusher).Flush()
+		}
+		if r.URL.Path == "/open" {
+			// keep the stream open until the client gives up
+			<-r.Context().Done()
+		}
+	}))
+	defer server.Close()
+
+	report, err := runConfig(t, `
+endpoints:
+  - name: count
+    url: `+server.URL+`/open
+    method: GET
+    sse:
+      events: 2
+    expect:
+      values:
+        - path: 1.data.n
+          value: 2
+        - path: 1.id
+          value: "2"
+          valueType: string
+  - name: ended
+    url: `+server.URL+`/closed
+    method: GET
+    sse: true
+  - name: short
+    url: `+server.URL+`/open
+    method: GET
+    sse:
+      events: 5
+      duration: 200ms
+`, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	count := endpointResult(t, report, "count").RequestDetails[0]
+	if !count.Success || count.EventCount != 2 || len(count.EventLatencies) != 2 {
+		t.Errorf("count: success %v, %d events, %d latencies, errors %v",
+			count.Success, count.EventCount, len(count.EventLatencies), count.ValidationErrors)
+	}
+
+	ended := endpointResult(t, report, "ended").RequestDetails[0]
+	if !ended.Success || ended.EventCount != 3 {
+		t.Errorf("ended: success %v, %d events, errors %v", ended.Success, ended.EventCount, ended.ValidationErrors)
+	}
+
+	short := endpointResult(t, report, "short").RequestDetails[0]
+	if short.Success || short.EventCount != 3 {
+		t.Errorf("short: success %v, %d events", short.Success, short.EventCount)
+	}
+	if want := "expected 5 events, received 3"; len(short.ValidationErrors) != 1 || short.ValidationErrors[0] != want {
+		t.Errorf("short errors = %q, want %q", short.ValidationErrors, want)
+	}
+	if short.Duration < 200*time.Millisecond || short.Duration > 2*time.Second {
+		t.Errorf("short duration = %s, want the read duration", short.Duration)
+	}
+}
@@ -0,0 +1,113 @@
+package runner
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/JakubPluta/tmago/internal/config"
+)
+
+// thinkTime draws a pause from the think time distribution using the seeded
+// random source.
+func (r *Runner) thinkTime(t config.ThinkTime) time.Duration {
+	span := t.Max - t.Min
+	if span <= 0 {
+		return t.Min
+	}
+
+	if t.Distribution == config.ThinkTimeExponential {
+		d := t.Min + time.Duration(r.random.ExpFloat64()*float64(span)/2)
+		if d > t.Max {
+			d = t.Max
+		}
+		return d
+	}
+	return t.Min + time.Duration(r.random.Float64()*float64(span))
+}
+
+// pause waits between two requests of a virtual user for the configured delay
+// plus a sampled think time, or the pause of the rate controller when the
+// endpoint has a target throughput, returning early when ctx is cancelled.
+func (r *Runner) pause(ctx context.Context, c config.ConcurrentConfig, rate *rateController) {
+	d := c.Delay
+	if rate != nil {
+		d = rate.next()
+	} else if c.ThinkTime != nil {
+		d += r.thinkTime(*c.ThinkTime)
+	}
+	if d <= 0 {
+		return
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(d):
+	}
+}
+
+// jitter waits a random time below max, drawn from the seeded random
+// source, before a request of a virtual user, so users that fell into
+// lockstep do not send their requests in waves. It returns early when ctx is
+// cancelled.
+func (r *Runner) jitter(ctx context.Context, max time.Duration) {
+	if max <= 0 {
+		return
+	}
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Duration(r.random.Float64() * float64(max))):
+	}
+}
+
+// rateWindow is the interval over which a rateController measures the
+// throughput before adjusting the pause.
+const rateWindow = 500 * time.Millisecond
+
+// rateGain is the proportional gain of a rateController: the fraction of the
+// relative throughput error corrected at every adjustment.
+const rateGain = 0.5
+
+// rateController adjusts the pause of the users of an endpoint to hold a
+// target throughput as the latency of the server varies. Every rateWindow, a
+// proportional controller lengthens the pause when the observed throughput
+// is above the target and shortens it when below, down to no pause when the
+// server is too slow to reach the target.
+type rateController struct {
+	mu     sync.Mutex
+	target float64
+	// cycle is the time between two requests of a user at the target rate
+	cycle       time.Duration
+	delay       time.Duration
+	windowStart time.Time
+	requests    int
+}
+
+// newRateController creates a controller for users users holding target
+// requests per second, starting with the given pause, or the cycle at the
+// target rate when zero.
+func newRateController(target float64, users int, initial time.Duration) *rateController {
+	cycle := time.Duration(float64(users) / target * float64(time.Second))
+	if initial == 0 {
+		initial = cycle
+	}
+	return &rateController{target: target, cycle: cycle, delay: initial, windowStart: time.Now()}
+}
+
+// next records a completed request and returns the pause before the next one.
+func (c *rateController) next() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.requests++
+	if elapsed := time.Since(c.windowStart); elapsed >= rateWindow {
+		observed := float64(c.requests) / elapsed.Seconds()
+		c.delay += time.Duration(rateGain * (observed - c.target) / c.target * float64(c.cycle))
+		if c.delay < 0 {
+			c.delay = 0
+		}
+		c.windowStart = time.Now()
+		c.requests = 0
+	}
+	return c.delay
+}
@@ -0,0 +1,61 @@
+package runner
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTargetRPSHoldsThroughputAsLatencyChanges(t *testing.T) {
+	// 5ms responses for the first 1.5s, then 50ms
+	var once sync.Once
+	var start time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		once.Do(func() { start = time.Now() })
+		if time.Since(start) < 1500*time.Millisecond {
+			time.Sleep(5 * time.Millisecond)
+		} else {
+			time.Sleep(50 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+
+	report, err := runConfig(t, `
+endpoints:
+  - name: items
+    url: `+server.URL+`/items
+    method: GET
+    concurrent:
+      targetRps: 40
+      loadProfile:
+        - users: 4
+          duration: 4s
+`, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	details := endpointResult(t, report, "items").RequestDetails
+	first := details[0].Timestamp
+	for _, detail := range details {
+		if detail.Timestamp.Before(first) {
+			first = detail.Timestamp
+		}
+	}
+	// the last second, after the controller had time to adapt to the slower
+	// responses
+	var late int
+	for _, detail := range details {
+		if offset := detail.Timestamp.Sub(first); offset >= 3*time.Second && offset < 4*time.Second {
+			late++
+		}
+	}
+	if late < 34 || late > 46 {
+		t.Errorf("%d requests in the last second, want about 40", late)
+	}
+	if rps := float64(len(details)) / 4; rps < 34 || rps > 46 {
+		t.Errorf("%.1f requests per second overall, want about 40", rps)
+	}
+}
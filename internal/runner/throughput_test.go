@@ -0,0 +1,50 @@
+package runner
+
+import (
+	"bytes"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestThroughputInBytesPerSecond(t *testing.T) {
+	body := bytes.Repeat([]byte("x"), 10000)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	report, err := runConfig(t, `
+endpoints:
+  - name: download
+    url: `+server.URL+`/file
+    method: GET
+    concurrent:
+      users: 1
+      total: 5
+`, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := endpointResult(t, report, "download")
+	if result.BytesTransferred != 50000 {
+		t.Fatalf("bytes = %d, want 50000", result.BytesTransferred)
+	}
+	duration := result.EndTime.Sub(result.StartTime)
+	if want := 50000 / duration.Seconds(); math.Abs(result.BytesPerSecond-want) > 1e-6 {
+		t.Errorf("throughput = %.2f B/s, want %.2f over %s", result.BytesPerSecond, want, duration)
+	}
+	// five sequential requests of at least 100ms move at most 100000 bytes per second
+	if result.BytesPerSecond > 100000 || result.BytesPerSecond < 60000 {
+		t.Errorf("throughput = %.2f B/s, want about 100000", result.BytesPerSecond)
+	}
+
+	elapsed := report.EndTime.Sub(report.StartTime)
+	if want := 50000 / elapsed.Seconds(); math.Abs(report.GlobalStats.BytesPerSecond-want) > 1e-6 {
+		t.Errorf("global throughput = %.2f B/s, want %.2f over %s", report.GlobalStats.BytesPerSecond, want, elapsed)
+	}
+}
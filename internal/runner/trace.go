@@ -0,0 +1,47 @@
+package runner
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/JakubPluta/tmago/internal/reporter"
+)
+
+// tlsTiming measures the TLS handshake of a request. The trace callbacks may
+// run on transport goroutines, so the timing is guarded by a mutex.
+type tlsTiming struct {
+	mu       sync.Mutex
+	start    time.Time
+	duration time.Duration
+	resumed  bool
+}
+
+// trace returns ctx with a client trace recording the TLS handshake.
+func (t *tlsTiming) trace(ctx context.Context) context.Context {
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		TLSHandshakeStart: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.start = time.Now()
+		},
+		TLSHandshakeDone: func(state tls.ConnectionState, err error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if err == nil && !t.start.IsZero() {
+				t.duration = time.Since(t.start)
+				t.resumed = state.DidResume
+			}
+		},
+	})
+}
+
+// record adds the handshake, if the request made one, to detail.
+func (t *tlsTiming) record(detail *reporter.RequestDetail) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	detail.TLSHandshake = t.duration
+	detail.TLSResumed = t.resumed
+}
@@ -0,0 +1,67 @@
+package runner
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDisableResumptionHandshakesEveryRequest(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	cfg := loadConfig(t, `
+endpoints:
+  - name: full
+    url: `+server.URL+`/full
+    method: GET
+    tls:
+      disableResumption: true
+    concurrent:
+      users: 1
+      total: 4
+  - name: reused
+    url: `+server.URL+`/reused
+    method: GET
+    dialTimeout: 5s
+    concurrent:
+      users: 1
+      total: 4
+`)
+	r, err := NewRunner(cfg, Options{NoFileLog: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// trust the test server in the transports built for the endpoints
+	roots := server.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+	for _, endpoint := range cfg.Endpoints {
+		transport := r.clientFor(endpoint).Transport.(*http.Transport)
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = server.Client().Transport.(*http.Transport).TLSClientConfig.Clone()
+		}
+		transport.TLSClientConfig.RootCAs = roots
+	}
+	if err := r.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	report := r.reporter.Report()
+
+	full := endpointResult(t, report, "full")
+	if full.SuccessCount != 4 || full.TLSHandshakes != 4 {
+		t.Errorf("full: %d successes, %d handshakes, want 4 and 4", full.SuccessCount, full.TLSHandshakes)
+	}
+	for _, detail := range full.RequestDetails {
+		if detail.TLSResumed {
+			t.Errorf("full: request %d resumed a session", detail.ID)
+		}
+	}
+	if full.AvgTLSHandshake <= 0 || full.MaxTLSHandshake < full.AvgTLSHandshake {
+		t.Errorf("full: avg %s, max %s", full.AvgTLSHandshake, full.MaxTLSHandshake)
+	}
+
+	reused := endpointResult(t, report, "reused")
+	if reused.SuccessCount != 4 || reused.TLSHandshakes != 1 {
+		t.Errorf("reused: %d successes, %d handshakes, want 4 and 1", reused.SuccessCount, reused.TLSHandshakes)
+	}
+}
@@ -0,0 +1,96 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/JakubPluta/tmago/internal/config"
+	"github.com/JakubPluta/tmago/internal/har"
+	"github.com/JakubPluta/tmago/internal/reporter"
+)
+
+// runTraffic replays recorded requests: every request is sent at its offset
+// from the start of the run, divided by Options.Speed, without waiting for
+// the earlier ones to complete, so the recorded mix and pace of the traffic
+// are reproduced. The requests are sent in the order of their offsets, and
+// those of each endpoint name are aggregated in a result, like in a scenario.
+func (r *Runner) runTraffic(ctx context.Context, traffic []har.Request) {
+	speed := r.opts.Speed
+	if speed <= 0 {
+		speed = 1
+	}
+
+	index := make(map[string]int)
+	var endpoints []config.Endpoint
+	var results []reporter.TestResult
+	var requestChans []chan reporter.RequestDetail
+	var errChans []chan error
+	for _, req := range traffic {
+		if _, ok := index[req.Endpoint.Name]; ok {
+			continue
+		}
+		index[req.Endpoint.Name] = len(results)
+		endpoints = append(endpoints, req.Endpoint)
+		results = append(results, newResult(req.Endpoint))
+		requestChans = append(requestChans, make(chan reporter.RequestDetail, 1))
+		errChans = append(errChans, make(chan error, 1))
+		r.logger.TestStarted(req.Endpoint.Name, req.Endpoint.Method, req.Endpoint.URL)
+	}
+	last := traffic[len(traffic)-1].Offset
+	r.logger.Info(fmt.Sprintf("Replaying %d requests to %d endpoints over %s (recorded over %s)",
+		len(traffic), len(results), time.Duration(float64(last)/speed).Round(time.Millisecond), last))
+
+	// one collector per endpoint
+	var collectors sync.WaitGroup
+	collectErrs := make([]error, len(results))
+	for i := range endpoints {
+		collectors.Add(1)
+		go func(i int) {
+			defer collectors.Done()
+			collectErrs[i] = r.collectResults(endpoints[i], &results[i], requestChans[i], errChans[i])
+		}(i)
+	}
+
+	var senders sync.WaitGroup
+	start := time.Now()
+	for n, req := range traffic {
+		i := index[req.Endpoint.Name]
+		if wait := time.Until(start.Add(time.Duration(float64(req.Offset) / speed))); wait > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(wait):
+			}
+		}
+		if ctx.Err() != nil {
+			errChans[i] <- ctx.Err()
+			break
+		}
+
+		senders.Add(1)
+		go func(i, id int, req har.Request) {
+			defer senders.Done()
+			detail, err := r.executeRequest(ctx, req.Endpoint, id)
+			requestChans[i] <- detail
+			if err != nil {
+				errChans[i] <- err
+			}
+		}(i, n+1, req)
+	}
+
+	senders.Wait()
+	for i := range results {
+		close(requestChans[i])
+		close(errChans[i])
+	}
+	collectors.Wait()
+
+	for i := range results {
+		if collectErrs[i] != nil {
+			r.logger.RequestFailed(-1, endpoints[i].Name, collectErrs[i])
+			results[i].Errors = append(results[i].Errors, collectErrs[i].Error())
+		}
+		r.finishResult(&results[i], 0)
+	}
+}
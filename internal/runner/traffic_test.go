@@ -0,0 +1,89 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/JakubPluta/tmago/internal/config"
+	"github.com/JakubPluta/tmago/internal/har"
+)
+
+func TestRunTrafficReplaysInRecordedOrder(t *testing.T) {
+	var mu sync.Mutex
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		received = append(received, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer server.Close()
+
+	// entries are listed out of order, 40ms apart
+	entry := func(offset int, method, path string, status int) string {
+		started := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC).Add(time.Duration(offset) * 40 * time.Millisecond)
+		return fmt.Sprintf(`{"startedDateTime": %q, "request": {"method": %q, "url": "%s%s", "headers": []}, "response": {"status": %d}}`,
+			started.Format(time.RFC3339Nano), method, server.URL, path, status)
+	}
+	archive := `{"log": {"entries": [` +
+		entry(2, "GET", "/items/1", 200) + `,` +
+		entry(0, "GET", "/items", 200) + `,` +
+		entry(3, "GET", "/items", 200) + `,` +
+		entry(1, "POST", "/items", 201) + `,` +
+		`{"startedDateTime": "2024-01-01T12:00:00Z", "request": {"method": "GET", "url": "data:text/plain,hi"}, "response": {"status": 200}}` +
+		`]}}`
+	traffic, skipped, err := har.Parse([]byte(archive))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if skipped != 1 {
+		t.Errorf("skipped %d entries, want the data: URL", skipped)
+	}
+
+	cfg := &config.Config{}
+	seen := make(map[string]bool)
+	for _, req := range traffic {
+		if !seen[req.Endpoint.Name] {
+			seen[req.Endpoint.Name] = true
+			cfg.Endpoints = append(cfg.Endpoints, req.Endpoint)
+		}
+	}
+	workDir(t)
+	r, err := NewRunner(cfg, Options{NoFileLog: true, Traffic: traffic, Speed: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	if err := r.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// the last request is recorded 120ms after the first, replayed at 2x
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Errorf("replayed in %s, faster than the recorded pace", elapsed)
+	}
+
+	want := []string{"GET /items", "POST /items", "GET /items/1", "GET /items"}
+	mu.Lock()
+	defer mu.Unlock()
+	if !reflect.DeepEqual(received, want) {
+		t.Errorf("received %v, want %v", received, want)
+	}
+
+	report := r.reporter.Report()
+	if len(report.TestResults) != 3 {
+		t.Fatalf("got %d results, want one per method and path", len(report.TestResults))
+	}
+	for _, result := range report.TestResults {
+		if result.FailureCount != 0 {
+			t.Errorf("%s failed: %v", result.EndpointName, result.Errors)
+		}
+	}
+}
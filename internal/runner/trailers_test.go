@@ -0,0 +1,65 @@
+package runner
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTrailerChecks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Trailer", "Grpc-Status")
+		w.Write([]byte("streamed"))
+		if r.URL.Path == "/ok" {
+			w.Header().Set("Grpc-Status", "0")
+		} else {
+			w.Header().Set("Grpc-Status", "13")
+		}
+	}))
+	defer server.Close()
+
+	report, err := runConfig(t, `
+endpoints:
+  - name: ok
+    url: `+server.URL+`/ok
+    method: GET
+    expect:
+      trailers:
+        - name: Grpc-Status
+          value: "0"
+  - name: failed
+    url: `+server.URL+`/failed
+    method: GET
+    expect:
+      trailers:
+        - name: Grpc-Status
+          value: "0"
+  - name: missing
+    url: `+server.URL+`/ok
+    method: GET
+    expect:
+      trailers:
+        - name: Grpc-Message
+`, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ok := endpointResult(t, report, "ok")
+	if ok.SuccessCount != 1 {
+		t.Errorf("ok failed: %v", ok.RequestDetails[0].ValidationErrors)
+	}
+	if got := ok.RequestDetails[0].Trailers["Grpc-Status"]; got != "0" {
+		t.Errorf("recorded trailer = %q, want 0", got)
+	}
+
+	for name, want := range map[string]string{
+		"failed":  "trailer Grpc-Status expected value 0, got 13",
+		"missing": "trailer Grpc-Message not sent in response",
+	} {
+		detail := endpointResult(t, report, name).RequestDetails[0]
+		if len(detail.ValidationErrors) != 1 || detail.ValidationErrors[0] != want {
+			t.Errorf("%s errors = %q, want %q", name, detail.ValidationErrors, want)
+		}
+	}
+}
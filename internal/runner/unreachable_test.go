@@ -0,0 +1,50 @@
+package runner
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUnreachableInvertsTheVerdict(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	// a port nothing listens on anymore, picked after the server started so
+	// that the server cannot reuse it
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed := listener.Addr().String()
+	listener.Close()
+
+	report, err := runConfig(t, `
+endpoints:
+  - name: closed
+    url: http://`+closed+`/admin
+    method: GET
+    expect:
+      unreachable: true
+  - name: open
+    url: `+server.URL+`/admin
+    method: GET
+    expect:
+      unreachable: true
+`, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	closedResult := endpointResult(t, report, "closed")
+	if closedResult.SuccessCount != 1 {
+		t.Errorf("closed port failed: %v", closedResult.RequestDetails[0].ValidationErrors)
+	}
+	if category := closedResult.RequestDetails[0].ErrorCategory; category != "connection refused" {
+		t.Errorf("category = %q, want connection refused", category)
+	}
+	if open := endpointResult(t, report, "open"); open.FailureCount != 1 {
+		t.Errorf("a response passed an unreachable expectation")
+	}
+}
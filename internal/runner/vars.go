@@ -0,0 +1,298 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/JakubPluta/tmago/internal/config"
+	"github.com/JakubPluta/tmago/internal/validator"
+)
+
+// capturedRef matches a reference to a captured variable, e.g. {{captured.userId}}.
+var capturedRef = regexp.MustCompile(`\{\{\s*captured\.([A-Za-z0-9_\-]+)\s*\}\}`)
+
+// Variables is a thread-safe store of values captured from responses.
+type Variables struct {
+	mu     sync.RWMutex
+	values map[string]interface{}
+}
+
+// NewVariables creates an empty variable store.
+func NewVariables() *Variables {
+	return &Variables{values: make(map[string]interface{})}
+}
+
+// Get returns the value stored under name.
+func (v *Variables) Get(name string) (interface{}, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	val, ok := v.values[name]
+	return val, ok
+}
+
+// Set stores value under name, replacing any previous value.
+func (v *Variables) Set(name string, value interface{}) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.values[name] = value
+}
+
+// Save writes all variables to path as a JSON object, so a later run can load
+// them with Load.
+func (v *Variables) Save(path string) error {
+	v.mu.RLock()
+	data, err := json.MarshalIndent(v.values, "", "  ")
+	v.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode variables: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write variables file: %w", err)
+	}
+	return nil
+}
+
+// Load reads variables saved with Save from path, replacing variables with the
+// same name.
+func (v *Variables) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read variables file: %w", err)
+	}
+
+	var values map[string]interface{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("failed to decode variables file %s: %w", path, err)
+	}
+	for name, value := range values {
+		v.Set(name, value)
+	}
+	return nil
+}
+
+// unsafeEnvChars matches characters not allowed in shell variable names.
+var unsafeEnvChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// plainEnvValue matches values that need no quoting in a dotenv file.
+var plainEnvValue = regexp.MustCompile(`^[A-Za-z0-9_./:@+,=-]*$`)
+
+// envName converts a variable name into a shell variable name: characters
+// not allowed in names are replaced with underscores, and names that would
+// start with a digit, or are empty, are prefixed with one.
+func envName(name string) string {
+	env := unsafeEnvChars.ReplaceAllString(name, "_")
+	if env == "" || env[0] >= '0' && env[0] <= '9' {
+		env = "_" + env
+	}
+	return env
+}
+
+// SaveDotenv writes all variables to path as sorted NAME=VALUE lines that
+// can be sourced by a shell. Names are converted with envName, values are
+// single-quoted when needed, and objects and arrays are written as JSON. It
+// fails without writing the file when two variables convert to the same
+// name, e.g. user-id and user_id.
+func (v *Variables) SaveDotenv(path string) error {
+	v.mu.RLock()
+	names := make([]string, 0, len(v.values))
+	for name := range v.values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	exported := make(map[string]string, len(names))
+	var b strings.Builder
+	for _, name := range names {
+		env := envName(name)
+		if other, ok := exported[env]; ok {
+			v.mu.RUnlock()
+			return fmt.Errorf("variables %s and %s are both exported as %s", other, name, env)
+		}
+		exported[env] = name
+
+		value := fmt.Sprintf("%v", v.values[name])
+		switch v.values[name].(type) {
+		case map[string]interface{}, []interface{}:
+			data, err := json.Marshal(v.values[name])
+			if err != nil {
+				v.mu.RUnlock()
+				return fmt.Errorf("failed to encode variable %s: %w", name, err)
+			}
+			value = string(data)
+		}
+		if !plainEnvValue.MatchString(value) {
+			value = "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+		}
+		fmt.Fprintf(&b, "%s=%s\n", env, value)
+	}
+	v.mu.RUnlock()
+
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write captures file: %w", err)
+	}
+	return nil
+}
+
+// errMissingVariable is returned when a referenced variable has neither been
+// captured in this run nor loaded from a previous one.
+func errMissingVariable(name string) error {
+	return fmt.Errorf("captured variable %s not found (not captured yet in this run and not loaded with --vars-in)", name)
+}
+
+// capture extracts the configured captures from a response body and stores
+// them in the variable store. The body is parsed like for the JSON checks,
+// refusing bodies over maxSize bytes and stopping once ctx is done. It
+// returns an error for every capture whose path cannot be resolved.
+func (v *Variables) capture(ctx context.Context, captures []config.Capture, body []byte, maxSize int64) []error {
+	if len(captures) == 0 {
+		return nil
+	}
+
+	data, err := validator.DecodeJSON(ctx, body, maxSize)
+	if err != nil {
+		return []error{fmt.Errorf("capture: failed to unmarshal response body: %w", err)}
+	}
+
+	var errs []error
+	for _, c := range captures {
+		val, ok := validator.LookupPath(data, c.Path)
+		if !ok {
+			errs = append(errs, fmt.Errorf("capture %s: path %s not found in response", c.Name, c.Path))
+			continue
+		}
+		if c.Increasing {
+			if err := v.setIncreasing(c.Name, val); err != nil {
+				errs = append(errs, fmt.Errorf("capture %s: %w", c.Name, err))
+			}
+			continue
+		}
+		v.Set(c.Name, val)
+	}
+	return errs
+}
+
+// setIncreasing stores a numeric value, returning an error when it is not
+// greater than the value previously stored under name. The value is stored
+// either way, so every value is compared with the one before it.
+func (v *Variables) setIncreasing(name string, value interface{}) error {
+	n, ok := toNumber(value)
+	if !ok {
+		return fmt.Errorf("value %v is not a number", value)
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	prev, exists := v.values[name]
+	v.values[name] = value
+	if !exists {
+		return nil
+	}
+	if p, ok := toNumber(prev); ok && n <= p {
+		return fmt.Errorf("value %v is not greater than the previous value %v", value, prev)
+	}
+	return nil
+}
+
+// toNumber converts a JSON number, or a string containing one, to a float64.
+func toNumber(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// resolveValue replaces references to captured variables in an expected value.
+//
+// A value consisting of a single reference is replaced by the captured value
+// itself so its type is preserved. References embedded in a longer string are
+// substituted with their string form. An error is returned when a referenced
+// variable has not been captured.
+func (v *Variables) resolveValue(value interface{}) (interface{}, error) {
+	s, ok := value.(string)
+	if !ok {
+		return value, nil
+	}
+
+	if m := capturedRef.FindStringSubmatch(s); m != nil && m[0] == s {
+		val, ok := v.Get(m[1])
+		if !ok {
+			return nil, errMissingVariable(m[1])
+		}
+		return val, nil
+	}
+
+	var missing error
+	resolved := capturedRef.ReplaceAllStringFunc(s, func(ref string) string {
+		name := capturedRef.FindStringSubmatch(ref)[1]
+		val, ok := v.Get(name)
+		if !ok {
+			missing = errMissingVariable(name)
+			return ref
+		}
+		return fmt.Sprintf("%v", val)
+	})
+	if missing != nil {
+		return nil, missing
+	}
+	return resolved, nil
+}
+
+// resolveExpectation returns a copy of expect with captured variable
+// references in its value checks resolved.
+func (v *Variables) resolveExpectation(expect config.Expectation) (config.Expectation, []error) {
+	var errs []error
+	expect.Values, errs = v.resolveChecks(expect.Values, errs)
+
+	if len(expect.AnyOf) > 0 {
+		variants := make([]config.Variant, len(expect.AnyOf))
+		for i, variant := range expect.AnyOf {
+			variant.Values, errs = v.resolveChecks(variant.Values, errs)
+			variants[i] = variant
+		}
+		expect.AnyOf = variants
+	}
+
+	if len(expect.ByStatus) > 0 {
+		byStatus := make(map[int]config.Expectation, len(expect.ByStatus))
+		for status, block := range expect.ByStatus {
+			var blockErrs []error
+			byStatus[status], blockErrs = v.resolveExpectation(block)
+			errs = append(errs, blockErrs...)
+		}
+		expect.ByStatus = byStatus
+	}
+	return expect, errs
+}
+
+// resolveChecks returns a copy of checks with captured variable references
+// resolved, appending resolution errors to errs.
+func (v *Variables) resolveChecks(checks []config.ValueCheck, errs []error) ([]config.ValueCheck, []error) {
+	if len(checks) == 0 {
+		return checks, errs
+	}
+
+	resolved := make([]config.ValueCheck, len(checks))
+	for i, check := range checks {
+		value, err := v.resolveValue(check.Value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("path %s: %w", check.Path, err))
+			value = check.Value
+		}
+		check.Value = value
+		resolved[i] = check
+	}
+	return resolved, errs
+}
@@ -0,0 +1,70 @@
+package runner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/JakubPluta/tmago/internal/config"
+)
+
+func TestCaptureUsesTheJSONSizeLimit(t *testing.T) {
+	captures := []config.Capture{{Name: "id", Path: "id"}}
+	body := []byte(`{"id": 42}`)
+
+	vars := NewVariables()
+	if errs := vars.capture(context.Background(), captures, body, 0); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	if value, ok := vars.Get("id"); !ok || value != 42.0 {
+		t.Errorf("captured %v, want 42", value)
+	}
+
+	errs := NewVariables().capture(context.Background(), captures, body, 5)
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "exceeds the JSON size limit") {
+		t.Errorf("errors = %v, want the size limit", errs)
+	}
+}
+
+func TestSaveDotenv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "captures.env")
+	vars := NewVariables()
+	vars.Set("token", "abc.def")
+	vars.Set("user-id", 42.0)
+	vars.Set("2fa", true)
+	vars.Set("message", "it's done")
+	vars.Set("user", map[string]interface{}{"name": "Ann"})
+	if err := vars.SaveDotenv(path); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `_2fa=true
+message='it'\''s done'
+token=abc.def
+user='{"name":"Ann"}'
+user_id=42
+`
+	if string(data) != want {
+		t.Errorf("got\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestSaveDotenvRejectsCollidingNames(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "captures.env")
+	vars := NewVariables()
+	vars.Set("user-id", 1.0)
+	vars.Set("user_id", 2.0)
+	err := vars.SaveDotenv(path)
+	if err == nil || !strings.Contains(err.Error(), "variables user-id and user_id are both exported as user_id") {
+		t.Fatalf("err = %v, want the collision", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("file written despite the collision: %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/JakubPluta/tmago/internal/reporter"
+)
+
+// Webhook notification policies.
+const (
+	WebhookOnFailure = "failure"
+	WebhookOnAlways  = "always"
+)
+
+// webhookTimeout bounds the webhook call so it cannot hold up the run.
+const webhookTimeout = 10 * time.Second
+
+// webhookPayload is the JSON summary posted to the webhook. The text field is
+// understood by Slack and Teams incoming webhooks.
+type webhookPayload struct {
+	Text            string            `json:"text"`
+	Status          string            `json:"status"`
+	StartTime       time.Time         `json:"startTime"`
+	EndTime         time.Time         `json:"endTime"`
+	TotalEndpoints  int               `json:"totalEndpoints"`
+	FailedEndpoints []string          `json:"failedEndpoints"`
+	TotalRequests   int               `json:"totalRequests"`
+	TotalErrors     int               `json:"totalErrors"`
+	SuccessRate     float64           `json:"successRate"`
+	AverageLatency  string            `json:"averageLatency"`
+	RequestsPerSec  float64           `json:"requestsPerSecond"`
+	Labels          map[string]string `json:"labels,omitempty"`
+}
+
+// failedEndpoints returns the names of the endpoints with failed requests or errors.
+func failedEndpoints(report reporter.Report) []string {
+	failed := make([]string, 0)
+	for _, result := range report.TestResults {
+		if result.FailureCount > 0 || len(result.Errors) > 0 {
+			failed = append(failed, result.EndpointName)
+		}
+	}
+	return failed
+}
+
+// notifyWebhook posts the run summary to the configured webhook, depending on
+// the notification policy. The call is best-effort: failures are logged and
+// do not affect the outcome of the run.
+func (r *Runner) notifyWebhook(ctx context.Context, report reporter.Report) {
+	if r.opts.WebhookURL == "" {
+		return
+	}
+
+	failed := failedEndpoints(report)
+	if r.opts.WebhookOn != WebhookOnAlways && len(failed) == 0 {
+		return
+	}
+
+	status := "passed"
+	if len(failed) > 0 {
+		status = "failed"
+	}
+	payload := webhookPayload{
+		Text: fmt.Sprintf("tmago run %s: %d/%d endpoints passed, %d requests, %.2f%% success, avg latency %s",
+			status, report.TotalEndpoints-len(failed), report.TotalEndpoints,
+			report.TotalRequests, report.SuccessRate, report.GlobalStats.AverageLatency),
+		Status:          status,
+		StartTime:       report.StartTime,
+		EndTime:         report.EndTime,
+		TotalEndpoints:  report.TotalEndpoints,
+		FailedEndpoints: failed,
+		TotalRequests:   report.TotalRequests,
+		TotalErrors:     report.GlobalStats.TotalErrors,
+		SuccessRate:     report.SuccessRate,
+		AverageLatency:  report.GlobalStats.AverageLatency.String(),
+		RequestsPerSec:  report.GlobalStats.RequestsPerSecond,
+		Labels:          report.Labels,
+	}
+
+	if err := postJSON(ctx, r.opts.WebhookURL, payload); err != nil {
+		r.logger.Warn(fmt.Sprintf("failed to notify webhook: %v", err))
+		return
+	}
+	r.logger.Info("Run summary sent to webhook")
+}
+
+// postJSON posts v as JSON to url and fails on a non-2xx response.
+func postJSON(ctx context.Context, url string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package validator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateByStatus(t *testing.T) {
+	const expect = `
+status: [200, 404]
+values:
+  - path: requestId
+    value: abc
+byStatus:
+  200:
+    values:
+      - path: data.id
+        value: 1
+  404:
+    values:
+      - path: error
+        value: not found
+`
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   []string
+	}{
+		{name: "200 with data", status: 200, body: `{"requestId": "abc", "data": {"id": 1}}`},
+		{name: "404 with error", status: 404, body: `{"requestId": "abc", "error": "not found"}`},
+		{name: "200 without data", status: 200, body: `{"requestId": "abc", "error": "not found"}`,
+			want: []string{"path data.id not found in response"}},
+		{name: "404 without error", status: 404, body: `{"requestId": "abc", "data": {"id": 1}}`,
+			want: []string{"path error not found in response"}},
+		{name: "common check", status: 404, body: `{"requestId": "xyz", "error": "not found"}`,
+			want: []string{`path requestId expected "abc", got "xyz"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := validate(t, expect, response(tt.status, nil), tt.body)
+			if len(tt.want) == 0 {
+				tt.want = []string{}
+			}
+			if !reflect.DeepEqual(result.Errors, tt.want) || result.IsValid != (len(tt.want) == 0) {
+				t.Errorf("valid %t with errors %q, want %q", result.IsValid, result.Errors, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package validator
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JakubPluta/tmago/internal/config"
+)
+
+// checkCertificate checks the leaf certificate of the connection state of a
+// response against the expected TLS certificate at the time now and returns
+// an error for every failed check. A response received without TLS fails.
+func checkCertificate(expect config.TLSExpectation, state *tls.ConnectionState, now time.Time) []ValidationError {
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return []ValidationError{{Kind: KindCertificate, Message: "expected a TLS connection with a server certificate"}}
+	}
+
+	var errs []ValidationError
+	fail := func(expected, actual, msg string) {
+		errs = append(errs, ValidationError{Kind: KindCertificate, Expected: expected, Actual: actual, Message: msg})
+	}
+	cert := state.PeerCertificates[0]
+	if expect.MinDaysToExpiry > 0 {
+		left := cert.NotAfter.Sub(now)
+		switch {
+		case left <= 0:
+			// reached when the certificate expires between the handshake and
+			// the check; an expired certificate otherwise fails the handshake,
+			// see CertificateExpired
+			fail("", cert.NotAfter.UTC().Format(time.RFC3339), expiredMessage(cert))
+		case left < time.Duration(expect.MinDaysToExpiry)*24*time.Hour:
+			fail(strconv.Itoa(expect.MinDaysToExpiry), cert.NotAfter.UTC().Format(time.RFC3339), fmt.Sprintf("certificate of %s expires on %s, in %.1f days, expected at least %d days",
+				cert.Subject.CommonName, cert.NotAfter.UTC().Format(time.RFC3339), left.Hours()/24, expect.MinDaysToExpiry))
+		}
+	}
+	if expect.Subject != "" && !nameMatches(cert.Subject, expect.Subject) {
+		fail(expect.Subject, cert.Subject.String(), fmt.Sprintf("expected certificate subject %s, got %s", expect.Subject, cert.Subject))
+	}
+	if expect.Issuer != "" && !nameMatches(cert.Issuer, expect.Issuer) {
+		fail(expect.Issuer, cert.Issuer.String(), fmt.Sprintf("expected certificate issuer %s, got %s", expect.Issuer, cert.Issuer))
+	}
+	return errs
+}
+
+// CertificateExpired reports whether err is a TLS handshake that failed
+// because the server certificate expired, and describes it like a failed
+// minDaysToExpiry check, e.g. "certificate of example.com expired on
+// 2024-01-01T00:00:00Z".
+func CertificateExpired(err error) (string, bool) {
+	var verifyErr *tls.CertificateVerificationError
+	var invalidErr x509.CertificateInvalidError
+	if !errors.As(err, &verifyErr) || !errors.As(err, &invalidErr) || invalidErr.Reason != x509.Expired {
+		return "", false
+	}
+	if len(verifyErr.UnverifiedCertificates) == 0 {
+		return "", false
+	}
+	return expiredMessage(verifyErr.UnverifiedCertificates[0]), true
+}
+
+// expiredMessage describes an expired certificate.
+func expiredMessage(cert *x509.Certificate) string {
+	return fmt.Sprintf("certificate of %s expired on %s", cert.Subject.CommonName, cert.NotAfter.UTC().Format(time.RFC3339))
+}
+
+// nameMatches reports whether the common name or one of the organizations of
+// a certificate name equals the expected one, ignoring case.
+func nameMatches(name pkix.Name, expected string) bool {
+	if strings.EqualFold(name.CommonName, expected) {
+		return true
+	}
+	for _, org := range name.Organization {
+		if strings.EqualFold(org, expected) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package validator
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JakubPluta/tmago/internal/config"
+)
+
+// certificate creates a self-signed certificate for example.com valid
+// between notBefore and notAfter.
+func certificate(t *testing.T, notBefore, notAfter time.Time) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "example.com", Organization: []string{"Example"}},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestCheckCertificate(t *testing.T) {
+	now := time.Now()
+	// a short-lived certificate, like those rotated daily
+	cert := certificate(t, now.Add(-time.Hour), now.Add(12*time.Hour))
+	state := &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+
+	tests := []struct {
+		name   string
+		expect config.TLSExpectation
+		now    time.Time
+		want   string
+	}{
+		{name: "valid", expect: config.TLSExpectation{Subject: "example.com", Issuer: "example"}, now: now},
+		{name: "expires too soon", expect: config.TLSExpectation{MinDaysToExpiry: 1}, now: now, want: "expected at least 1 days"},
+		{name: "expired", expect: config.TLSExpectation{MinDaysToExpiry: 1}, now: now.Add(13 * time.Hour), want: "certificate of example.com expired on"},
+		{name: "wrong issuer", expect: config.TLSExpectation{Issuer: "Let's Encrypt"}, now: now, want: "expected certificate issuer Let's Encrypt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := checkCertificate(tt.expect, state, tt.now)
+			if tt.want == "" {
+				if len(errs) > 0 {
+					t.Fatalf("unexpected errors %v", errs)
+				}
+				return
+			}
+			if len(errs) != 1 || !strings.Contains(errs[0].Message, tt.want) {
+				t.Fatalf("errors = %v, want %q", errs, tt.want)
+			}
+		})
+	}
+}
+
+func TestCertificateExpired(t *testing.T) {
+	now := time.Now()
+	expired := certificate(t, now.Add(-2*time.Hour), now.Add(-time.Hour))
+	_, verifyErr := expired.Verify(x509.VerifyOptions{})
+	err := &tls.CertificateVerificationError{UnverifiedCertificates: []*x509.Certificate{expired}, Err: verifyErr}
+
+	msg, ok := CertificateExpired(err)
+	if !ok || !strings.HasPrefix(msg, "certificate of example.com expired on ") {
+		t.Errorf("CertificateExpired = %q, %t, want the expiry", msg, ok)
+	}
+
+	valid := certificate(t, now.Add(-time.Hour), now.Add(time.Hour))
+	_, verifyErr = valid.Verify(x509.VerifyOptions{})
+	err = &tls.CertificateVerificationError{UnverifiedCertificates: []*x509.Certificate{valid}, Err: verifyErr}
+	if _, ok := CertificateExpired(err); ok {
+		t.Errorf("an untrusted certificate was reported as expired: %v", verifyErr)
+	}
+}
@@ -0,0 +1,19 @@
+package validator
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestContentTypeCheck(t *testing.T) {
+	json := http.Header{"Content-Type": {"application/json; charset=utf-8"}}
+	html := http.Header{"Content-Type": {"text/html"}}
+
+	if result := validate(t, "status: 200\ncontentType: json", response(200, json), `{}`); !result.IsValid {
+		t.Errorf("json rejected: %v", result.Errors)
+	}
+	result := validate(t, "status: 200\ncontentType: json", response(200, html), `<html>`)
+	if want := `expected content type json, got "text/html"`; len(result.Errors) != 1 || result.Errors[0] != want {
+		t.Errorf("errors = %q, want %q", result.Errors, want)
+	}
+}
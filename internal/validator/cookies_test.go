@@ -0,0 +1,57 @@
+package validator
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestValidateCookies(t *testing.T) {
+	header := http.Header{}
+	header.Add("Set-Cookie", "session=abc; Path=/; HttpOnly; Secure; SameSite=Strict")
+	header.Add("Set-Cookie", "theme=dark")
+
+	tests := []struct {
+		name    string
+		cookies string
+		want    []string
+	}{
+		{
+			name: "matching flags",
+			cookies: `
+  - {name: session, value: abc, httpOnly: true, secure: true, sameSite: strict}
+  - {name: theme, httpOnly: false}`,
+		},
+		{
+			name: "wrong flags",
+			cookies: `
+  - {name: session, value: xyz, sameSite: lax}
+  - {name: theme, httpOnly: true, secure: true}`,
+			want: []string{
+				"cookie session expected value xyz, got abc",
+				"cookie session expected sameSite lax, got Strict",
+				"cookie theme expected httpOnly true, got false",
+				"cookie theme expected secure true, got false",
+			},
+		},
+		{
+			name:    "missing cookie",
+			cookies: "\n  - {name: csrf}",
+			want:    []string{"cookie csrf not set in response"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := validate(t, "status: 200\ncookies:"+tt.cookies, response(200, header), "")
+			if result.IsValid != (len(tt.want) == 0) {
+				t.Errorf("valid = %t, want %t", result.IsValid, len(tt.want) == 0)
+			}
+			if len(tt.want) == 0 {
+				tt.want = []string{}
+			}
+			if !reflect.DeepEqual(result.Errors, tt.want) {
+				t.Errorf("errors = %q, want %q", result.Errors, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package validator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// DefaultMaxJSONSize is the size of the largest response body parsed for the
+// JSON checks and captures unless another limit is set. Larger bodies fail
+// them without being parsed.
+const DefaultMaxJSONSize int64 = 64 << 20
+
+// errTrailingData is returned for a body with data after its JSON value.
+var errTrailingData = errors.New("body has data after its JSON value")
+
+// DecodeJSON parses a JSON body with a streaming decoder that stops at the
+// next read once ctx is done, so that parsing a pathological body does not
+// delay the shutdown of a cancelled run. Bodies over maxSize bytes, or
+// DefaultMaxJSONSize when maxSize is zero, are refused.
+func DecodeJSON(ctx context.Context, body []byte, maxSize int64) (interface{}, error) {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxJSONSize
+	}
+	if int64(len(body)) > maxSize {
+		return nil, fmt.Errorf("body of %d bytes exceeds the JSON size limit of %d bytes", len(body), maxSize)
+	}
+
+	dec := json.NewDecoder(&contextReader{ctx: ctx, r: bytes.NewReader(body)})
+	var data interface{}
+	if err := dec.Decode(&data); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, fmt.Errorf("validation canceled: %w", ctxErr)
+		}
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("unexpected end of JSON input")
+		}
+		return nil, err
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, fmt.Errorf("validation canceled: %w", ctxErr)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return nil, errTrailingData
+	}
+	return data, nil
+}
+
+// contextReader reads from r until ctx is done.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c *contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
@@ -0,0 +1,51 @@
+package validator
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		maxSize int64
+		want    interface{}
+		wantErr string
+	}{
+		{name: "object", body: `{"id": 1, "tags": ["a"]}`, want: map[string]interface{}{"id": 1.0, "tags": []interface{}{"a"}}},
+		{name: "trailing whitespace", body: "[1]\n", want: []interface{}{1.0}},
+		{name: "trailing data", body: `{"id": 1} {"id": 2}`, wantErr: "body has data after its JSON value"},
+		{name: "empty", body: "", wantErr: "unexpected end of JSON input"},
+		{name: "within the limit", body: `"abc"`, maxSize: 5, want: "abc"},
+		{name: "over the limit", body: `"abcd"`, maxSize: 5, wantErr: "exceeds the JSON size limit of 5 bytes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeJSON(context.Background(), []byte(tt.body), tt.maxSize)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeJSONCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := DecodeJSON(ctx, []byte(`{"id": 1}`), 0)
+	if err == nil || !strings.Contains(err.Error(), "validation canceled") {
+		t.Fatalf("err = %v, want the cancellation", err)
+	}
+}
@@ -0,0 +1,68 @@
+package validator
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JakubPluta/tmago/internal/config"
+	"github.com/JakubPluta/tmago/internal/logger"
+	"gopkg.in/yaml.v2"
+)
+
+func TestMatchDiffListsTheChangedPaths(t *testing.T) {
+	var expectation config.Expectation
+	if err := yaml.UnmarshalStrict([]byte(`
+status: 200
+match: {"id": "<any>", "name": "Widget", "tags": ["a", "b"], "price": 10, "secret": "s1"}
+`), &expectation); err != nil {
+		t.Fatal(err)
+	}
+	var console bytes.Buffer
+	log, err := logger.NewLoggerWithOptions(logger.Options{NoFile: true, Console: &console})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := NewValidator(expectation, &config.Redact{Paths: []string{"secret"}}, log)
+
+	body := `{"id": 7, "name": "Gadget", "tags": ["a"], "price": 10, "secret": "s2", "extra": true}`
+	result := v.Validate(context.Background(), response(200, http.Header{}), []byte(body), time.Millisecond)
+
+	var got []string
+	for _, d := range result.Diff {
+		got = append(got, d.Line())
+	}
+	want := []string{
+		`~ $.name: "Widget" -> "Gadget"`,
+		`~ $.secret: *** -> ***`,
+		`- $.tags.1: "b"`,
+		`+ $.extra: true`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diff = %q, want %q", got, want)
+	}
+	if len(result.Errors) != len(want) {
+		t.Errorf("errors = %q, want one per difference", result.Errors)
+	}
+
+	out := console.String()
+	for _, colored := range []string{colorYellow + want[0], colorRed + want[2], colorGreen + want[3]} {
+		if !strings.Contains(out, colored) {
+			t.Errorf("console output lacks %q:\n%s", colored, out)
+		}
+	}
+	if strings.Contains(out, "s1") || strings.Contains(out, "s2") {
+		t.Errorf("console output shows a redacted value:\n%s", out)
+	}
+}
+
+// ANSI colors of the diff lines written by the logger
+const (
+	colorRed    = "\x1b[31m"
+	colorGreen  = "\x1b[32m"
+	colorYellow = "\x1b[33m"
+)
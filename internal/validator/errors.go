@@ -0,0 +1,52 @@
+package validator
+
+// Kinds of validation errors, see ValidationError.
+const (
+	KindStatus          = "status"
+	KindResponseTime    = "responseTime"
+	KindBody            = "body" // the body is not valid JSON or cannot be decoded
+	KindValue           = "value"
+	KindMatch           = "match"
+	KindVariant         = "variant"
+	KindBodyOneOf       = "bodyOneOf"
+	KindCookie          = "cookie"
+	KindHeader          = "header"
+	KindTrailer         = "trailer"
+	KindContentEncoding = "contentEncoding"
+	KindContentType     = "contentType"
+	KindContentLength   = "contentLength"
+	KindCompression     = "compression"
+	KindRedirect        = "redirect"
+	KindCertificate     = "certificate"
+	KindUnreachable     = "unreachable"
+	KindTransport       = "transport"
+	KindCapture         = "capture"
+	KindEvents          = "events"
+)
+
+// ValidationError is a failed check of a response, for programs handling
+// failures by kind. Message is its string form, as found in
+// ValidationResult.Errors. Path is what the check applies to: the JSON path
+// of value and match checks, or the name of a cookie, header or trailer.
+// Expected and Actual are the compared values when the check has them, as
+// shown in the message, with redacted values hidden likewise.
+type ValidationError struct {
+	Kind     string
+	Path     string `json:",omitempty"`
+	Expected string `json:",omitempty"`
+	Actual   string `json:",omitempty"`
+	Message  string
+}
+
+// Error returns the message of the validation error.
+func (e ValidationError) Error() string {
+	return e.Message
+}
+
+// AddError records a failed check in both the typed and the string form of
+// the result, which is then invalid.
+func (r *ValidationResult) AddError(e ValidationError) {
+	r.Failures = append(r.Failures, e)
+	r.Errors = append(r.Errors, e.Message)
+	r.IsValid = false
+}
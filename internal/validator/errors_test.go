@@ -0,0 +1,47 @@
+package validator
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestValidationErrorKinds(t *testing.T) {
+	result := validate(t, `
+status: 201
+contentType: json
+headers:
+  - name: X-Request-Id
+values:
+  - path: user.id
+    value: 7
+  - path: user.name
+    value: ann
+cookies:
+  - name: session
+`, response(500, http.Header{"Content-Type": {"text/plain"}}), `{"user": {"id": 8}}`)
+
+	want := []ValidationError{
+		{Kind: KindStatus, Expected: "201", Actual: "500", Message: "expected status code 201, got 500"},
+		{Kind: KindValue, Path: "user.id", Expected: "7", Actual: "8", Message: "path user.id expected 7, got 8"},
+		{Kind: KindValue, Path: "user.name", Message: "path user.name not found in response"},
+	}
+	if !reflect.DeepEqual(result.Failures[:len(want)], want) {
+		t.Errorf("failures = %+v", result.Failures)
+	}
+
+	var kinds []string
+	for i, failure := range result.Failures {
+		kinds = append(kinds, failure.Kind)
+		if failure.Message != result.Errors[i] {
+			t.Errorf("failure %d message %q differs from error %q", i, failure.Message, result.Errors[i])
+		}
+	}
+	wantKinds := []string{KindStatus, KindValue, KindValue, KindCookie, KindContentType, KindHeader}
+	if !reflect.DeepEqual(kinds, wantKinds) {
+		t.Fatalf("kinds = %q, want %q", kinds, wantKinds)
+	}
+	if cookie, header := result.Failures[3], result.Failures[5]; cookie.Path != "session" || header.Path != "X-Request-Id" {
+		t.Errorf("cookie path %q, header path %q, want their names", cookie.Path, header.Path)
+	}
+}
@@ -0,0 +1,44 @@
+package validator
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/JakubPluta/tmago/internal/config"
+	"github.com/JakubPluta/tmago/internal/logger"
+	"gopkg.in/yaml.v2"
+)
+
+// newValidator creates a validator for the expectation given as YAML,
+// logging nowhere.
+func newValidator(t *testing.T, expect string) *Validator {
+	t.Helper()
+	var expectation config.Expectation
+	if err := yaml.UnmarshalStrict([]byte(expect), &expectation); err != nil {
+		t.Fatal(err)
+	}
+	log, err := logger.NewLoggerWithOptions(logger.Options{NoFile: true, Console: io.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewValidator(expectation, &config.Redact{}, log)
+}
+
+// response creates a response with the status and headers, e.g. built with
+// header, to validate with body.
+func response(status int, header http.Header) *http.Response {
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{StatusCode: status, Header: header}
+}
+
+// validate validates a response with the status, headers and body against
+// the expectation given as YAML.
+func validate(t *testing.T, expect string, resp *http.Response, body string) ValidationResult {
+	t.Helper()
+	return newValidator(t, expect).Validate(context.Background(), resp, []byte(body), time.Millisecond)
+}
@@ -0,0 +1,241 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/JakubPluta/tmago/internal/config"
+)
+
+// normalizeYAML converts a value decoded by yaml.v2 to the types produced by
+// encoding/json, so it can be compared with a decoded response body: maps get
+// string keys and numbers become float64.
+func normalizeYAML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			m[fmt.Sprintf("%v", key)] = normalizeYAML(val)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			m[key] = normalizeYAML(val)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, val := range v {
+			s[i] = normalizeYAML(val)
+		}
+		return s
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	default:
+		return v
+	}
+}
+
+// Kinds of differences between an expected and an actual JSON value
+const (
+	// DiffAdded is a key or element of the actual value that is not expected
+	DiffAdded = "added"
+	// DiffRemoved is an expected key or element missing from the actual value
+	DiffRemoved = "removed"
+	// DiffChanged is a value that differs, including in type
+	DiffChanged = "changed"
+)
+
+// Difference is a difference between an expected and an actual JSON value at
+// a path. Expected and Actual are JSON, empty for added and removed values
+// respectively.
+type Difference struct {
+	Path     string
+	Kind     string
+	Expected string
+	Actual   string
+}
+
+// String describes the difference in messages.
+func (d Difference) String() string {
+	switch d.Kind {
+	case DiffAdded:
+		return fmt.Sprintf("%s: unexpected", d.Path)
+	case DiffRemoved:
+		return fmt.Sprintf("%s: missing", d.Path)
+	default:
+		return fmt.Sprintf("%s: expected %s, got %s", d.Path, d.Expected, d.Actual)
+	}
+}
+
+// Line formats the difference as a line of a diff: "+" and the actual value
+// for an added value, "-" and the expected value for a removed one, and "~"
+// with both for a changed one.
+func (d Difference) Line() string {
+	switch d.Kind {
+	case DiffAdded:
+		return fmt.Sprintf("+ %s: %s", d.Path, d.Actual)
+	case DiffRemoved:
+		return fmt.Sprintf("- %s: %s", d.Path, d.Expected)
+	default:
+		return fmt.Sprintf("~ %s: %s -> %s", d.Path, d.Expected, d.Actual)
+	}
+}
+
+// diffJSON deeply compares an expected and an actual decoded JSON value and
+// returns every difference with its path. Object keys are compared regardless
+// of their order, array elements by position, extra or missing elements being
+// added or removed. With ignoreOrder, arrays are compared as multisets instead,
+// see diffUnordered. An expected config.MatchAny matches any value.
+func diffJSON(path string, expected, actual interface{}, ignoreOrder bool) []Difference {
+	if expected == config.MatchAny {
+		return nil
+	}
+	changed := []Difference{{Path: path, Kind: DiffChanged, Expected: jsonString(expected), Actual: jsonString(actual)}}
+	switch exp := expected.(type) {
+	case map[string]interface{}:
+		act, ok := actual.(map[string]interface{})
+		if !ok {
+			return changed
+		}
+		var diffs []Difference
+		for _, key := range sortedKeys(exp) {
+			val, ok := act[key]
+			if !ok {
+				diffs = append(diffs, Difference{Path: path + "." + key, Kind: DiffRemoved, Expected: jsonString(exp[key])})
+				continue
+			}
+			diffs = append(diffs, diffJSON(path+"."+key, exp[key], val, ignoreOrder)...)
+		}
+		for _, key := range sortedKeys(act) {
+			if _, ok := exp[key]; !ok {
+				diffs = append(diffs, Difference{Path: path + "." + key, Kind: DiffAdded, Actual: jsonString(act[key])})
+			}
+		}
+		return diffs
+	case []interface{}:
+		act, ok := actual.([]interface{})
+		if !ok {
+			return changed
+		}
+		if ignoreOrder {
+			return diffUnordered(path, exp, act)
+		}
+		var diffs []Difference
+		for i := 0; i < len(exp) || i < len(act); i++ {
+			elemPath := fmt.Sprintf("%s.%d", path, i)
+			switch {
+			case i >= len(act):
+				diffs = append(diffs, Difference{Path: elemPath, Kind: DiffRemoved, Expected: jsonString(exp[i])})
+			case i >= len(exp):
+				diffs = append(diffs, Difference{Path: elemPath, Kind: DiffAdded, Actual: jsonString(act[i])})
+			default:
+				diffs = append(diffs, diffJSON(elemPath, exp[i], act[i], ignoreOrder)...)
+			}
+		}
+		return diffs
+	default:
+		if !reflect.DeepEqual(expected, actual) {
+			return changed
+		}
+		return nil
+	}
+}
+
+// diffUnordered compares two arrays as multisets: every expected element is
+// paired with a distinct equal actual element, in any order. Expected elements
+// left without a pair are reported as removed at their index in the expected
+// array, actual ones as added at their index in the actual array. Elements
+// holding config.MatchAny can equal several actual elements, so the pairs are
+// found by augmenting paths, which pairs as many elements as possible.
+func diffUnordered(path string, exp, act []interface{}) []Difference {
+	equal := make([][]bool, len(exp))
+	for i := range exp {
+		equal[i] = make([]bool, len(act))
+		for j := range act {
+			equal[i][j] = len(diffJSON("", exp[i], act[j], true)) == 0
+		}
+	}
+
+	// pairOf holds the expected element paired with every actual one, or -1
+	pairOf := make([]int, len(act))
+	for j := range pairOf {
+		pairOf[j] = -1
+	}
+	var pair func(i int, seen []bool) bool
+	pair = func(i int, seen []bool) bool {
+		for j := range act {
+			if !equal[i][j] || seen[j] {
+				continue
+			}
+			seen[j] = true
+			if pairOf[j] < 0 || pair(pairOf[j], seen) {
+				pairOf[j] = i
+				return true
+			}
+		}
+		return false
+	}
+	// an augmenting path re-pairs earlier elements but never unpairs them
+	paired := make([]bool, len(exp))
+	for i := range exp {
+		paired[i] = pair(i, make([]bool, len(act)))
+	}
+
+	var diffs []Difference
+	for i, val := range exp {
+		if !paired[i] {
+			diffs = append(diffs, Difference{Path: fmt.Sprintf("%s.%d", path, i), Kind: DiffRemoved, Expected: jsonString(val)})
+		}
+	}
+	for j, val := range act {
+		if pairOf[j] < 0 {
+			diffs = append(diffs, Difference{Path: fmt.Sprintf("%s.%d", path, j), Kind: DiffAdded, Actual: jsonString(val)})
+		}
+	}
+	return diffs
+}
+
+// matchExample returns the expected body of a match expectation: the
+// normalized YAML value, or the decoded JSON of a string holding a JSON
+// object or array.
+func matchExample(match interface{}) interface{} {
+	if s, ok := match.(string); ok {
+		trimmed := strings.TrimSpace(s)
+		if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+			var decoded interface{}
+			if err := json.Unmarshal([]byte(trimmed), &decoded); err == nil {
+				return decoded
+			}
+		}
+	}
+	return normalizeYAML(match)
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// jsonString formats a decoded JSON value as JSON for messages.
+func jsonString(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
@@ -0,0 +1,80 @@
+package validator
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func decode(t *testing.T, s string) interface{} {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestDiffJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		expected    string
+		actual      string
+		ignoreOrder bool
+		want        []string
+	}{
+		{
+			name:     "equal nested object",
+			expected: `{"config": {"retries": 3, "tags": ["a", "b"]}}`,
+			actual:   `{"config": {"tags": ["a", "b"], "retries": 3}}`,
+		},
+		{
+			name:     "differing nested object",
+			expected: `{"config": {"retries": 3, "mode": "fast"}}`,
+			actual:   `{"config": {"retries": 5, "debug": true}}`,
+			want:     []string{"$.config.mode: missing", "$.config.retries: expected 3, got 5", "$.config.debug: unexpected"},
+		},
+		{
+			name:     "arrays by position",
+			expected: `["a", "b"]`,
+			actual:   `["b", "a"]`,
+			want:     []string{`$.0: expected "a", got "b"`, `$.1: expected "b", got "a"`},
+		},
+		{
+			name:        "arrays in any order",
+			expected:    `[{"id": 1}, {"id": 2}, "x"]`,
+			actual:      `["x", {"id": 2}, {"id": 1}]`,
+			ignoreOrder: true,
+		},
+		{
+			name:        "arrays as multisets",
+			expected:    `["a", "a", "b"]`,
+			actual:      `["b", "a", "c"]`,
+			ignoreOrder: true,
+			want:        []string{"$.1: missing", "$.2: unexpected"},
+		},
+		{
+			name:        "wildcard pairs with the remaining element",
+			expected:    `["<any>", 1]`,
+			actual:      `[1, 2]`,
+			ignoreOrder: true,
+		},
+		{
+			name:        "nested arrays in any order",
+			expected:    `{"groups": [["b", "a"], ["c"]]}`,
+			actual:      `{"groups": [["c"], ["a", "b"]]}`,
+			ignoreOrder: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, d := range diffJSON("$", decode(t, tt.expected), decode(t, tt.actual), tt.ignoreOrder) {
+				got = append(got, d.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package validator
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestQuantifiedValueChecks(t *testing.T) {
+	body := `{
+		"users": [
+			{"status": "active", "role": "user"},
+			{"status": "active", "role": "admin"},
+			{"status": "blocked"}
+		],
+		"tags": ["a", "b"],
+		"empty": [],
+		"name": "ann"
+	}`
+	tests := []struct {
+		check string
+		want  string
+	}{
+		{check: "{path: users, quantifier: any, element: role, value: admin}"},
+		{check: "{path: users, quantifier: all, element: status, value: active}",
+			want: `path users: 2 of 3 elements with status equal to "active", expected all (users.2.status is "blocked")`},
+		{check: "{path: users, quantifier: all, element: role, value: user}",
+			want: `path users: 1 of 3 elements with role equal to "user", expected all (users.1.role is "admin")`},
+		{check: "{path: users, quantifier: any, element: role, value: owner}",
+			want: `path users: 0 of 3 elements with role equal to "owner", expected any (users.0.role is "user")`},
+		{check: "{path: tags, quantifier: any, value: b}"},
+		{check: "{path: tags, quantifier: all, value: a}",
+			want: `path tags: 1 of 2 elements equal to "a", expected all (tags.1 is "b")`},
+		{check: "{path: empty, quantifier: all, value: a}"},
+		{check: "{path: empty, quantifier: any, value: a}",
+			want: `path empty: 0 of 0 elements equal to "a", expected any`},
+		{check: `{path: users, quantifier: any, op: jsonEquals, value: {"status": "blocked"}}`},
+		{check: "{path: name, quantifier: any, value: ann}", want: "path name is not an array"},
+	}
+	for _, tt := range tests {
+		result := validate(t, "status: 200\nvalues: ["+tt.check+"]", response(200, http.Header{}), body)
+		var got string
+		if len(result.Errors) > 0 {
+			got = result.Errors[0]
+		}
+		if got != tt.want || len(result.Errors) > 1 {
+			t.Errorf("%s: errors = %q, want %q", tt.check, result.Errors, tt.want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package validator
+
+import "fmt"
+
+// checkSorted checks that value is an array sorted in ascending (or descending)
+// order, comparing the elements themselves or the field by of every element.
+// Equal neighbours are allowed. It returns a message naming the first element
+// out of order, or an empty string when the array is sorted.
+func checkSorted(value interface{}, by string, descending bool) string {
+	items, ok := value.([]interface{})
+	if !ok {
+		return fmt.Sprintf("expected an array, got %v", value)
+	}
+
+	keys := make([]interface{}, len(items))
+	for i, item := range items {
+		keys[i] = item
+		if by != "" {
+			key, ok := LookupPath(item, by)
+			if !ok {
+				return fmt.Sprintf("element %d has no %s", i, by)
+			}
+			keys[i] = key
+		}
+	}
+
+	direction := "ascending"
+	if descending {
+		direction = "descending"
+	}
+	for i := 1; i < len(keys); i++ {
+		cmp, ok := compareJSON(keys[i-1], keys[i])
+		if !ok {
+			return fmt.Sprintf("cannot compare elements %d and %d (%v and %v)", i-1, i, keys[i-1], keys[i])
+		}
+		if (!descending && cmp > 0) || (descending && cmp < 0) {
+			return fmt.Sprintf("is not sorted %s: element %d (%v) is out of order after %v", direction, i, keys[i], keys[i-1])
+		}
+	}
+	return ""
+}
+
+// compareJSON compares two decoded JSON numbers or strings, returning -1, 0
+// or 1. It reports false for values of other or mixed types.
+func compareJSON(a, b interface{}) (int, bool) {
+	switch x := a.(type) {
+	case float64:
+		y, ok := b.(float64)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		}
+		return 0, true
+	case string:
+		y, ok := b.(string)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		}
+		return 0, true
+	default:
+		return 0, false
+	}
+}
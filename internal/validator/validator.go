@@ -1,9 +1,16 @@
 package validator
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/JakubPluta/tmago/internal/config"
@@ -12,87 +19,807 @@ import (
 
 // ValidationResult represents the result of validating an HTTP response.
 type ValidationResult struct {
-	IsValid    bool
+	IsValid bool
+	// Errors holds the message of every failed check, and Failures the same
+	// failures typed, see AddError.
 	Errors     []string
+	Failures   []ValidationError
 	Duration   time.Duration
 	StatusCode int
 	Body       []byte
+	// MatchedVariant is the name of the anyOf variant the body matched, if any.
+	MatchedVariant string
+	// Diff holds the differences found by failed jsonEquals and match checks,
+	// with the values of redacted paths hidden.
+	Diff []Difference
 }
 
 // Validator is a struct that validates HTTP responses based on a set of expectations.
 type Validator struct {
 	maxDuration time.Duration
-	statusCode  int
+	status      config.Status
+	expect      config.Expectation
+	redact      *config.Redact
 	logger      *logger.Logger
+	maxJSONSize int64
 }
 
-// NewValidator creates a new Validator instance with specified maximum duration
-// and expected HTTP status code. The Validator can be used to validate HTTP
-// responses based on these criteria.
-func NewValidator(maxDuration time.Duration, expectedStatus int) *Validator {
-	logger, err := logger.NewLogger("logs")
-	if err != nil {
-		fmt.Printf("failed to create logger: %v", err)
-	}
+// NewValidator creates a new Validator instance for the given expectation.
+// The maximum duration and expected HTTP status code are taken from the
+// expectation, together with any additional checks (e.g. cookies) it defines.
+// Validation failures are logged to the given logger, with the values of
+// redacted paths and headers hidden; redact may be nil.
+func NewValidator(expect config.Expectation, redact *config.Redact, logger *logger.Logger) *Validator {
 	return &Validator{
-		maxDuration: maxDuration,
-		statusCode:  expectedStatus,
+		maxDuration: expect.MaxTime,
+		status:      expect.Status,
+		expect:      expect,
+		redact:      redact,
 		logger:      logger,
 	}
 }
 
+// SetMaxJSONSize sets the size of the largest body parsed for the JSON checks,
+// DefaultMaxJSONSize when zero.
+func (r *Validator) SetMaxJSONSize(size int64) {
+	r.maxJSONSize = size
+}
+
+// derive returns a validator for another expectation, e.g. a byStatus block
+// merged with the common checks, with the same settings.
+func (r *Validator) derive(expect config.Expectation) *Validator {
+	v := NewValidator(expect, r.redact, r.logger)
+	v.maxJSONSize = r.maxJSONSize
+	return v
+}
+
 // Validate validates an HTTP response against a set of expectations.
 //
-// The function takes an HTTP response, its body, the time it took to receive the response,
-// and a list of value checks. It returns a ValidationResult with the validation result
-// and any errors that occurred during the validation.
+// The function takes an HTTP response, its body and the time it took to receive the response.
+// Parsing the body stops when ctx is done, failing the JSON checks.
+// It returns a ValidationResult with the validation result and any errors that occurred
+// during the validation.
 //
 // The validation process is as follows:
 //
 //  1. The function checks if the response status code matches the expected status code.
 //  2. It checks if the response time is less than the expected maximum duration.
-//  3. If value checks are provided, it unmarshals the response body into a map and checks
-//     if the values at the specified paths match the expected values.
-func (r *Validator) Validate(resp *http.Response, body []byte, duration time.Duration, valueChecks []config.ValueCheck) ValidationResult {
+//  3. If a JSON body is expected or value checks are provided, it unmarshals
+//     the response body into a map and checks if the values at the specified
+//     paths match the expected values.
+//     If anyOf variants are provided, the body must also match the value checks
+//     of at least one of them.
+//  4. If cookie checks are provided, it checks that the response sets the cookies
+//     with the expected attributes.
+//  5. If a content encoding or type is expected, it checks the Content-Encoding
+//     and Content-Type headers.
+//  6. If header checks are provided, it checks that the headers are present,
+//     with the expected value, or absent.
+//  7. If trailer checks are provided, it checks the trailers sent after the body,
+//     which are only available once the body has been read.
+//  8. If a TLS certificate is expected, it checks the expiry, subject and
+//     issuer of the certificate of the server.
+//  9. If a redirect location is expected, it checks the Location header of
+//     3xx responses.
+//
+// With byStatus expectations, the checks of the block matching the status are
+// applied together with the common ones.
+func (r *Validator) Validate(ctx context.Context, resp *http.Response, body []byte, duration time.Duration) ValidationResult {
+	if len(r.expect.ByStatus) > 0 {
+		return r.validateByStatus(ctx, resp, body, duration)
+	}
+
+	valueChecks := r.expect.Values
 	result := ValidationResult{
-		Duration: duration,
-		Errors:   make([]string, 0),
+		Duration:   duration,
+		Errors:     make([]string, 0),
+		StatusCode: resp.StatusCode,
+	}
+
+	// an unreachable endpoint must not respond at all
+	if r.expect.Unreachable {
+		r.fail(&result, ValidationError{Kind: KindUnreachable, Actual: strconv.Itoa(resp.StatusCode),
+			Message: fmt.Sprintf("expected endpoint to be unreachable, got status code %d", resp.StatusCode)})
+		return result
 	}
 
 	// validate status code
-	if resp.StatusCode != r.statusCode {
-		r.logger.Warn(fmt.Sprintf("expected status code %d, got %d", r.statusCode, resp.StatusCode))
-		result.Errors = append(result.Errors, fmt.Sprintf("expected status code %d, got %d", r.statusCode, resp.StatusCode))
-	}
-
-	// Response time validation
-	if duration > r.maxDuration {
-		r.logger.Warn(fmt.Sprintf("expected response time less than %s, got %s", r.maxDuration, duration))
-		result.Errors = append(result.Errors, fmt.Sprintf("expected response time less than %s, got %s", r.maxDuration, duration))
-	}
-	// value checks
-	if len(valueChecks) > 0 {
-		var responseData map[string]interface{}
-		if err := json.Unmarshal(body, &responseData); err != nil {
-			r.logger.Warn(fmt.Sprintf("failed to unmarshal response body: %v", err))
-			result.Errors = append(result.Errors, fmt.Sprintf("failed to unmarshal response body: %v", err))
+	if !r.status.Matches(resp.StatusCode) && !(r.expect.AllowRedirects && isRedirect(resp.StatusCode)) {
+		r.fail(&result, ValidationError{Kind: KindStatus, Expected: r.status.String(), Actual: strconv.Itoa(resp.StatusCode),
+			Message: fmt.Sprintf("expected status code %s, got %d", r.status, resp.StatusCode)})
+	}
+
+	// Response time validation, a zero maxTime sets no limit
+	if r.maxDuration > 0 && duration > r.maxDuration {
+		r.fail(&result, ValidationError{Kind: KindResponseTime, Expected: r.maxDuration.String(), Actual: duration.String(),
+			Message: fmt.Sprintf("expected response time less than %s, got %s", r.maxDuration, duration)})
+	}
+	// JSON body and value checks
+	if r.expect.JSON || len(valueChecks) > 0 || len(r.expect.AnyOf) > 0 || r.expect.Match != nil || len(r.expect.BodyOneOf) > 0 {
+		responseData, err := DecodeJSON(ctx, body, r.maxJSONSize)
+		if err != nil {
+			msg := fmt.Sprintf("failed to unmarshal response body: %v", err)
+			if r.expect.JSON {
+				msg = fmt.Sprintf("expected a valid JSON body: %v", err)
+			}
+			r.fail(&result, ValidationError{Kind: KindBody, Message: msg})
 		} else {
-			for _, check := range valueChecks {
-				if val, ok := responseData[check.Path]; !ok {
-					r.logger.Warn(fmt.Sprintf("path %s not found in response", check.Path))
-					result.Errors = append(result.Errors, fmt.Sprintf("path %s not found in response", check.Path))
-				} else if fmt.Sprintf("%v", val) != fmt.Sprintf("%v", check.Value) {
-					r.logger.Info(fmt.Sprintf("type of val %T and expected %T", val, check.Value))
-					r.logger.Warn(fmt.Sprintf("path %s expected %v, got %v", check.Path, check.Value, val))
-					result.Errors = append(result.Errors, fmt.Sprintf("path %s expected %v, got %v", check.Path, check.Value, val))
+			errs, diff := r.checkValues(responseData, valueChecks)
+			r.fail(&result, errs...)
+			result.Diff = diff
+			if r.expect.Match != nil {
+				errs, diff := r.checkMatch(responseData)
+				r.fail(&result, errs...)
+				result.Diff = append(result.Diff, diff...)
+			}
+			if len(r.expect.BodyOneOf) > 0 {
+				if msg := r.matchCandidate(responseData); msg != "" {
+					r.fail(&result, ValidationError{Kind: KindBodyOneOf, Message: msg})
 				}
-
 			}
+			if len(r.expect.AnyOf) > 0 {
+				variant, msg := r.matchVariant(responseData)
+				result.MatchedVariant = variant
+				if msg != "" {
+					r.fail(&result, ValidationError{Kind: KindVariant, Message: msg})
+				}
+			}
+		}
+	}
+	// cookie checks
+	if len(r.expect.Cookies) > 0 {
+		r.fail(&result, r.validateCookies(resp.Cookies())...)
+	}
+	// negotiated content encoding
+	if r.expect.ContentEncoding != "" {
+		if msg := checkContentEncoding(r.expect.ContentEncoding, resp.Header.Get("Content-Encoding")); msg != "" {
+			r.fail(&result, ValidationError{Kind: KindContentEncoding, Expected: r.expect.ContentEncoding,
+				Actual: resp.Header.Get("Content-Encoding"), Message: msg})
 		}
 	}
+	// media type
+	if r.expect.ContentType != "" {
+		if actual := resp.Header.Get("Content-Type"); !config.ContentTypeMatches(r.expect.ContentType, actual) {
+			r.fail(&result, ValidationError{Kind: KindContentType, Expected: r.expect.ContentType, Actual: actual,
+				Message: fmt.Sprintf("expected content type %s, got %q", r.expect.ContentType, actual)})
+		}
+	}
+	// header checks
+	if len(r.expect.Headers) > 0 {
+		r.fail(&result, r.validateHeaders(resp.Header)...)
+	}
+	if r.expect.ExactHeaders {
+		r.fail(&result, r.checkExactHeaders(resp.Header)...)
+	}
+	// trailer checks
+	if len(r.expect.Trailers) > 0 {
+		r.fail(&result, r.validateTrailers(resp.Trailer)...)
+	}
+	// server certificate
+	if r.expect.TLS != nil {
+		r.fail(&result, checkCertificate(*r.expect.TLS, resp.TLS, time.Now())...)
+	}
+	// redirect target
+	if r.expect.RedirectLocation != nil && isRedirect(resp.StatusCode) {
+		if location := resp.Header.Get("Location"); !r.expect.RedirectLocation.Matches(location) {
+			actual := r.redact.Header("Location", location)
+			r.fail(&result, ValidationError{Kind: KindRedirect, Expected: r.expect.RedirectLocation.String(), Actual: actual,
+				Message: fmt.Sprintf("expected redirect location %s, got %q", r.expect.RedirectLocation, actual)})
+		}
+	}
+	if len(result.Diff) > 0 {
+		lines := make([]string, len(result.Diff))
+		for i, d := range result.Diff {
+			lines[i] = d.Line()
+		}
+		r.logger.Diff(lines)
+	}
 	result.IsValid = len(result.Errors) == 0
 	if !result.IsValid {
 		r.logger.Warn(fmt.Sprintf("validation failed: %v", result.Errors))
 	}
 	return result
 }
+
+// validateByStatus validates a response against the byStatus block matching its
+// status, merged with the common checks of the expectation. When no block
+// matches, the top-level checks apply if a top-level status is set; otherwise
+// the response fails.
+func (r *Validator) validateByStatus(ctx context.Context, resp *http.Response, body []byte, duration time.Duration) ValidationResult {
+	expect := r.expect
+	expect.ByStatus = nil
+
+	block, ok := r.expect.ByStatus[resp.StatusCode]
+	if !ok && r.expect.Status.IsSet() {
+		// fall back to the top-level checks
+		return r.derive(expect).Validate(ctx, resp, body, duration)
+	}
+
+	expect.Status = config.ExactStatus(resp.StatusCode)
+	if ok {
+		if block.MaxTime > 0 {
+			expect.MaxTime = block.MaxTime
+		}
+		expect.Values = append(append([]config.ValueCheck{}, expect.Values...), block.Values...)
+		if len(block.AnyOf) > 0 {
+			expect.AnyOf = block.AnyOf
+		}
+		expect.Cookies = append(append([]config.CookieCheck{}, expect.Cookies...), block.Cookies...)
+		expect.Trailers = append(append([]config.TrailerCheck{}, expect.Trailers...), block.Trailers...)
+		expect.Headers = append(append([]config.HeaderCheck{}, expect.Headers...), block.Headers...)
+		expect.ExactHeaders = expect.ExactHeaders || block.ExactHeaders
+		expect.IgnoreHeaders = append(append([]string{}, expect.IgnoreHeaders...), block.IgnoreHeaders...)
+		expect.JSON = expect.JSON || block.JSON
+		if block.Match != nil {
+			expect.Match = block.Match
+		}
+		if len(block.BodyOneOf) > 0 {
+			expect.BodyOneOf = block.BodyOneOf
+		}
+		if block.ContentEncoding != "" {
+			expect.ContentEncoding = block.ContentEncoding
+		}
+		if block.ContentType != "" {
+			expect.ContentType = block.ContentType
+		}
+		if block.RedirectLocation != nil {
+			expect.RedirectLocation = block.RedirectLocation
+		}
+		if block.TLS != nil {
+			expect.TLS = block.TLS
+		}
+	}
+
+	result := r.derive(expect).Validate(ctx, resp, body, duration)
+	if !ok {
+		statuses := make([]int, 0, len(r.expect.ByStatus))
+		for status := range r.expect.ByStatus {
+			statuses = append(statuses, status)
+		}
+		sort.Ints(statuses)
+		e := ValidationError{Kind: KindStatus, Expected: fmt.Sprint(statuses), Actual: strconv.Itoa(resp.StatusCode),
+			Message: fmt.Sprintf("expected status code one of %v, got %d", statuses, resp.StatusCode)}
+		r.logger.Warn(e.Message)
+		result.Errors = append([]string{e.Message}, result.Errors...)
+		result.Failures = append([]ValidationError{e}, result.Failures...)
+		result.IsValid = false
+	}
+	return result
+}
+
+// fail logs failed checks and records them in the result.
+func (r *Validator) fail(result *ValidationResult, errs ...ValidationError) {
+	for _, e := range errs {
+		r.logger.Warn(e.Message)
+		result.AddError(e)
+	}
+}
+
+// isRedirect reports whether status is a 3xx redirection status.
+func isRedirect(status int) bool {
+	return status >= 300 && status < 400
+}
+
+// checkContentEncoding compares the Content-Encoding of a response with the
+// expected one and returns a message when they differ. A missing header
+// matches "identity".
+func checkContentEncoding(expected, actual string) string {
+	if actual == "" {
+		actual = "identity"
+	}
+	if !strings.EqualFold(strings.TrimSpace(actual), expected) {
+		return fmt.Sprintf("expected content encoding %s, got %s", expected, actual)
+	}
+	return ""
+}
+
+// checkValues checks the values at the paths of the value checks in decoded
+// JSON data and returns an error for every failed check, together with the
+// differences found by failed jsonEquals checks.
+// The values of redacted paths are hidden in the errors and differences.
+func (r *Validator) checkValues(data interface{}, checks []config.ValueCheck) ([]ValidationError, []Difference) {
+	var errs []ValidationError
+	var diff []Difference
+	for _, check := range checks {
+		val, ok := LookupPath(data, check.Path)
+		fail := func(msg string) {
+			errs = append(errs, ValidationError{Kind: KindValue, Path: check.Path, Message: msg})
+		}
+		switch {
+		case !ok && check.Optional:
+		case !ok:
+			fail(fmt.Sprintf("path %s not found in response", check.Path))
+		case check.Quantifier != "":
+			if msg := r.checkElements(check, val); msg != "" {
+				fail(msg)
+			}
+		case check.Op == config.ValueOpJSONEquals:
+			if diffs := diffJSON(check.Path, normalizeYAML(check.Value), val, check.IgnoreOrder); len(diffs) > 0 {
+				msgs := make([]string, len(diffs))
+				for i, d := range diffs {
+					msgs[i] = d.String()
+					diff = append(diff, r.redactDifference(d, false))
+				}
+				fail(fmt.Sprintf("path %s does not equal the expected JSON: %s", check.Path, r.redact.Value(check.Path, strings.Join(msgs, ", "))))
+			}
+		case check.Op == config.ValueOpSorted:
+			if msg := checkSorted(val, check.By, check.Direction == config.SortDescending); msg != "" {
+				fail(fmt.Sprintf("path %s %s", check.Path, r.redact.Value(check.Path, msg)))
+			}
+		case check.Op == config.ValueOpEqualsPath:
+			other, ok := LookupPath(data, check.OtherPath)
+			if !ok {
+				fail(fmt.Sprintf("path %s not found in response", check.OtherPath))
+			} else if !reflect.DeepEqual(val, other) {
+				fail(fmt.Sprintf("path %s (%s) does not equal path %s (%s)", check.Path, r.redact.Value(check.Path, jsonString(val)),
+					check.OtherPath, r.redact.Value(check.OtherPath, jsonString(other))))
+			}
+		default:
+			if e, ok := r.checkEquals(check, val); !ok {
+				errs = append(errs, e)
+			}
+		}
+	}
+	return errs, diff
+}
+
+// checkEquals compares the value at the path of an equals check with the
+// expected value, converted to the valueType of the check when set, and
+// returns an error and false when they differ.
+func (r *Validator) checkEquals(check config.ValueCheck, val interface{}) (ValidationError, bool) {
+	e := ValidationError{Kind: KindValue, Path: check.Path}
+	expected, err := config.CoerceValue(normalizeYAML(check.Value), check.ValueType)
+	if err != nil {
+		// a captured value that does not convert
+		e.Message = fmt.Sprintf("path %s: %s", check.Path, r.redact.Value(check.Path, err.Error()))
+		return e, false
+	}
+	if valuesEqual(expected, val, check.ValueType) {
+		return e, true
+	}
+	hint := typeHint(expected, val)
+	if check.ValueType != "" {
+		hint = ""
+		if check.ValueType != config.ValueTypeAny && isScalar(val) && jsonType(val) != check.ValueType {
+			hint = fmt.Sprintf(" (valueType %s, got a %s)", check.ValueType, jsonType(val))
+		}
+	}
+	e.Expected = r.redact.Value(check.Path, jsonString(expected))
+	e.Actual = r.redact.Value(check.Path, jsonString(val))
+	e.Message = fmt.Sprintf("path %s expected %s, got %s%s", check.Path, e.Expected, e.Actual, hint)
+	return e, false
+}
+
+// checkElements checks the elements of the array val at the path of a
+// quantified check and returns a message with the number of matching
+// elements and the first mismatch when the check fails. All elements of an
+// empty array match.
+func (r *Validator) checkElements(check config.ValueCheck, val interface{}) string {
+	items, ok := val.([]interface{})
+	if !ok {
+		return fmt.Sprintf("path %s is not an array", check.Path)
+	}
+
+	matched := 0
+	mismatch := ""
+	for i, item := range items {
+		path := fmt.Sprintf("%s.%d", check.Path, i)
+		if check.Element != "" {
+			path += "." + check.Element
+			item, ok = LookupPath(item, check.Element)
+		}
+		switch {
+		case ok && elementMatches(check, item):
+			matched++
+		case mismatch != "":
+		case !ok:
+			mismatch = fmt.Sprintf("%s not found", path)
+		default:
+			mismatch = fmt.Sprintf("%s is %s", path, r.redact.Value(path, jsonString(item)))
+		}
+	}
+
+	if check.Quantifier == config.QuantifierAll && matched == len(items) ||
+		check.Quantifier == config.QuantifierAny && matched > 0 {
+		return ""
+	}
+	target := "elements"
+	if check.Element != "" {
+		target += " with " + check.Element
+	}
+	msg := fmt.Sprintf("path %s: %d of %d %s equal to %s, expected %s", check.Path, matched, len(items), target,
+		r.redact.Value(check.Path, jsonString(normalizeYAML(check.Value))), check.Quantifier)
+	if mismatch != "" {
+		msg += " (" + mismatch + ")"
+	}
+	return msg
+}
+
+// elementMatches compares an array element, or the value within it, with the
+// value of a quantified check.
+func elementMatches(check config.ValueCheck, val interface{}) bool {
+	if check.Op == config.ValueOpJSONEquals {
+		return len(diffJSON("", normalizeYAML(check.Value), val, check.IgnoreOrder)) == 0
+	}
+	expected, err := config.CoerceValue(normalizeYAML(check.Value), check.ValueType)
+	return err == nil && valuesEqual(expected, val, check.ValueType)
+}
+
+// valuesEqual compares an expected value from the config with a decoded JSON
+// value, keeping their types: the YAML string "200" does not equal the JSON
+// number 200, nor does the YAML number 200 equal the JSON string "200".
+// Numbers are compared by value, whether written as integers or not. With
+// the any valueType, scalars are compared by their text instead.
+func valuesEqual(expected, actual interface{}, valueType string) bool {
+	expected = normalizeYAML(expected)
+	if valueType == config.ValueTypeAny && isScalar(expected) && isScalar(actual) {
+		return fmt.Sprintf("%v", expected) == fmt.Sprintf("%v", actual)
+	}
+	return reflect.DeepEqual(expected, actual)
+}
+
+// typeHint explains a mismatch between an expected and an actual scalar that
+// only differ in type, e.g. the string "200" and the number 200, which
+// likely comes from quoting, or not quoting, the value in the config. It
+// returns "" for other mismatches.
+func typeHint(expected, actual interface{}) string {
+	_, expectedString := expected.(string)
+	_, actualString := actual.(string)
+	if expectedString == actualString || !isScalar(expected) || !isScalar(actual) ||
+		fmt.Sprintf("%v", expected) != fmt.Sprintf("%v", actual) {
+		return ""
+	}
+	if expectedString {
+		return fmt.Sprintf(" (expected a string, got a %s: unquote the value in the config to expect a %s)", jsonType(actual), jsonType(actual))
+	}
+	return fmt.Sprintf(" (expected a %s, got a string: quote the value in the config to expect a string)", jsonType(expected))
+}
+
+// isScalar reports whether a decoded value is a string, number, boolean or null.
+func isScalar(v interface{}) bool {
+	switch v.(type) {
+	case map[string]interface{}, []interface{}:
+		return false
+	}
+	return true
+}
+
+// jsonType names the JSON type of a decoded scalar for messages.
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// checkMatch compares decoded JSON data with the example body of the match
+// expectation and returns an error for every difference, with its path from
+// the root ($), together with the differences. Differences at redacted paths
+// do not show the values.
+func (r *Validator) checkMatch(data interface{}) ([]ValidationError, []Difference) {
+	diffs := diffJSON("$", matchExample(r.expect.Match), data, false)
+	errs := make([]ValidationError, len(diffs))
+	for i, d := range diffs {
+		// a difference at the root shows the whole body
+		root := d.Path == "$" && r.redact != nil && len(r.redact.Paths) > 0
+		redacted := root || r.redact.IsPath(strings.TrimPrefix(d.Path, "$."))
+		diffs[i] = r.redactDifference(d, redacted)
+		errs[i] = ValidationError{Kind: KindMatch, Path: d.Path, Expected: diffs[i].Expected, Actual: diffs[i].Actual}
+		if redacted {
+			errs[i].Message = "match " + d.Path + ": " + config.RedactedValue
+		} else {
+			errs[i].Message = "match " + d.String()
+		}
+	}
+	return errs, diffs
+}
+
+// redactDifference hides the values of a difference when redacted is set or
+// its path is redacted. Other values are redacted by the patterns.
+func (r *Validator) redactDifference(d Difference, redacted bool) Difference {
+	if redacted || r.redact.IsPath(d.Path) {
+		if d.Expected != "" {
+			d.Expected = config.RedactedValue
+		}
+		if d.Actual != "" {
+			d.Actual = config.RedactedValue
+		}
+		return d
+	}
+	d.Expected = r.redact.Text(d.Expected)
+	d.Actual = r.redact.Text(d.Actual)
+	return d
+}
+
+// matchCandidate checks decoded JSON data against the bodyOneOf candidates in
+// order, passing when it deeply equals one of them. When none matches, it
+// returns a message naming every candidate tried with its first difference.
+func (r *Validator) matchCandidate(data interface{}) string {
+	tried := make([]string, 0, len(r.expect.BodyOneOf))
+	for i, candidate := range r.expect.BodyOneOf {
+		name := candidate.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		diffs := diffJSON("$", matchExample(candidate.Value), data, false)
+		if len(diffs) == 0 {
+			r.logger.Debug(fmt.Sprintf("response matched bodyOneOf candidate %s", name))
+			return ""
+		}
+		d := diffs[0]
+		root := d.Path == "$" && r.redact != nil && len(r.redact.Paths) > 0
+		first := r.redactDifference(d, root || r.redact.IsPath(strings.TrimPrefix(d.Path, "$."))).String()
+		if len(diffs) > 1 {
+			first = fmt.Sprintf("%s and %d more differences", first, len(diffs)-1)
+		}
+		tried = append(tried, fmt.Sprintf("%s (%s)", name, first))
+	}
+	return fmt.Sprintf("body matched none of the %d bodyOneOf candidates: %s", len(tried), strings.Join(tried, ", "))
+}
+
+// matchVariant checks decoded JSON data against the anyOf variants in order
+// and returns the name of the first one whose value checks all pass. When no
+// variant matches, it returns a message with the failures of every variant.
+func (r *Validator) matchVariant(data interface{}) (string, string) {
+	diagnostics := make([]string, 0, len(r.expect.AnyOf))
+	for i, variant := range r.expect.AnyOf {
+		name := variant.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		errs, _ := r.checkValues(data, variant.Values)
+		if len(errs) == 0 {
+			r.logger.Debug(fmt.Sprintf("response matched anyOf variant %s", name))
+			return name, ""
+		}
+		msgs := make([]string, len(errs))
+		for i, e := range errs {
+			msgs[i] = e.Message
+		}
+		diagnostics = append(diagnostics, fmt.Sprintf("variant %s: %s", name, strings.Join(msgs, ", ")))
+	}
+	return "", fmt.Sprintf("no anyOf variant matched (%s)", strings.Join(diagnostics, "; "))
+}
+
+// exactHeadersIgnored are the headers allowed in addition to the header checks
+// of an exactHeaders expectation: hop-by-hop headers, describing the
+// connection rather than the response, and headers set by most servers.
+var exactHeadersIgnored = []string{
+	"Connection", "Keep-Alive", "Proxy-Connection", "Proxy-Authenticate", "Te", "Trailer", "Transfer-Encoding", "Upgrade",
+	"Date", "Content-Length",
+}
+
+// checkExactHeaders returns an error for every header sent by the response
+// that is neither expected by a header check nor ignored. Expected headers
+// that are missing are reported by the header checks themselves.
+func (r *Validator) checkExactHeaders(header http.Header) []ValidationError {
+	allowed := make(map[string]bool)
+	for _, name := range exactHeadersIgnored {
+		allowed[http.CanonicalHeaderKey(name)] = true
+	}
+	for _, name := range r.expect.IgnoreHeaders {
+		allowed[http.CanonicalHeaderKey(name)] = true
+	}
+	for _, check := range r.expect.Headers {
+		if !check.Absent {
+			allowed[http.CanonicalHeaderKey(check.Name)] = true
+		}
+	}
+
+	names := make([]string, 0, len(header))
+	for name := range header {
+		if !allowed[http.CanonicalHeaderKey(name)] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	errs := make([]ValidationError, len(names))
+	for i, name := range names {
+		actual := r.redact.Header(name, strings.Join(header.Values(name), ", "))
+		errs[i] = ValidationError{Kind: KindHeader, Path: name, Actual: actual,
+			Message: fmt.Sprintf("unexpected header %s sent with value %q", name, actual)}
+	}
+	return errs
+}
+
+// ValidateTransportError validates a request that failed before a response was
+// received. The request is valid only when the endpoint is expected to be
+// unreachable and the error shows that it could not be reached.
+func (r *Validator) ValidateTransportError(err error, duration time.Duration) ValidationResult {
+	result := ValidationResult{
+		Duration: duration,
+		Errors:   make([]string, 0),
+	}
+
+	if !r.expect.Unreachable {
+		result.AddError(ValidationError{Kind: KindTransport, Message: err.Error()})
+		return result
+	}
+
+	reason, ok := UnreachableReason(err)
+	if !ok {
+		r.fail(&result, ValidationError{Kind: KindUnreachable, Actual: err.Error(),
+			Message: fmt.Sprintf("expected endpoint to be unreachable, got error: %v", err)})
+		return result
+	}
+
+	r.logger.Info(fmt.Sprintf("endpoint unreachable as expected: %s", reason))
+	result.IsValid = true
+	return result
+}
+
+// UnreachableReason classifies a transport error. It reports whether the error
+// shows that the endpoint could not be reached (connection refused or reset,
+// host or network unreachable, DNS failure or timeout) and the matching class.
+func UnreachableReason(err error) (string, bool) {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return "", false
+	}
+
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	switch {
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return "connection refused", true
+	case errors.Is(err, syscall.ECONNRESET):
+		return "connection reset", true
+	case errors.Is(err, syscall.EHOSTUNREACH):
+		return "host unreachable", true
+	case errors.Is(err, syscall.ENETUNREACH):
+		return "network unreachable", true
+	case errors.As(err, &dnsErr):
+		return "dns lookup failed", true
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout", true
+	default:
+		return "", false
+	}
+}
+
+// validateCookies checks the cookies set by the response against the expected
+// cookie checks and returns a message for every missing cookie or mismatched
+// attribute.
+func (r *Validator) validateCookies(cookies []*http.Cookie) []ValidationError {
+	var errs []ValidationError
+	for _, check := range r.expect.Cookies {
+		fail := func(expected, actual, msg string) {
+			errs = append(errs, ValidationError{Kind: KindCookie, Path: check.Name, Expected: expected, Actual: actual, Message: msg})
+		}
+		var cookie *http.Cookie
+		for _, c := range cookies {
+			if c.Name == check.Name {
+				cookie = c
+				break
+			}
+		}
+		if cookie == nil {
+			fail("", "", fmt.Sprintf("cookie %s not set in response", check.Name))
+			continue
+		}
+		if check.Value != nil && cookie.Value != *check.Value {
+			expected, actual := r.cookieValue(*check.Value), r.cookieValue(cookie.Value)
+			fail(expected, actual, fmt.Sprintf("cookie %s expected value %s, got %s", check.Name, expected, actual))
+		}
+		if check.HttpOnly != nil && cookie.HttpOnly != *check.HttpOnly {
+			fail(strconv.FormatBool(*check.HttpOnly), strconv.FormatBool(cookie.HttpOnly),
+				fmt.Sprintf("cookie %s expected httpOnly %t, got %t", check.Name, *check.HttpOnly, cookie.HttpOnly))
+		}
+		if check.Secure != nil && cookie.Secure != *check.Secure {
+			fail(strconv.FormatBool(*check.Secure), strconv.FormatBool(cookie.Secure),
+				fmt.Sprintf("cookie %s expected secure %t, got %t", check.Name, *check.Secure, cookie.Secure))
+		}
+		if check.SameSite != "" {
+			if got := sameSiteName(cookie.SameSite); !strings.EqualFold(got, check.SameSite) {
+				fail(check.SameSite, got, fmt.Sprintf("cookie %s expected sameSite %s, got %s", check.Name, check.SameSite, got))
+			}
+		}
+	}
+	return errs
+}
+
+// validateTrailers checks the expected trailers against the trailers of a
+// response and returns a message for every failed check.
+func (r *Validator) validateTrailers(trailer http.Header) []ValidationError {
+	var errs []ValidationError
+	for _, check := range r.expect.Trailers {
+		values := trailer.Values(check.Name)
+		if len(values) == 0 {
+			errs = append(errs, ValidationError{Kind: KindTrailer, Path: check.Name,
+				Message: fmt.Sprintf("trailer %s not sent in response", check.Name)})
+			continue
+		}
+		if check.Value != nil && values[0] != *check.Value {
+			expected, actual := r.redact.Header(check.Name, *check.Value), r.redact.Header(check.Name, values[0])
+			errs = append(errs, ValidationError{Kind: KindTrailer, Path: check.Name, Expected: expected, Actual: actual,
+				Message: fmt.Sprintf("trailer %s expected value %s, got %s", check.Name, expected, actual)})
+		}
+	}
+	return errs
+}
+
+// validateHeaders checks the response headers against the header checks and
+// returns a message for every failed check. A header that must be absent is
+// reported with the value it was sent with.
+func (r *Validator) validateHeaders(header http.Header) []ValidationError {
+	var errs []ValidationError
+	for _, check := range r.expect.Headers {
+		values := header.Values(check.Name)
+		switch {
+		case check.Absent:
+			if len(values) > 0 {
+				actual := r.redact.Header(check.Name, strings.Join(values, ", "))
+				errs = append(errs, ValidationError{Kind: KindHeader, Path: check.Name, Actual: actual,
+					Message: fmt.Sprintf("forbidden header %s sent with value %q", check.Name, actual)})
+			}
+		case len(values) == 0:
+			errs = append(errs, ValidationError{Kind: KindHeader, Path: check.Name,
+				Message: fmt.Sprintf("header %s not sent in response", check.Name)})
+		case check.Value != nil && values[0] != *check.Value:
+			expected, actual := r.redact.Header(check.Name, *check.Value), r.redact.Header(check.Name, values[0])
+			errs = append(errs, ValidationError{Kind: KindHeader, Path: check.Name, Expected: expected, Actual: actual,
+				Message: fmt.Sprintf("header %s expected value %s, got %s", check.Name, expected, actual)})
+		}
+	}
+	return errs
+}
+
+// cookieValue returns the value of a cookie for messages, redacted with the
+// Set-Cookie header.
+func (r *Validator) cookieValue(value string) string {
+	return r.redact.Header("Set-Cookie", value)
+}
+
+// sameSiteName returns the attribute name of the given SameSite mode as it
+// appears in a Set-Cookie header.
+func sameSiteName(mode http.SameSite) string {
+	switch mode {
+	case http.SameSiteLaxMode:
+		return "Lax"
+	case http.SameSiteStrictMode:
+		return "Strict"
+	case http.SameSiteNoneMode:
+		return "None"
+	default:
+		return ""
+	}
+}
+
+// LookupPath resolves a dot separated path (e.g. "data.items.0.id") in decoded
+// JSON data. Numeric segments index into arrays. A key containing dots is
+// matched as a whole before the path is split.
+func LookupPath(data interface{}, path string) (interface{}, bool) {
+	if m, ok := data.(map[string]interface{}); ok {
+		if val, ok := m[path]; ok {
+			return val, true
+		}
+	}
+
+	current := data
+	for _, part := range strings.Split(path, ".") {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			val, ok := node[part]
+			if !ok {
+				return nil, false
+			}
+			current = val
+		case []interface{}:
+			idx, err := strconv.Atoi(part)
+			if err != nil || idx < 0 || idx >= len(node) {
+				return nil, false
+			}
+			current = node[idx]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
@@ -0,0 +1,39 @@
+package validator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOptionalValueChecks(t *testing.T) {
+	const expect = `
+status: 200
+values:
+  - path: nickname
+    value: ann
+    optional: true
+  - path: id
+    value: 1
+`
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{name: "absent optional field", body: `{"id": 1}`},
+		{name: "present optional field", body: `{"id": 1, "nickname": "ann"}`},
+		{name: "wrong optional field", body: `{"id": 1, "nickname": "bob"}`, want: []string{`path nickname expected "ann", got "bob"`}},
+		{name: "absent required field", body: `{}`, want: []string{"path id not found in response"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := validate(t, expect, response(200, nil), tt.body)
+			if len(tt.want) == 0 {
+				tt.want = []string{}
+			}
+			if !reflect.DeepEqual(result.Errors, tt.want) || result.IsValid != (len(tt.want) == 0) {
+				t.Errorf("valid %t with errors %q, want %q", result.IsValid, result.Errors, tt.want)
+			}
+		})
+	}
+}
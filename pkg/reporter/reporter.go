@@ -0,0 +1,55 @@
+// Package reporter holds the results tmago passes to programs embedding it,
+// e.g. to the result sinks of package runner, and the report writers that
+// add output formats.
+package reporter
+
+import "github.com/JakubPluta/tmago/internal/reporter"
+
+type (
+	// RequestDetail is a completed request, as passed to result sinks and
+	// listed in the reports.
+	RequestDetail = reporter.RequestDetail
+	// TestResult is the result of an endpoint.
+	TestResult = reporter.TestResult
+)
+
+type (
+	// Report is a finished report, as passed to report writers.
+	Report = reporter.Report
+	// ReportWriter writes a finished report in one output format. Implement
+	// it to send reports to a custom backend, and register it with
+	// RegisterFormat to select it by name with --format.
+	ReportWriter = reporter.ReportWriter
+	// WriterFactory creates the writer of a format, writing into dir.
+	WriterFactory = reporter.WriterFactory
+	// Built-in writers
+	HTMLWriter  = reporter.HTMLWriter
+	JSONWriter  = reporter.JSONWriter
+	CSVWriter   = reporter.CSVWriter
+	JUnitWriter = reporter.JUnitWriter
+)
+
+// Built-in report formats.
+const (
+	FormatHTML  = reporter.FormatHTML
+	FormatJSON  = reporter.FormatJSON
+	FormatCSV   = reporter.FormatCSV
+	FormatJUnit = reporter.FormatJUnit
+)
+
+// RegisterFormat makes a format selectable by name, replacing any format of
+// the same name. Register formats before creating the runner, or before
+// cmd.Execute to select them with --format.
+func RegisterFormat(name string, factory WriterFactory) {
+	reporter.RegisterFormat(name, factory)
+}
+
+// Formats returns the names of the registered formats, sorted.
+func Formats() []string {
+	return reporter.Formats()
+}
+
+// NewWriter returns the writer of a registered format, writing into dir.
+func NewWriter(format, dir string) (ReportWriter, error) {
+	return reporter.NewWriter(format, dir)
+}
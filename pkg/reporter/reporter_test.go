@@ -0,0 +1,41 @@
+package reporter_test
+
+import (
+	"testing"
+
+	"github.com/JakubPluta/tmago/pkg/reporter"
+)
+
+// dbWriter stands for a writer sending reports to a results database.
+type dbWriter struct {
+	dir string
+}
+
+func (w dbWriter) Write(report *reporter.Report) error {
+	return nil
+}
+
+func TestRegisterFormat(t *testing.T) {
+	reporter.RegisterFormat("resultsdb", func(dir string) reporter.ReportWriter {
+		return dbWriter{dir: dir}
+	})
+
+	found := false
+	for _, name := range reporter.Formats() {
+		found = found || name == "resultsdb"
+	}
+	if !found {
+		t.Fatalf("formats %v do not list the registered format", reporter.Formats())
+	}
+
+	w, err := reporter.NewWriter("resultsdb", "reports")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := w.(dbWriter); !ok || got.dir != "reports" {
+		t.Errorf("writer = %#v, want the registered writer for reports", w)
+	}
+	if _, err := reporter.NewWriter("unknown", "reports"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
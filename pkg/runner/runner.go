@@ -0,0 +1,55 @@
+// Package runner runs tmago configurations from Go programs embedding tmago,
+// e.g. to forward every completed request to a datastore with a ResultSink.
+//
+//	cfg, err := runner.LoadConfig("tmago.yaml")
+//	if err != nil {
+//		return err
+//	}
+//	r, err := runner.NewRunner(cfg, runner.Options{Sinks: []runner.ResultSink{sink}})
+//	if err != nil {
+//		return err
+//	}
+//	return r.Run(ctx)
+package runner
+
+import (
+	"fmt"
+
+	"github.com/JakubPluta/tmago/internal/config"
+	"github.com/JakubPluta/tmago/internal/runner"
+)
+
+type (
+	// Config is a loaded tmago configuration.
+	Config = config.Config
+	// Runner runs the endpoints of a configuration and writes the reports.
+	Runner = runner.Runner
+	// Options configure a Runner like the flags of the run command.
+	Options = runner.Options
+	// ResultSink receives every completed request as soon as it completes.
+	// The reports are built by the default sink, so registered sinks come in
+	// addition to them.
+	ResultSink = runner.ResultSink
+)
+
+// ReportsDir receives the reports of the run.
+const ReportsDir = runner.ReportsDir
+
+// LoadConfig loads and validates the configuration file at path, like the
+// run command does.
+func LoadConfig(path string) (*Config, error) {
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		return nil, fmt.Errorf("loading config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	return cfg, nil
+}
+
+// NewRunner creates a runner for cfg. Sinks can also be registered with
+// Runner.AddSink before Run.
+func NewRunner(cfg *Config, opts Options) (*Runner, error) {
+	return runner.NewRunner(cfg, opts)
+}
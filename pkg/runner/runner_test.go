@@ -0,0 +1,88 @@
+package runner_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/JakubPluta/tmago/pkg/reporter"
+	"github.com/JakubPluta/tmago/pkg/runner"
+)
+
+// countingSink counts the requests it receives per endpoint.
+type countingSink struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (s *countingSink) Record(detail reporter.RequestDetail, endpointName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counts[endpointName]++
+}
+
+func TestResultSinkReceivesEveryRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	config := `
+endpoints:
+  - name: single
+    url: ` + server.URL + `
+    method: GET
+  - name: concurrent
+    url: ` + server.URL + `
+    method: GET
+    concurrent:
+      users: 4
+      total: 25
+`
+	if err := os.WriteFile(path, []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	chdir(t, dir)
+	if err := os.Mkdir(runner.ReportsDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := runner.LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink := &countingSink{counts: map[string]int{}}
+	r, err := runner.NewRunner(cfg, runner.Options{NoFileLog: true, Sinks: []runner.ResultSink{sink}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if sink.counts["single"] != 1 || sink.counts["concurrent"] != 25 {
+		t.Errorf("sink received %v, want single:1 concurrent:25", sink.counts)
+	}
+	if _, err := os.Stat(filepath.Join(runner.ReportsDir, "report.html")); err != nil {
+		t.Errorf("the report is still written: %v", err)
+	}
+}
+
+// chdir changes the working directory, where the reports are written, for the test.
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}